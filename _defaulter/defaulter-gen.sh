@@ -0,0 +1,11 @@
+
+# defaulter-gen
+go install ./$(dirname "${0}")/cmd/defaulter-gen
+if [ "${GENS}" = "all" ] || grep -qw "defaulter" <<<"${GENS}"; then
+  echo "Generating defaulters for ${GROUPS_WITH_VERSIONS}"
+
+  ${GOPATH}/bin/defaulter-gen \
+           --input-dirs $(codegen::join , "${FQ_APIS[@]}") \
+           -O zz_generated.defaults \
+           "$@"
+fi
@@ -42,23 +42,25 @@ func main() {
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "testassign",
 		},
-		Spec: assignmentsv1alpha1.PodAssignmentRuleSpec{
-			TargetLabels: labels.Set{
-				"test": "true",
-			},
-			Scheduling: assignmentsv1alpha1.PodAssignmentRuleScheduling{
-				MergeStrategy: assignmentsv1alpha1.PodAssignmentRuleSchedulingMergeStrategyDefault,
-				Affinity: &corev1.Affinity{
-					NodeAffinity: &corev1.NodeAffinity{
-						RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
-							NodeSelectorTerms: []corev1.NodeSelectorTerm{
-								{
-									MatchExpressions: []corev1.NodeSelectorRequirement{
-										{
-											Key:      "testkey",
-											Operator: corev1.NodeSelectorOpIn,
-											Values: []string{
-												"testval",
+		Spec: assignmentsv1alpha1.ClusterPodAssignmentRuleSpec{
+			PodAssignmentRuleSpec: assignmentsv1alpha1.PodAssignmentRuleSpec{
+				TargetLabels: labels.Set{
+					"test": "true",
+				},
+				Scheduling: assignmentsv1alpha1.PodAssignmentRuleScheduling{
+					MergeStrategy: assignmentsv1alpha1.PodAssignmentRuleSchedulingMergeStrategyDefault,
+					Affinity: &corev1.Affinity{
+						NodeAffinity: &corev1.NodeAffinity{
+							RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+								NodeSelectorTerms: []corev1.NodeSelectorTerm{
+									{
+										MatchExpressions: []corev1.NodeSelectorRequirement{
+											{
+												Key:      "testkey",
+												Operator: corev1.NodeSelectorOpIn,
+												Values: []string{
+													"testval",
+												},
 											},
 										},
 									},
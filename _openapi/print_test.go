@@ -12,6 +12,16 @@ import (
 	"github.com/go-openapi/spec"
 )
 
+// versions are all of the served versions of the assignments group
+var versions = []string{"v1alpha1", "v1beta1"}
+
+// kinds are the top level kinds of the assignments group
+var kinds = map[string]bool{
+	"NodeAssignmentGroup":      true,
+	"PodAssignmentRule":        true,
+	"ClusterPodAssignmentRule": true,
+}
+
 func createDefinitionObj() map[string]interface{} {
 
 	def := GetOpenAPIDefinitions(func(path string) spec.Ref {
@@ -27,30 +37,13 @@ func createDefinitionObj() map[string]interface{} {
 			"properties":  def[k].Schema.Properties,
 		}
 
-		if strings.HasSuffix(k, "NodeAssignmentGroup") {
-			munged[k].(map[string]interface{})["x-kubernetes-group-version-kind"] = []map[string]string{
-				map[string]string{
-					"group":   "assignments.kube-valet.io",
-					"kind":    "NodeAssignmentGroup",
-					"version": "v1alpha1",
-				},
-			}
-		}
-		if strings.HasSuffix(k, "ClusterPodAssignmentRule") {
+		// Tag the top level kinds of every version with their group version kind
+		if i := strings.LastIndex(k, "."); i != -1 && kinds[k[i+1:]] {
 			munged[k].(map[string]interface{})["x-kubernetes-group-version-kind"] = []map[string]string{
 				map[string]string{
 					"group":   "assignments.kube-valet.io",
-					"kind":    "ClusterPodAssignmentRule",
-					"version": "v1alpha1",
-				},
-			}
-		}
-		if strings.HasSuffix(k, "PodAssignmentRule") {
-			munged[k].(map[string]interface{})["x-kubernetes-group-version-kind"] = []map[string]string{
-				map[string]string{
-					"group":   "assignments.kube-valet.io",
-					"kind":    "PodAssignmentRule",
-					"version": "v1alpha1",
+					"kind":    k[i+1:],
+					"version": k[strings.LastIndex(k[:i], "/")+1 : i],
 				},
 			}
 		}
@@ -67,7 +60,9 @@ func createDefinitionObj() map[string]interface{} {
 	jsonStr = strings.Replace(jsonStr, "k8s.io/apimachinery/pkg/apis/meta/", "", -1)
 	jsonStr = strings.Replace(jsonStr, "github.com/domoinc/kube-valet/pkg/apis/", "", -1)
 	jsonStr = strings.Replace(jsonStr, "k8s.io/api/core/", "", -1)
-	jsonStr = strings.Replace(jsonStr, "assignments/v1alpha1", "assignments.v1alpha1", -1)
+	for _, version := range versions {
+		jsonStr = strings.Replace(jsonStr, "assignments/"+version, "assignments."+version, -1)
+	}
 	jsonStr = strings.Replace(jsonStr, "$ref\": \"", "$ref\": \"#/definitions/", -1)
 
 	var definitions map[string]interface{}
@@ -85,33 +80,48 @@ type ApiContext struct {
 	Version    string
 	Name       string
 	Namespaced bool
+	// OperationVersion keeps operation ids unique across versions. It is empty for v1alpha1 so existing ids don't change
+	OperationVersion string
 }
 
-var contexts = []ApiContext{
-	ApiContext{
-		Plural:     "nodeassignmentgroups",
-		Group:      "assignments.kube-valet.io",
-		DefRef:     "assignments",
-		Version:    "v1alpha1",
-		Name:       "NodeAssignmentGroup",
-		Namespaced: false,
-	},
-	ApiContext{
-		Plural:     "podassignmentrules",
-		Group:      "assignments.kube-valet.io",
-		DefRef:     "assignments",
-		Version:    "v1alpha1",
-		Name:       "PodAssignmentRule",
-		Namespaced: true,
-	},
-	ApiContext{
-		Plural:     "clusterpodassignmentrules",
-		Group:      "assignments.kube-valet.io",
-		DefRef:     "assignments",
-		Version:    "v1alpha1",
-		Name:       "ClusterPodAssignmentRule",
-		Namespaced: false,
-	}}
+var contexts = func() []ApiContext {
+	var rtn []ApiContext
+	for _, version := range versions {
+		opVersion := ""
+		if version != "v1alpha1" {
+			opVersion = strings.Title(version)
+		}
+		rtn = append(rtn,
+			ApiContext{
+				Plural:           "nodeassignmentgroups",
+				Group:            "assignments.kube-valet.io",
+				DefRef:           "assignments",
+				Version:          version,
+				Name:             "NodeAssignmentGroup",
+				Namespaced:       false,
+				OperationVersion: opVersion,
+			},
+			ApiContext{
+				Plural:           "podassignmentrules",
+				Group:            "assignments.kube-valet.io",
+				DefRef:           "assignments",
+				Version:          version,
+				Name:             "PodAssignmentRule",
+				Namespaced:       true,
+				OperationVersion: opVersion,
+			},
+			ApiContext{
+				Plural:           "clusterpodassignmentrules",
+				Group:            "assignments.kube-valet.io",
+				DefRef:           "assignments",
+				Version:          version,
+				Name:             "ClusterPodAssignmentRule",
+				Namespaced:       false,
+				OperationVersion: opVersion,
+			})
+	}
+	return rtn
+}()
 
 var fns = template.FuncMap{
 	"last": func(x int, a interface{}) bool {
@@ -150,7 +160,7 @@ func TestWriteOpenAPISpec(t *testing.T) {
 	openapi["swagger"] = "2.0"
 	openapi["info"] = map[string]interface{}{
 		"title":   "kube-valet",
-		"version": "v1alpha1",
+		"version": versions[len(versions)-1],
 	}
 	openapi["paths"] = createPathObj()
 	openapi["definitions"] = createDefinitionObj()
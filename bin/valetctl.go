@@ -3,12 +3,15 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/ghodss/yaml"
 
@@ -17,8 +20,11 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
 
@@ -26,7 +32,10 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	assignmentsv1alpha1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1alpha1"
+	"github.com/domoinc/kube-valet/pkg/apis/assignments/v1alpha1/validation"
 	valet "github.com/domoinc/kube-valet/pkg/client/clientset/versioned"
+	valetscheme "github.com/domoinc/kube-valet/pkg/client/clientset/versioned/scheme"
+	"github.com/domoinc/kube-valet/pkg/controller/nodeassignment"
 )
 
 const (
@@ -48,6 +57,14 @@ Examples:
   # Target: All nodes
   # Ensure that 25% of nodes are labeled and tainted for 'assign1' and the rest are for 'defAssign'
   valetctl group create tainted assign1:25%:LabelAndTaint defAssign:DEFAULT:LabelAndTaint
+
+  # Target: All nodes
+  # Ensure that 10% of nodes, rounded up, are 'canary' but never more than 3. 'batch' gets 5% or nothing at all
+  valetctl group create bounded --rounding Ceil canary:10%::-3 batch:5%::0- rest:DEFAULT
+
+  # Target: nodes in zone a or b that are not in the gpu pool
+  # Ensure there are always two 'ingress' labeled nodes
+  valetctl group create edge -t 'zone in (a,b),pool!=gpu' ingress:2
 `
 
 	assignmentCreateCmdHelp = `Create ClusterPodAssignmentRules or PodAssignmentRules
@@ -66,6 +83,10 @@ Affinity Examples:
   # Pods will use nodeAffinity to require jobhost=mysql or jobhost=etl nodes
   valetctl assignment create misc-jobs require -t job=misc -a jobhost=mysql,etl
 
+  # Target: batch pods that are not low priority in namespaces labeled team=data
+  # Pods will be given a prefered affinity to 'batch/*' node assignment members. Implies toleration
+  valetctl assignment create data-batch prefer -t 'job=batch,priority!=low' --namespace-selector team=data -A batch
+
 NodeSelector Examples:
   # Target: sensitive=true pods in all namespaces
   # Pods will use a nodeSelector to require volatile=false labeled nodes
@@ -87,6 +108,8 @@ PodAffinity/AntiAffinity Examples:
 )
 
 var (
+	yamlDocumentSeparator = regexp.MustCompile("(?m)^---\\s*$")
+
 	app        = kingpin.New("valetctl", "Kube-valet command-line control")
 	kubeconfig = app.Flag("kubeconfig", "Path to kubeconfig").Short('c').String()
 	dryRun     = app.Flag("dry-run", "Output objects without submitting them to Kubernetes").Short('N').Bool()
@@ -95,9 +118,13 @@ var (
 	groupCmd = app.Command("group", "Work with NodeAssignmentGroups")
 
 	groupCreateCmd             = groupCmd.Command("create", groupCreateCmdHelp)
-	groupCreateCmdTargetLabels = groupCreateCmd.Flag("target-labels", "Label selector for nodes").Short('t').String()
+	groupCreateCmdTargetLabels = groupCreateCmd.Flag("target-labels", "Label selector for nodes. Supports set-based selectors. Ex: 'zone in (a,b),!gpu'").Short('t').String()
 	groupCreateCmdName         = groupCreateCmd.Arg("name", "Group name").Required().String()
-	groupCreateCmdAssignments  = groupCreateCmd.Arg("assignments", "Assignment pairs. NAME:NUM:MODE. NUM can be a number, percent, or `DEFAULT`. NUM is optional. If no NUM is given, DEFAULT is assumed. MODE can be 'LabelOnly' or 'LabelAndTaint'. MODE is optional. If no MODE is given, labelOnly is assumed").Required().Strings()
+	groupCreateCmdRounding     = groupCreateCmd.Flag("rounding", "How percent assignments are rounded to a number of nodes. Options: Floor, Ceil, Nearest").Default(string(assignmentsv1alpha1.NodeAssignmentPercentRoundingDefault)).Enum("Floor", "Ceil", "Nearest")
+	groupCreateCmdAssignments  = groupCreateCmd.Arg("assignments", "Assignment pairs. NAME:NUM:MODE:MIN-MAX. NUM can be a number, percent, or `DEFAULT`. NUM is optional. If no NUM is given, DEFAULT is assumed. MODE can be 'LabelOnly', 'LabelAndTaint' or 'TaintOnly'. MODE is optional. If no MODE is given, labelOnly is assumed. MIN-MAX bounds the number of nodes. Either side may be left out. A MIN of 0 allows the assignment to have no nodes").Required().Strings()
+
+	groupPlanCmd     = groupCmd.Command("plan", "Show how nodes would be assigned if the NodeAssignmentGroup in a file was applied. Rollout strategies and drains may spread the changes over several reconciles")
+	groupPlanCmdFile = groupPlanCmd.Flag("file", "YAML or JSON file with the NodeAssignmentGroup").Short('f').Required().ExistingFile()
 
 	groupReportCmd = groupCmd.Command("report", "Generate NodeAssignmentGroup reports.")
 
@@ -106,21 +133,28 @@ var (
 
 	groupReportNodesCmd      = groupReportCmd.Command("nodes", "Generate report based on Node")
 	groupReportNodesCmdNames = groupReportNodesCmd.Arg("targets", "Target Node Names").Strings()
+	groupReportNodesCmdWide  = groupReportNodesCmd.Flag("wide", "Also show when, by which kube-valet replica and for which NodeAssignmentGroup generation each assignment and pack left state was set").Short('w').Bool()
 
 	assignmentCmd = app.Command("assignment", "Work with ClusterPodAssignmentRules and PodAssignmentRules")
 
-	assignmentCreateCmd             = assignmentCmd.Command("create", assignmentCreateCmdHelp)
-	assignmentCreateCmdNamespace    = app.Flag("namespace", "Create a PodAssignmentRule in the given namespace").Short('n').String()
-	assignmentCreateCmdTargetLabels = assignmentCreateCmd.Flag("target-labels", "Label selector for pods").Short('t').String()
-	assignmentCreateCmdNodeSelector = assignmentCreateCmd.Flag("node-selector", "NodeSelector labels").Short('S').String()
-	assignmentCreateCmdNodeAffinity = assignmentCreateCmd.Flag("node-affinity", "Node Affinity labels. Supports key=value1,value2,etc...").Short('a').String()
-	assignmentCreateCmdAssignment   = assignmentCreateCmd.Flag("assignment", "NodeAssignmentGroup Name/Assignment").Short('A').String()
-	assignmentCreateCmdTopologyKey  = assignmentCreateCmd.Flag("fault-key", "Fault key for avoid-others and deny-thers anti-affinity").Short('F').Default("kubernetes.io/hostname").String()
-	assignmentCreateCmdName         = assignmentCreateCmd.Arg("name", "Assignment name").Required().String()
-	assignmentCreateCmdMode         = assignmentCreateCmd.Arg("mode", "Rule Mode").Required().Enum("prefer", "require", "nodes", "prefer-others", "require-others", "avoid-others", "deny-others")
+	assignmentCreateCmd                  = assignmentCmd.Command("create", assignmentCreateCmdHelp)
+	assignmentCreateCmdNamespace         = app.Flag("namespace", "Create a PodAssignmentRule in the given namespace").Short('n').String()
+	assignmentCreateCmdTargetLabels      = assignmentCreateCmd.Flag("target-labels", "Label selector for pods. Supports set-based selectors").Short('t').String()
+	assignmentCreateCmdNamespaceSelector = assignmentCreateCmd.Flag("namespace-selector", "Label selector for namespaces. Only valid for ClusterPodAssignmentRules").String()
+	assignmentCreateCmdPriority          = assignmentCreateCmd.Flag("priority", "Rule priority. Higher priority rules are applied last and take precedence").Int32()
+	assignmentCreateCmdStopProcessing    = assignmentCreateCmd.Flag("stop-processing", "Skip lower precedence rules when this rule matches").Bool()
+	assignmentCreateCmdNodeSelector      = assignmentCreateCmd.Flag("node-selector", "NodeSelector labels").Short('S').String()
+	assignmentCreateCmdNodeAffinity      = assignmentCreateCmd.Flag("node-affinity", "Node Affinity labels. Supports key=value1,value2,etc...").Short('a').String()
+	assignmentCreateCmdAssignment        = assignmentCreateCmd.Flag("assignment", "NodeAssignmentGroup Name/Assignment").Short('A').String()
+	assignmentCreateCmdTopologyKey       = assignmentCreateCmd.Flag("fault-key", "Fault key for avoid-others and deny-thers anti-affinity").Short('F').Default("kubernetes.io/hostname").String()
+	assignmentCreateCmdName              = assignmentCreateCmd.Arg("name", "Assignment name").Required().String()
+	assignmentCreateCmdMode              = assignmentCreateCmd.Arg("mode", "Rule Mode").Required().Enum("prefer", "require", "nodes", "prefer-others", "require-others", "avoid-others", "deny-others")
 
 	assignmentReportCmd = assignmentCmd.Command("report", "Report on ClusterPodAssignmentRules and PodAssignmentRules")
 
+	validateCmd      = app.Command("validate", "Validate kube-valet resources in files without submitting them to Kubernetes")
+	validateCmdFiles = validateCmd.Arg("files", "YAML or JSON files. Multiple documents per file are supported").Required().ExistingFiles()
+
 	restConfig  *rest.Config
 	kubeClient  kubernetes.Interface
 	valetClient valet.Interface
@@ -130,15 +164,21 @@ func main() {
 	// Enable short help flag
 	app.HelpFlag.Short('h')
 
-	if !*dryRun {
+	// Parse Flags
+	cmd := kingpin.MustParse(app.Parse(os.Args[1:]))
+
+	if !*dryRun && cmd != validateCmd.FullCommand() {
 		configureClients()
 	}
 
-	// Parse Flags
-	cmd := kingpin.MustParse(app.Parse(os.Args[1:]))
 	switch cmd {
 	case groupCreateCmd.FullCommand():
 		groupCreate()
+	case groupPlanCmd.FullCommand():
+		if *dryRun {
+			app.Fatalf("Plan cannot be run in dry-run mode")
+		}
+		groupPlan()
 	case groupReportNagsCmd.FullCommand():
 		if *dryRun {
 			app.Fatalf("Report cannot be run in dry-run mode")
@@ -154,6 +194,8 @@ func main() {
 		// 	app.FatalUsage("A node-selector, nodeaffinity, or assignment flag must be provided")
 		// }
 		assignmentCreate()
+	case validateCmd.FullCommand():
+		validateFiles()
 	}
 
 }
@@ -200,7 +242,7 @@ func getConfig() *rest.Config {
 }
 
 func groupCreate() {
-	targetLabels, err := labels.ConvertSelectorToLabelsMap(*groupCreateCmdTargetLabels)
+	selector, err := assignmentsv1alpha1.ParseSelector(*groupCreateCmdTargetLabels)
 	if err != nil {
 		app.FatalUsage("Error parsing targetLabels: %s\n", err)
 	}
@@ -209,31 +251,44 @@ func groupCreate() {
 		ObjectMeta: metav1.ObjectMeta{
 			Name: *groupCreateCmdName,
 		},
-		Spec: assignmentsv1alpha1.NodeAssignmentGroupSpec{
-			TargetLabels: targetLabels,
-		},
+	}
+
+	// Plain key=value selectors are kept as targetLabels, anything set-based requires a nodeSelector
+	if selector != nil {
+		if len(selector.MatchExpressions) == 0 {
+			nag.Spec.TargetLabels = selector.MatchLabels
+		} else {
+			nag.Spec.NodeSelector = selector
+		}
 	}
 
 	for _, assign := range *groupCreateCmdAssignments {
-		parts := strings.SplitN(assign, ":", 3)
-		var name string
+		parts := strings.SplitN(assign, ":", 4)
+		var name, bounds string
 		num := "DEFAULT"
 		mode := assignmentsv1alpha1.NodeAssignmentModeLabelOnly
 		switch len(parts) {
-		case 1:
-			name = parts[0]
+		case 4:
+			bounds = parts[3]
+			fallthrough
+		case 3:
+			if parts[2] != "" {
+				mode = assignmentsv1alpha1.NodeAssignmentMode(parts[2])
+			}
+			fallthrough
 		case 2:
-			name = parts[0]
 			num = parts[1]
-		case 3:
+			fallthrough
+		case 1:
 			name = parts[0]
-			num = parts[1]
-			mode = assignmentsv1alpha1.NodeAssignmentMode(parts[2])
 		default:
 			app.FatalUsage("Invalid assignment argument: %s\n", assign)
 		}
 
 		if num == "DEFAULT" {
+			if bounds != "" {
+				app.FatalUsage("The DEFAULT assignment can't have MIN-MAX bounds: %s\n", assign)
+			}
 			nag.Spec.DefaultAssignment = &assignmentsv1alpha1.NodeAssignment{
 				Name: name,
 				Mode: mode,
@@ -241,15 +296,23 @@ func groupCreate() {
 			continue
 		}
 
+		minNodes, maxNodes, err := parseNodeBounds(bounds)
+		if err != nil {
+			app.FatalUsage("Error parsing assignment MIN-MAX: %s\n", err)
+		}
+
 		if strings.HasSuffix(num, "%") {
 			percentDesired, err := strconv.ParseInt(strings.TrimSuffix(num, `%`), 10, 32)
 			if err != nil {
 				app.FatalUsage("Error parsing assignment NUM: %s\n", err)
 			}
 			nag.Spec.Assignments = append(nag.Spec.Assignments, assignmentsv1alpha1.NodeAssignment{
-				Name:           name,
-				PercentDesired: int(percentDesired),
-				Mode:           mode,
+				Name:            name,
+				PercentDesired:  int(percentDesired),
+				PercentRounding: assignmentsv1alpha1.NodeAssignmentPercentRounding(*groupCreateCmdRounding),
+				MinNodes:        minNodes,
+				MaxNodes:        maxNodes,
+				Mode:            mode,
 			})
 			continue
 		}
@@ -262,6 +325,8 @@ func groupCreate() {
 			nag.Spec.Assignments = append(nag.Spec.Assignments, assignmentsv1alpha1.NodeAssignment{
 				Name:       name,
 				NumDesired: int(numDesired),
+				MinNodes:   minNodes,
+				MaxNodes:   maxNodes,
 				Mode:       mode,
 			})
 			continue
@@ -270,6 +335,8 @@ func groupCreate() {
 		app.FatalUsage("Unsupported value for assignment NUM: %s\n", num)
 	}
 
+	exitIfInvalid(validation.ValidateNodeAssignmentGroup(nag))
+
 	if *dryRun {
 		nag.Kind = assignmentsv1alpha1.NodeAssignmentGroupResourceKind
 		nag.APIVersion = assignmentsv1alpha1.SchemeGroupVersion.Group + "/" + assignmentsv1alpha1.SchemeGroupVersion.Version
@@ -284,6 +351,99 @@ func groupCreate() {
 	}
 }
 
+// parseNodeBounds parses the MIN-MAX part of an assignment argument. Either side may be empty to leave it unset
+func parseNodeBounds(bounds string) (*int, *int, error) {
+	if bounds == "" {
+		return nil, nil, nil
+	}
+	parts := strings.SplitN(bounds, "-", 2)
+	if len(parts) != 2 {
+		return nil, nil, fmt.Errorf("expected MIN-MAX, got %q", bounds)
+	}
+
+	var rtn [2]*int
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		v, err := strconv.ParseUint(part, 10, 31)
+		if err != nil {
+			return nil, nil, err
+		}
+		n := int(v)
+		rtn[i] = &n
+	}
+	return rtn[0], rtn[1], nil
+}
+
+func groupPlan() {
+	var nag *assignmentsv1alpha1.NodeAssignmentGroup
+	for _, doc := range readDocuments(*groupPlanCmdFile) {
+		if _, obj, ok := decodeDocument(*groupPlanCmdFile, doc); ok {
+			if n, ok := obj.(*assignmentsv1alpha1.NodeAssignmentGroup); ok {
+				nag = n
+				break
+			}
+		}
+	}
+	if nag == nil {
+		app.Fatalf("No NodeAssignmentGroup found in %s", *groupPlanCmdFile)
+	}
+	exitIfInvalid(validation.ValidateNodeAssignmentGroup(nag))
+
+	nodeResult, err := kubeClient.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		app.Fatalf("Error fetching Nodes: %s", err)
+	}
+	nodes := make([]*corev1.Node, 0, len(nodeResult.Items))
+	for i := range nodeResult.Items {
+		nodes = append(nodes, &nodeResult.Items[i])
+	}
+
+	// Pods are only needed to rank nodes by the number of pods of each assignment they run
+	var podIndex cache.Indexer
+	if nag.Spec.SelectionPolicy == assignmentsv1alpha1.NodeSelectionPolicyMostPods {
+		podResult, err := kubeClient.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{})
+		if err != nil {
+			app.Fatalf("Error fetching Pods: %s", err)
+		}
+		podIndex = cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		for i := range podResult.Items {
+			podIndex.Add(&podResult.Items[i])
+		}
+	}
+
+	planner := nodeassignment.NewPlanner(nag, nodes, podIndex, time.Now())
+
+	// setup table writer
+	const padding = 3
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, padding, ' ', 0)
+	fmt.Fprintln(w, "NODE\tCURRENT\tPLANNED\tCHANGES\t")
+
+	// The plan also lists the untargeted nodes that lose their assignment, so it is the total nodes can change out of
+	plan := planner.Plan()
+	numChanged := 0
+	for _, np := range plan {
+		changes := "none"
+		if np.Changed() {
+			numChanged++
+			changes = strings.Join(nodeassignment.DiffNodes(np.Node, planner.Apply(np)), ", ")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n", np.Node.GetName(), valueOrNone(np.Current), valueOrNone(np.PlannedName()), changes)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d of %d nodes would change\n", numChanged, len(plan))
+}
+
+// valueOrNone returns "<none>" for empty values like kubectl does
+func valueOrNone(v string) string {
+	if v == "" {
+		return "<none>"
+	}
+	return v
+}
+
 func groupReportByNag() {
 	var nags []assignmentsv1alpha1.NodeAssignmentGroup
 	fetchErrors := make(map[string]error)
@@ -315,13 +475,12 @@ func groupReportByNag() {
 		for _, nag := range nags {
 			// Generate data
 			// Example label : nag.assignments.kube-valet.io/NAGNAME=ASSIGNMENTNAME
-			nagLabelKey := fmt.Sprintf("nag.assignments.kube-valet.io/%s", nag.GetName())
+			// Assignments with custom keys or without a label are found through the applied annotation
 			assignmentMembers := make(map[string][]string)
-			for _, node := range nodeResult.Items {
-				for lk, lv := range node.GetLabels() {
-					if lk == nagLabelKey {
-						assignmentMembers[lv] = append(assignmentMembers[lv], node.GetName())
-					}
+			for i := range nodeResult.Items {
+				node := &nodeResult.Items[i]
+				if a, ok := nag.GetAssignment(node); ok {
+					assignmentMembers[a] = append(assignmentMembers[a], node.GetName())
 				}
 			}
 
@@ -391,9 +550,14 @@ func groupReportByNode() {
 	// setup table writer
 	const padding = 3
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, padding, ' ', 0)
-	fmt.Fprintln(w, "NODE\tNAG-ASSIGNMENTS\tNAG-TAINTS\t")
+	if *groupReportNodesCmdWide {
+		fmt.Fprintln(w, "NODE\tNAG-ASSIGNMENTS\tNAG-TAINTS\tPROVENANCE\t")
+	} else {
+		fmt.Fprintln(w, "NODE\tNAG-ASSIGNMENTS\tNAG-TAINTS\t")
+	}
 
-	for _, node := range nodes {
+	for i := range nodes {
+		node := &nodes[i]
 		var assignLabels, assignTaints []string
 
 		// Check for protected nodes
@@ -418,26 +582,103 @@ func groupReportByNode() {
 			}
 		}
 
-		fmt.Fprintf(w, "%s\t%s\t%s\t\n", node.GetName(), strings.Join(assignLabels, ","), strings.Join(assignTaints, ","))
+		if *groupReportNodesCmdWide {
+			provenance := describeNodeProvenance(node)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n", node.GetName(), strings.Join(assignLabels, ","), strings.Join(assignTaints, ","), strings.Join(provenance, "; "))
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%s\t\n", node.GetName(), strings.Join(assignLabels, ","), strings.Join(assignTaints, ","))
+		}
 	}
 
 	// Report results
 	w.Flush()
 }
 
+// describeNodeProvenance summarizes the provenance of every assignment and pack left state on the node. Provenance is
+// kept by the label keys of the group, so assignments that use a custom label key or only a taint are found too.
+// Ex: NAGNAME/ASSIGNMENTNAME at 2019-06-01T12:00:00Z by kube-valet-0 for generation 3
+func describeNodeProvenance(node *corev1.Node) []string {
+	var provenance []string
+	prefix := assignmentsv1alpha1.ProvenanceKey(assignmentsv1alpha1.ProvenanceAssignedAt, "")
+	for key := range node.GetAnnotations() {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		labelKey := strings.TrimPrefix(key, prefix)
+		parts := strings.SplitN(labelKey, "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		nag := &assignmentsv1alpha1.NodeAssignmentGroup{ObjectMeta: metav1.ObjectMeta{Name: parts[1]}}
+		switch labelKey {
+		case nag.AssignmentLabelKey():
+			assignment, _ := nag.GetAssignment(node)
+			provenance = append(provenance, fmt.Sprintf("%s/%s %s", nag.Name, valueOrNone(assignment), describeProvenance(node, labelKey)))
+		case nag.PackLeftLabelKey():
+			state := node.GetLabels()[labelKey]
+			provenance = append(provenance, fmt.Sprintf("%s/packleft=%s %s", nag.Name, valueOrNone(state), describeProvenance(node, labelKey)))
+		}
+	}
+	sort.Strings(provenance)
+	return provenance
+}
+
+// describeProvenance summarizes the provenance annotations of a kube-valet label on the node.
+// Ex: at 2019-06-01T12:00:00Z by kube-valet-0 for generation 3, was old
+func describeProvenance(node *corev1.Node, labelKey string) string {
+	p := assignmentsv1alpha1.GetProvenance(node, labelKey)
+	if len(p) == 0 {
+		return "(no provenance)"
+	}
+
+	desc := []string{"at " + valueOrNone(p[assignmentsv1alpha1.ProvenanceAssignedAt])}
+	if by, ok := p[assignmentsv1alpha1.ProvenanceAssignedBy]; ok {
+		desc = append(desc, "by "+by)
+	}
+	if gen, ok := p[assignmentsv1alpha1.ProvenanceGeneration]; ok {
+		desc = append(desc, "for generation "+gen)
+	}
+	if pf, ok := p[assignmentsv1alpha1.ProvenancePercentFull]; ok {
+		desc = append(desc, pf+"% full")
+	}
+	rtn := strings.Join(desc, " ")
+	if prev, ok := p[assignmentsv1alpha1.ProvenancePrevious]; ok {
+		rtn += ", was " + prev
+	}
+	return rtn
+}
+
 func assignmentCreate() {
-	targetLabels, err := labels.ConvertSelectorToLabelsMap(*assignmentCreateCmdTargetLabels)
+	selector, err := assignmentsv1alpha1.ParseSelector(*assignmentCreateCmdTargetLabels)
 	if err != nil {
 		app.FatalUsage("Error parsing targetLabels: %s\n", err)
 	}
 
+	namespaceSelector, err := assignmentsv1alpha1.ParseSelector(*assignmentCreateCmdNamespaceSelector)
+	if err != nil {
+		app.FatalUsage("Error parsing namespaceSelector: %s\n", err)
+	}
+	if namespaceSelector != nil && *assignmentCreateCmdNamespace != "" {
+		app.FatalUsage("A namespace-selector can only be used for ClusterPodAssignmentRules\n")
+	}
+
 	spec := assignmentsv1alpha1.PodAssignmentRuleSpec{
-		TargetLabels: targetLabels,
+		Priority:       *assignmentCreateCmdPriority,
+		StopProcessing: *assignmentCreateCmdStopProcessing,
 		Scheduling: assignmentsv1alpha1.PodAssignmentRuleScheduling{
 			MergeStrategy: assignmentsv1alpha1.PodAssignmentRuleSchedulingMergeStrategyDefault,
 		},
 	}
 
+	// Plain key=value selectors are kept as targetLabels, anything set-based requires a podSelector
+	if selector != nil {
+		if len(selector.MatchExpressions) == 0 {
+			spec.TargetLabels = selector.MatchLabels
+		} else {
+			spec.PodSelector = selector
+		}
+	}
+
 	// Parse nag
 	if (*assignmentCreateCmdMode == "prefer" || *assignmentCreateCmdMode == "require") && *assignmentCreateCmdAssignment != "" {
 		parts := strings.Split(*assignmentCreateCmdAssignment, "/")
@@ -516,12 +757,15 @@ func assignmentCreate() {
 	if *assignmentCreateCmdMode == "prefer-others" || *assignmentCreateCmdMode == "require-others" || *assignmentCreateCmdMode == "avoid-others" || *assignmentCreateCmdMode == "deny-others" {
 		matchExpressions := []metav1.LabelSelectorRequirement{}
 
-		for k, v := range targetLabels {
-			matchExpressions = append(matchExpressions, metav1.LabelSelectorRequirement{
-				Key:      k,
-				Operator: metav1.LabelSelectorOpIn,
-				Values:   []string{v},
-			})
+		if selector != nil {
+			for k, v := range selector.MatchLabels {
+				matchExpressions = append(matchExpressions, metav1.LabelSelectorRequirement{
+					Key:      k,
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   []string{v},
+				})
+			}
+			matchExpressions = append(matchExpressions, selector.MatchExpressions...)
 		}
 
 		paTerm := corev1.PodAffinityTerm{
@@ -579,6 +823,8 @@ func assignmentCreate() {
 			Spec: spec,
 		}
 
+		exitIfInvalid(validation.ValidatePodAssignmentRule(par))
+
 		if *dryRun {
 			par.Kind = assignmentsv1alpha1.PodAssignmentRuleResourceKind
 			par.APIVersion = assignmentsv1alpha1.SchemeGroupVersion.Group + "/" + assignmentsv1alpha1.SchemeGroupVersion.Version
@@ -596,9 +842,14 @@ func assignmentCreate() {
 			ObjectMeta: metav1.ObjectMeta{
 				Name: *assignmentCreateCmdName,
 			},
-			Spec: spec,
+			Spec: assignmentsv1alpha1.ClusterPodAssignmentRuleSpec{
+				PodAssignmentRuleSpec: spec,
+				NamespaceSelector:     namespaceSelector,
+			},
 		}
 
+		exitIfInvalid(validation.ValidateClusterPodAssignmentRule(cpar))
+
 		if *dryRun {
 			cpar.Kind = assignmentsv1alpha1.ClusterPodAssignmentRuleResourceKind
 			cpar.APIVersion = assignmentsv1alpha1.SchemeGroupVersion.Group + "/" + assignmentsv1alpha1.SchemeGroupVersion.Version
@@ -614,6 +865,100 @@ func assignmentCreate() {
 	}
 }
 
+// exitIfInvalid prints all validation errors and exits when there are any
+func exitIfInvalid(errs field.ErrorList) {
+	if len(errs) == 0 {
+		return
+	}
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+	}
+	app.Fatalf("Object is invalid")
+}
+
+func validateFiles() {
+	numInvalid := 0
+	for _, path := range *validateCmdFiles {
+		for _, doc := range readDocuments(path) {
+			typeMeta, converted, ok := decodeDocument(path, doc)
+			if !ok {
+				fmt.Printf("%s: skipping unknown kind %q\n", path, typeMeta.Kind)
+				continue
+			}
+
+			var obj metav1.Object
+			var errs field.ErrorList
+			switch o := converted.(type) {
+			case *assignmentsv1alpha1.NodeAssignmentGroup:
+				obj, errs = o, validation.ValidateNodeAssignmentGroup(o)
+			case *assignmentsv1alpha1.PodAssignmentRule:
+				obj, errs = o, validation.ValidatePodAssignmentRule(o)
+			case *assignmentsv1alpha1.ClusterPodAssignmentRule:
+				obj, errs = o, validation.ValidateClusterPodAssignmentRule(o)
+			default:
+				fmt.Printf("%s: skipping unknown kind %q\n", path, typeMeta.Kind)
+				continue
+			}
+
+			if len(errs) == 0 {
+				fmt.Printf("%s: %s %s is valid\n", path, typeMeta.Kind, obj.GetName())
+				continue
+			}
+			numInvalid++
+			fmt.Printf("%s: %s %s is invalid\n", path, typeMeta.Kind, obj.GetName())
+			for _, err := range errs {
+				fmt.Printf("  %s\n", err)
+			}
+		}
+	}
+
+	if numInvalid != 0 {
+		app.Fatalf("%d invalid object(s)", numInvalid)
+	}
+}
+
+// readDocuments returns the non-empty documents of a YAML or JSON file
+func readDocuments(path string) []string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		app.Fatalf("Unable to read %s: %s", path, err)
+	}
+
+	var docs []string
+	for _, doc := range yamlDocumentSeparator.Split(string(data), -1) {
+		if strings.TrimSpace(doc) != "" {
+			docs = append(docs, doc)
+		}
+	}
+	return docs
+}
+
+// decodeDocument decodes a kube-valet object of any served version as v1alpha1, the version it is stored in.
+// False is returned for objects of other groups
+func decodeDocument(path string, doc string) (metav1.TypeMeta, runtime.Object, bool) {
+	typeMeta := metav1.TypeMeta{}
+	if err := yaml.Unmarshal([]byte(doc), &typeMeta); err != nil {
+		app.Fatalf("Unable to parse %s: %s", path, err)
+	}
+	if typeMeta.GroupVersionKind().Group != assignmentsv1alpha1.GroupName {
+		return typeMeta, nil, false
+	}
+
+	jsonDoc, err := yaml.YAMLToJSON([]byte(doc))
+	if err != nil {
+		app.Fatalf("Unable to parse %s in %s: %s", typeMeta.Kind, path, err)
+	}
+	decoded, _, err := valetscheme.Codecs.UniversalDeserializer().Decode(jsonDoc, nil, nil)
+	if err != nil {
+		app.Fatalf("Unable to parse %s in %s: %s", typeMeta.Kind, path, err)
+	}
+	converted, err := valetscheme.Scheme.ConvertToVersion(decoded, assignmentsv1alpha1.SchemeGroupVersion)
+	if err != nil {
+		app.Fatalf("Unable to convert %s in %s: %s", typeMeta.Kind, path, err)
+	}
+	return typeMeta, converted, true
+}
+
 func outputObject(o interface{}) {
 	switch *output {
 	case "json":
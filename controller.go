@@ -5,12 +5,9 @@ import (
 
 	"github.com/op/go-logging"
 
-	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/leaderelection"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
-	"k8s.io/client-go/tools/record"
 
 	valet "github.com/domoinc/kube-valet/pkg/client/clientset/versioned"
 	"github.com/domoinc/kube-valet/pkg/config"
@@ -68,11 +65,8 @@ func (kd *KubeValet) Run() {
 			kd.kubeClient.CoreV1(),
 			kd.kubeClient.CoordinationV1(),
 			resourcelock.ResourceLockConfig{
-				Identity: *electID,
-				EventRecorder: record.NewBroadcaster().NewRecorder(
-					scheme.Scheme,
-					corev1.EventSource{Component: KubernetesComponent},
-				),
+				Identity:      *electID,
+				EventRecorder: kd.config.EventRecorder,
 			},
 		)
 		if err != nil {
@@ -62,4 +62,90 @@ webhooks:
     apiGroups: [""]
     apiVersions: ["*"]
     resources: ["pods"]
+- name: defaults.kube-valet.io
+  failurePolicy: Fail
+  clientConfig:
+{{- if not .Values.tls.auto }}
+    caBundle: {{ .Files.Get .Values.tls.caPath | b64enc }}
+{{- else }}
+    caBundle: __AUTO_TLS_CA_BUNDLE__
+{{- end }}
+    service:
+      namespace: kube-valet
+      name: kube-valet
+      path: /mutate
+  rules:
+  - operations: ["CREATE", "UPDATE"]
+    apiGroups: ["assignments.kube-valet.io"]
+    apiVersions: ["v1alpha1"]
+    resources: ["nodeassignmentgroups", "podassignmentrules", "clusterpodassignmentrules"]
+  # Requests for other versions are converted to v1alpha1 before being sent to the webhook
+  matchPolicy: Equivalent
+{{- end -}}
+
+
+{{- define "kube-valet.validating-webhook-config" -}}
+apiVersion: admissionregistration.k8s.io/v1beta1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: validation.kube-valet.io
+webhooks:
+- name: validation.kube-valet.io
+  failurePolicy: Fail
+  clientConfig:
+{{- if not .Values.tls.auto }}
+    caBundle: {{ .Files.Get .Values.tls.caPath | b64enc }}
+{{- else }}
+    caBundle: __AUTO_TLS_CA_BUNDLE__
+{{- end }}
+    service:
+      namespace: kube-valet
+      name: kube-valet
+      path: /validate
+  rules:
+  - operations: ["CREATE", "UPDATE"]
+    apiGroups: ["assignments.kube-valet.io"]
+    apiVersions: ["v1alpha1"]
+    resources: ["nodeassignmentgroups", "podassignmentrules", "clusterpodassignmentrules"]
+  # Requests for other versions are converted to v1alpha1 before being sent to the webhook
+  matchPolicy: Equivalent
+{{- end -}}
+
+
+{{/*
+Served versions and webhook conversion for the kube-valet CRDs. With automatic TLS the
+caBundle is patched in by the bootstrap job.
+*/}}
+{{- define "kube-valet.crd-versions" }}
+  # v1alpha1 is the storage version, v1beta1 objects are converted by the kube-valet webhook
+  versions:
+  - name: v1alpha1
+    served: true
+    storage: true
+  - name: v1beta1
+    served: true
+    storage: false
+  # webhook conversion requires a structural schema and pruning
+  preserveUnknownFields: false
+  validation:
+    openAPIV3Schema:
+      type: object
+      properties:
+        spec:
+          type: object
+          x-kubernetes-preserve-unknown-fields: true
+        status:
+          type: object
+          x-kubernetes-preserve-unknown-fields: true
+  conversion:
+    strategy: Webhook
+    conversionReviewVersions: ["v1beta1"]
+    webhookClientConfig:
+{{- if not .Values.tls.auto }}
+      caBundle: {{ .Files.Get .Values.tls.caPath | b64enc }}
+{{- end }}
+      service:
+        namespace: kube-valet
+        name: kube-valet
+        path: /convert
 {{- end -}}
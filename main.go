@@ -9,12 +9,18 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	valet "github.com/domoinc/kube-valet/pkg/client/clientset/versioned"
+	valetscheme "github.com/domoinc/kube-valet/pkg/client/clientset/versioned/scheme"
 	"github.com/op/go-logging"
 	"gopkg.in/alecthomas/kingpin.v2"
+	corev1 "k8s.io/api/core/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	resourcelock "k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/record"
 
 	valetconfig "github.com/domoinc/kube-valet/pkg/config"
 )
@@ -41,7 +47,9 @@ var (
 
 	nodeAssignment = app.Flag("node-assignment", "Run the NodeAssignment controllers, Default: true").Default("true").Bool()
 	packLeft       = app.Flag("scheduling-packleft", "Run the Pack Left Scheduling controller, Default: true").Default("true").Bool()
-	//numNagThreads  = app.Flag("num-nag-threads", "Max number of NodAssignmentGroups that will be reconciled concurrently").Default("1").Int() // Can't enable until node resource locking is in place
+	packLeftWindow = app.Flag("packleft-coalesce-window", "How long pod and node changes are collected before the NodeAssignmentGroups they affect are rebalanced. 0 rebalances on every change").Default("2s").Duration()
+	numNagThreads  = app.Flag("num-nag-threads", "Max number of NodeAssignmentGroups that will be reconciled concurrently").Default("1").Int()
+	dryRun         = app.Flag("dry-run", "Log and record events for the node assignment and pack left changes of NodeAssignmentGroups instead of making them. Pod assignment is not affected").Bool()
 
 	podAssignment = app.Flag("pod-assignment", "Run the PodAssignment Controllers, Default: true").Default("true").Bool()
 	numPodThreads = app.Flag("num-pod-threads", "Max number of Pods that will be initilized concurrently").Default("1").Int()
@@ -123,14 +131,18 @@ func main() {
 			ShouldRun: *podAssignment,
 		},
 		NagController: valetconfig.ControllerConfig{
-			Threads:   1,
+			Threads:   *numNagThreads,
 			ShouldRun: *nodeAssignment,
 		},
 		PLController: valetconfig.ControllerConfig{
 			Threads:   1,
 			ShouldRun: *packLeft,
 		},
-		LoggingBackend: backend1Leveled,
+		LoggingBackend:         backend1Leveled,
+		EventRecorder:          newEventRecorder(kubeClient),
+		Identity:               *electID,
+		DryRun:                 *dryRun,
+		PackLeftCoalesceWindow: *packLeftWindow,
 	})
 
 	http.Handle("/metrics", promhttp.Handler())
@@ -140,6 +152,16 @@ func main() {
 	kd.Run()
 }
 
+// newEventRecorder creates a recorder that writes events to the api. The kube-valet types are added to the scheme
+// so that events can refer to them.
+func newEventRecorder(kubeClient kubernetes.Interface) record.EventRecorder {
+	utilruntime.Must(valetscheme.AddToScheme(scheme.Scheme))
+
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+	return broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: KubernetesComponent})
+}
+
 func startMetricsHttp() {
 	for true {
 		err := http.ListenAndServe(":8080", nil)
@@ -0,0 +1,100 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// PackLeftFullPercentDefault is the percent of a node that must be used for it to be considered full
+	PackLeftFullPercentDefault = 80
+
+	// PackLeftNumAvoidDefault is the number of nodes set to Avoid when no other amount is given
+	PackLeftNumAvoidDefault = 1
+
+	// PackLeftResourceWeightDefault is the weight of a pack left resource when none is given
+	PackLeftResourceWeightDefault = 1
+
+	// DrainTimeoutSecondsDefault is how long to wait for pods to be evicted from a node leaving a Drain assignment
+	DrainTimeoutSecondsDefault = 600
+
+	// TopologySpreadMaxSkewDefault is the allowed difference between the number of assigned nodes in two domains
+	TopologySpreadMaxSkewDefault = 1
+)
+
+func addDefaultingFuncs(scheme *runtime.Scheme) error {
+	return RegisterDefaults(scheme)
+}
+
+// SetDefaults_NodeAssignmentGroupSpec sets the selection policy of a group
+func SetDefaults_NodeAssignmentGroupSpec(obj *NodeAssignmentGroupSpec) {
+	if obj.SelectionPolicy == NodeSelectionPolicyUndefined {
+		obj.SelectionPolicy = NodeSelectionPolicyDefault
+	}
+}
+
+// SetDefaults_NodeAssignment sets the mode, taint effect, percent rounding and reassignment policy of an
+// assignment. PackLeft configuration is added to PackLeft assignments that do not have any.
+func SetDefaults_NodeAssignment(obj *NodeAssignment) {
+	if obj.Mode == NodeAssignmentModeUndefined {
+		obj.Mode = NodeAssignmentModeDefault
+	}
+	if obj.PercentRounding == NodeAssignmentPercentRoundingUndefined {
+		obj.PercentRounding = NodeAssignmentPercentRoundingDefault
+	}
+	if obj.ReassignmentPolicy == NodeAssignmentReassignmentPolicyUndefined {
+		obj.ReassignmentPolicy = NodeAssignmentReassignmentPolicyDefault
+	}
+	if obj.ReassignmentPolicy == NodeAssignmentReassignmentPolicyDrain && obj.DrainTimeoutSeconds == 0 {
+		obj.DrainTimeoutSeconds = DrainTimeoutSecondsDefault
+	}
+	if (obj.Mode == NodeAssignmentModeLabelAndTaint || obj.Mode == NodeAssignmentModeTaintOnly) && obj.TaintEffect == NodeAssignmentTaintEffectNotSpecified {
+		obj.TaintEffect = NodeAssignmentTaintEffectDefault
+	}
+	if obj.SchedulingMode == NodeAssignmentSchedulingModePackLeft && obj.PackLeft == nil {
+		obj.PackLeft = &PackLeftScheduling{}
+	}
+}
+
+// SetDefaults_PackLeftScheduling sets the full threshold, avoid buffer, resources and aggregation of a PackLeft
+// assignment. FullExitPercent is left unset so that it keeps following FullPercent when that is edited.
+func SetDefaults_PackLeftScheduling(obj *PackLeftScheduling) {
+	if obj.FullPercent == nil {
+		fullPercent := PackLeftFullPercentDefault
+		obj.FullPercent = &fullPercent
+	}
+	if obj.NumAvoid == 0 && obj.PercentAvoid == nil {
+		obj.NumAvoid = PackLeftNumAvoidDefault
+	}
+	if len(obj.Resources) == 0 {
+		obj.Resources = []PackLeftResource{
+			{Name: corev1.ResourceCPU},
+			{Name: corev1.ResourceMemory},
+		}
+	}
+	if obj.Aggregation == PackLeftAggregationUndefined {
+		obj.Aggregation = PackLeftAggregationDefault
+	}
+}
+
+// SetDefaults_PackLeftResource sets the weight of a pack left resource
+func SetDefaults_PackLeftResource(obj *PackLeftResource) {
+	if obj.Weight == nil {
+		weight := PackLeftResourceWeightDefault
+		obj.Weight = &weight
+	}
+}
+
+// SetDefaults_NodeAssignmentTopologySpread sets the max skew of a spread assignment
+func SetDefaults_NodeAssignmentTopologySpread(obj *NodeAssignmentTopologySpread) {
+	if obj.MaxSkew == 0 {
+		obj.MaxSkew = TopologySpreadMaxSkewDefault
+	}
+}
+
+// SetDefaults_PodAssignmentRuleScheduling sets the merge strategy of a rule
+func SetDefaults_PodAssignmentRuleScheduling(obj *PodAssignmentRuleScheduling) {
+	if obj.MergeStrategy == PodAssignmentRuleSchedulingMergeStrategyUndefined {
+		obj.MergeStrategy = PodAssignmentRuleSchedulingMergeStrategyDefault
+	}
+}
@@ -0,0 +1,103 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestSetObjectDefaultsNodeAssignmentGroup(t *testing.T) {
+	percentAvoid, zero := 10, 0
+	nag := &NodeAssignmentGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "testnag"},
+		Spec: NodeAssignmentGroupSpec{
+			Assignments: []NodeAssignment{
+				{Name: "labeled"},
+				{Name: "tainted", Mode: NodeAssignmentModeLabelAndTaint, TopologySpread: &NodeAssignmentTopologySpread{TopologyKey: "zone"}},
+				{Name: "packed", SchedulingMode: NodeAssignmentSchedulingModePackLeft, ReassignmentPolicy: NodeAssignmentReassignmentPolicyDrain},
+				{Name: "percent", SchedulingMode: NodeAssignmentSchedulingModePackLeft, PackLeft: &PackLeftScheduling{PercentAvoid: &percentAvoid, Resources: []PackLeftResource{{Name: corev1.ResourcePods}, {Name: corev1.ResourceCPU, Weight: &zero}}}},
+			},
+			DefaultAssignment: &NodeAssignment{Name: "rest"},
+		},
+	}
+
+	// Defaults must be applied through the scheme
+	scheme := runtime.NewScheme()
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	scheme.Default(nag)
+
+	if nag.Spec.SelectionPolicy != NodeSelectionPolicyDefault {
+		t.Errorf("Expected selection policy %s, got %s", NodeSelectionPolicyDefault, nag.Spec.SelectionPolicy)
+	}
+
+	assignments := nag.Spec.Assignments
+	if assignments[0].Mode != NodeAssignmentModeLabelOnly || assignments[0].TaintEffect != NodeAssignmentTaintEffectNotSpecified {
+		t.Errorf("Unexpected defaults for LabelOnly assignment: %+v", assignments[0])
+	}
+	if assignments[0].PercentRounding != NodeAssignmentPercentRoundingDefault {
+		t.Errorf("Expected percent rounding %s, got %s", NodeAssignmentPercentRoundingDefault, assignments[0].PercentRounding)
+	}
+	if assignments[0].MinNodes != nil || assignments[0].MaxNodes != nil {
+		t.Errorf("Node bounds must not be defaulted: %+v", assignments[0])
+	}
+	if assignments[0].ReassignmentPolicy != NodeAssignmentReassignmentPolicyDefault || assignments[0].DrainTimeoutSeconds != 0 {
+		t.Errorf("Unexpected reassignment defaults: %+v", assignments[0])
+	}
+	if assignments[2].DrainTimeoutSeconds != DrainTimeoutSecondsDefault {
+		t.Errorf("Expected drain timeout %d, got %d", DrainTimeoutSecondsDefault, assignments[2].DrainTimeoutSeconds)
+	}
+	if assignments[1].TaintEffect != NodeAssignmentTaintEffectDefault {
+		t.Errorf("Expected taint effect %s, got %s", NodeAssignmentTaintEffectDefault, assignments[1].TaintEffect)
+	}
+	if assignments[1].TopologySpread.MaxSkew != TopologySpreadMaxSkewDefault {
+		t.Errorf("Expected max skew %d, got %d", TopologySpreadMaxSkewDefault, assignments[1].TopologySpread.MaxSkew)
+	}
+	if assignments[0].PackLeft != nil || assignments[1].PackLeft != nil {
+		t.Errorf("PackLeft set on an assignment that does not use it")
+	}
+
+	packLeft := assignments[2].PackLeft
+	if packLeft == nil || packLeft.FullPercent == nil || *packLeft.FullPercent != PackLeftFullPercentDefault || packLeft.NumAvoid != PackLeftNumAvoidDefault {
+		t.Errorf("Unexpected PackLeft defaults: %+v", packLeft)
+	}
+
+	// FullExitPercent must stay unset so that it follows later edits of FullPercent
+	if packLeft.FullExitPercent != nil || packLeft.MinDwellSeconds != 0 {
+		t.Errorf("Unexpected PackLeft hysteresis defaults: %+v", packLeft)
+	}
+	if len(packLeft.Resources) != 2 || packLeft.Resources[0].Name != corev1.ResourceCPU || packLeft.Resources[1].Name != corev1.ResourceMemory ||
+		*packLeft.Resources[1].Weight != PackLeftResourceWeightDefault || packLeft.Aggregation != PackLeftAggregationMax {
+		t.Errorf("Unexpected PackLeft resource defaults: %+v", packLeft)
+	}
+	// An explicit weight of 0 is kept
+	if r := assignments[3].PackLeft.Resources; len(r) != 2 || *r[0].Weight != PackLeftResourceWeightDefault || *r[1].Weight != 0 {
+		t.Errorf("Unexpected PackLeft resources: %+v", r)
+	}
+
+	// An explicit PercentAvoid must not be combined with the default NumAvoid
+	if assignments[3].PackLeft.NumAvoid != 0 {
+		t.Errorf("Expected NumAvoid to stay unset when PercentAvoid is set, got %d", assignments[3].PackLeft.NumAvoid)
+	}
+
+	if nag.Spec.DefaultAssignment.Mode != NodeAssignmentModeLabelOnly {
+		t.Errorf("Default assignment was not defaulted: %+v", nag.Spec.DefaultAssignment)
+	}
+}
+
+func TestSetObjectDefaultsClusterPodAssignmentRule(t *testing.T) {
+	cpar := &ClusterPodAssignmentRule{}
+	SetObjectDefaults_ClusterPodAssignmentRule(cpar)
+	if cpar.Spec.Scheduling.MergeStrategy != PodAssignmentRuleSchedulingMergeStrategyOverwriteAll {
+		t.Errorf("Expected merge strategy %s, got %s", PodAssignmentRuleSchedulingMergeStrategyOverwriteAll, cpar.Spec.Scheduling.MergeStrategy)
+	}
+
+	par := &PodAssignmentRule{Spec: PodAssignmentRuleSpec{Scheduling: PodAssignmentRuleScheduling{MergeStrategy: PodAssignmentRuleSchedulingMergeStrategyReject}}}
+	SetObjectDefaults_PodAssignmentRule(par)
+	if par.Spec.Scheduling.MergeStrategy != PodAssignmentRuleSchedulingMergeStrategyReject {
+		t.Errorf("Explicit merge strategy was overwritten: %s", par.Spec.Scheduling.MergeStrategy)
+	}
+}
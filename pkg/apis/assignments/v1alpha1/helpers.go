@@ -1,10 +1,18 @@
 package v1alpha1
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/domoinc/kube-valet/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 const (
@@ -16,6 +24,31 @@ const (
 	ProtectedLabelValue = "true"
 )
 
+// Provenance annotations record the last change kube-valet made to a label of a group on a node. Their key is the
+// prefix followed by the label key. Ex: assigned-at.nag.assignments.kube-valet.io/NAGNAME
+const (
+	// ProvenanceAssignedAt is when the label was set
+	ProvenanceAssignedAt = "assigned-at"
+	// ProvenanceAssignedBy is the identity of the kube-valet replica that set the label
+	ProvenanceAssignedBy = "assigned-by"
+	// ProvenanceGeneration and ProvenanceUID identify the version of the group the label was set for
+	ProvenanceGeneration = "generation"
+	ProvenanceUID        = "uid"
+	// ProvenancePrevious is the value the label had before. Not set when there was none
+	ProvenancePrevious = "previous"
+	// ProvenancePercentFull is how full a pack left node was when its state was set
+	ProvenancePercentFull = "percent-full"
+)
+
+var provenancePrefixes = []string{
+	ProvenanceAssignedAt,
+	ProvenanceAssignedBy,
+	ProvenanceGeneration,
+	ProvenanceUID,
+	ProvenancePrevious,
+	ProvenancePercentFull,
+}
+
 // DeleteTaintsByKey removes all the taints that have the same key to given taintKey
 func deleteTaintsByKey(taints []corev1.Taint, taintKey string) ([]corev1.Taint, bool) {
 	newTaints := []corev1.Taint{}
@@ -86,35 +119,220 @@ func (nag *NodeAssignmentGroup) TargetsNode(node *corev1.Node) bool {
 		}
 	}
 
-	return matched
+	if !matched || !SelectorMatchesLabels(nag.Spec.NodeSelector, node.GetLabels()) {
+		return false
+	}
+
+	return nag.Spec.NodeFieldSelector.MatchesNode(node)
+}
+
+// MatchesNode returns true if the node matches all the fields of the selector. A nil selector matches all nodes.
+func (s *NodeFieldSelector) MatchesNode(node *corev1.Node) bool {
+	if s == nil {
+		return true
+	}
+
+	if s.Unschedulable != nil && *s.Unschedulable != node.Spec.Unschedulable {
+		return false
+	}
+
+	for i := range s.TaintsPresent {
+		if !hasMatchingTaint(node.Spec.Taints, &s.TaintsPresent[i]) {
+			return false
+		}
+	}
+
+	for i := range s.TaintsAbsent {
+		if hasMatchingTaint(node.Spec.Taints, &s.TaintsAbsent[i]) {
+			return false
+		}
+	}
+
+	return true
 }
 
+// MatchesNode returns true if the node meets all the requirements. A nil requirement matches all nodes.
+// When the node is only too young, the time until it is old enough is returned as well.
+func (r *NodeAssignmentRequirements) MatchesNode(node *corev1.Node, now time.Time) (bool, time.Duration) {
+	if r == nil {
+		return true, 0
+	}
+
+	for name, min := range r.MinAllocatable {
+		if allocatable, ok := node.Status.Allocatable[name]; !ok || allocatable.Cmp(min) < 0 {
+			return false, 0
+		}
+	}
+
+	if !SelectorMatchesLabels(r.NodeSelector, node.GetLabels()) {
+		return false, 0
+	}
+
+	if r.ExcludeUnschedulable && node.Spec.Unschedulable {
+		return false, 0
+	}
+
+	if r.ExcludeNotReady && !nodeIsReady(node) {
+		return false, 0
+	}
+
+	if wait := node.CreationTimestamp.Add(time.Duration(r.MinAgeSeconds) * time.Second).Sub(now); wait > 0 {
+		return false, wait
+	}
+
+	return true, 0
+}
+
+// GetMinNodes returns the lower bound on the number of nodes the assignment asks for
+func (na *NodeAssignment) GetMinNodes() int {
+	if na.MinNodes == nil {
+		return 1
+	}
+	return *na.MinNodes
+}
+
+// DesiredNodes returns the number of nodes the assignment asks for out of numNodes matching nodes. The larger
+// of NumDesired and the rounded PercentDesired is used and then held within MinNodes and MaxNodes.
+func (na *NodeAssignment) DesiredNodes(numNodes int) int {
+	percent := numNodes * na.PercentDesired
+	var desired int
+	switch na.PercentRounding {
+	case NodeAssignmentPercentRoundingCeil:
+		desired = (percent + 99) / 100
+	case NodeAssignmentPercentRoundingNearest:
+		desired = (percent + 50) / 100
+	default:
+		desired = percent / 100
+	}
+
+	if na.NumDesired > desired {
+		desired = na.NumDesired
+	}
+	if min := na.GetMinNodes(); desired < min {
+		desired = min
+	}
+	if na.MaxNodes != nil && desired > *na.MaxNodes {
+		desired = *na.MaxNodes
+	}
+	return desired
+}
+
+func nodeIsReady(node *corev1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// hasMatchingTaint checks for a taint with the same key. Value and effect are only compared when set on want.
+func hasMatchingTaint(taints []corev1.Taint, want *corev1.Taint) bool {
+	for _, t := range taints {
+		if t.Key != want.Key {
+			continue
+		}
+		if want.Value != "" && t.Value != want.Value {
+			continue
+		}
+		if want.Effect != "" && t.Effect != want.Effect {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// SelectorMatchesLabels returns true if the selector matches the given labels. A nil selector matches everything
+// while an invalid selector matches nothing.
+func SelectorMatchesLabels(selector *metav1.LabelSelector, l map[string]string) bool {
+	if selector == nil {
+		return true
+	}
+
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false
+	}
+
+	return s.Matches(labels.Set(l))
+}
+
+// ParseSelector parses a selector string in the same syntax used by kubectl. Ex: "zone in (a,b),!gpu,tier=web"
+// An empty string results in a nil selector.
+func ParseSelector(selector string) (*metav1.LabelSelector, error) {
+	if strings.TrimSpace(selector) == "" {
+		return nil, nil
+	}
+
+	reqs, err := labels.ParseToRequirements(selector)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse the selector string \"%s\": %v", selector, err)
+	}
+
+	// Convert by hand instead of using metav1.ParseToLabelSelector, which does not support "!="
+	ls := &metav1.LabelSelector{}
+	for _, req := range reqs {
+		var op metav1.LabelSelectorOperator
+		switch req.Operator() {
+		case selection.Equals, selection.DoubleEquals:
+			if ls.MatchLabels == nil {
+				ls.MatchLabels = make(map[string]string)
+			}
+			ls.MatchLabels[req.Key()] = req.Values().List()[0]
+			continue
+		case selection.In:
+			op = metav1.LabelSelectorOpIn
+		case selection.NotIn, selection.NotEquals:
+			op = metav1.LabelSelectorOpNotIn
+		case selection.Exists:
+			op = metav1.LabelSelectorOpExists
+		case selection.DoesNotExist:
+			op = metav1.LabelSelectorOpDoesNotExist
+		default:
+			return nil, fmt.Errorf("%q isn't supported in label selectors", req.Operator())
+		}
+		ls.MatchExpressions = append(ls.MatchExpressions, metav1.LabelSelectorRequirement{
+			Key:      req.Key(),
+			Operator: op,
+			Values:   req.Values().List(),
+		})
+	}
+
+	return ls, nil
+}
+
+// GetAssignment returns the name of the assignment the group gave the node
 func (nag *NodeAssignmentGroup) GetAssignment(node *corev1.Node) (string, bool) {
-	s, ok := node.ObjectMeta.Labels["nag."+GroupName+"/"+nag.ObjectMeta.Name]
+	if applied, ok := nag.getApplied(node); ok {
+		return applied.Name, true
+	}
+	s, ok := node.ObjectMeta.Labels[nag.AssignmentLabelKey()]
 	return s, ok
 }
 
 func (nag *NodeAssignmentGroup) SetLabel(node *corev1.Node, na *NodeAssignment) {
-	// Generate assignment key from group and rule names
-	key := "nag." + GroupName + "/" + nag.ObjectMeta.Name
+	if node.ObjectMeta.Labels == nil {
+		node.ObjectMeta.Labels = make(map[string]string)
+	}
 	// Assignment
-	node.ObjectMeta.Labels[key] = na.Name
+	node.ObjectMeta.Labels[nag.labelKey(na)] = na.Name
 }
 
 func (nag *NodeAssignmentGroup) RemoveLabel(node *corev1.Node) {
 	// Delete assignment Key
 	// Ex: nag.assignments.kube-valet.io/NAGNAME
-	delete(node.ObjectMeta.Labels, "nag."+GroupName+"/"+nag.ObjectMeta.Name)
+	delete(node.ObjectMeta.Labels, nag.AssignmentLabelKey())
 
 	// Delete packleft key
 	//nag.packleft.scheduling.kube-valet.io/NAGNAME
-	delete(node.ObjectMeta.Labels, "nag.packleft.scheduling."+Domain+"/"+nag.ObjectMeta.Name)
+	delete(node.ObjectMeta.Labels, nag.PackLeftLabelKey())
 }
 
 func (nag *NodeAssignmentGroup) RemoveTaint(node *corev1.Node) []error {
 	// Removing taint
-	k := "nag." + GroupName + "/" + nag.ObjectMeta.Name
-	plk := "nag.packleft.scheduling." + Domain + "/" + nag.ObjectMeta.Name
+	k := nag.AssignmentLabelKey()
+	plk := nag.PackLeftLabelKey()
 
 	// Remove all possible any taints for the group
 	var removeTaints = []corev1.Taint{
@@ -131,36 +349,165 @@ func (nag *NodeAssignmentGroup) RemoveTaint(node *corev1.Node) []error {
 }
 
 func (nag *NodeAssignmentGroup) SetTaint(node *corev1.Node, na *NodeAssignment) {
-	// Set Taint
-	// Generate taint key from group and rule names
-	key := "nag." + GroupName + "/" + nag.ObjectMeta.Name
+	// Assignment
+	setTaint(node, corev1.Taint{
+		Key:    nag.taintKey(na),
+		Value:  na.Name,
+		Effect: na.taintEffect(),
+	})
+}
 
+// taintEffect returns the effect of the assignment taint
+func (na *NodeAssignment) taintEffect() corev1.TaintEffect {
 	if na.TaintEffect == NodeAssignmentTaintEffectNotSpecified {
-		na.TaintEffect = NodeAssignmentTaintEffectDefault
+		return NodeAssignmentTaintEffectDefault
 	}
+	return na.TaintEffect
+}
 
-	// Assignment
-	node.Spec.Taints = append(node.Spec.Taints, corev1.Taint{
-		Key:    key,
-		Value:  na.Name,
-		Effect: na.TaintEffect,
-	})
+// setTaint adds the taint to the node. A taint with the same key and effect is replaced since a node can only have one
+func setTaint(node *corev1.Node, taint corev1.Taint) {
+	node.Spec.Taints, _ = deleteTaint(node.Spec.Taints, &taint)
+	node.Spec.Taints = append(node.Spec.Taints, taint)
 }
 
 func (nag *NodeAssignmentGroup) Assign(node *corev1.Node, na *NodeAssignment) {
-	// If the user didn't define a mode, Use the default
-	if na.Mode == NodeAssignmentModeUndefined {
-		na.Mode = NodeAssignmentModeDefault
+	// If the user didn't define a mode, Use the default. The assignment is not modified
+	// since it is usually shared with an informer cache
+	mode := na.Mode
+	if mode == NodeAssignmentModeUndefined {
+		mode = NodeAssignmentModeDefault
 	}
 
 	// Label And/Or Taint based on the assignment's rule
-	switch na.Mode {
+	switch mode {
 	case NodeAssignmentModeLabelAndTaint:
 		nag.SetLabel(node, na)
 		nag.SetTaint(node, na)
 	case NodeAssignmentModeLabelOnly:
 		nag.SetLabel(node, na)
+	case NodeAssignmentModeTaintOnly:
+		nag.SetTaint(node, na)
+	}
+
+	for k, v := range na.ExtraLabels {
+		if node.ObjectMeta.Labels == nil {
+			node.ObjectMeta.Labels = make(map[string]string)
+		}
+		node.ObjectMeta.Labels[k] = v
+	}
+	for _, taint := range na.ExtraTaints {
+		setTaint(node, taint)
+	}
+
+	nag.setApplied(node, na, mode)
+}
+
+// appliedAssignment is the value of the applied annotation of a group. It names the assignment of the node and
+// lists the labels and taints it set, so that they can be removed even after the assignment was changed or
+// removed from the group.
+// +k8s:deepcopy-gen=false
+type appliedAssignment struct {
+	Name   string         `json:"name"`
+	Labels []string       `json:"labels,omitempty"`
+	Taints []corev1.Taint `json:"taints,omitempty"`
+}
+
+// AppliedAnnotationKey returns the key of the annotation that records an assignment of the group that sets more
+// than the group label. Ex: applied.nag.assignments.kube-valet.io/NAGNAME
+func (nag *NodeAssignmentGroup) AppliedAnnotationKey() string {
+	return "applied." + nag.AssignmentLabelKey()
+}
+
+// labelKey returns the key of the assignment label of na
+func (nag *NodeAssignmentGroup) labelKey(na *NodeAssignment) string {
+	if na.LabelKey != "" {
+		return na.LabelKey
+	}
+	return nag.AssignmentLabelKey()
+}
+
+// taintKey returns the key of the assignment taint of na
+func (nag *NodeAssignmentGroup) taintKey(na *NodeAssignment) string {
+	if na.TaintKey != "" {
+		return na.TaintKey
+	}
+	return nag.AssignmentLabelKey()
+}
+
+// PodWantsAssignment returns true if the pod selects the assignment label of na or tolerates its taint by key.
+// Tolerations without a key tolerate every taint, so they don't count. Ex: the tolerations of most DaemonSets
+func (nag *NodeAssignmentGroup) PodWantsAssignment(pod *corev1.Pod, na *NodeAssignment) bool {
+	labelKey := nag.labelKey(na)
+	if v, ok := pod.Spec.NodeSelector[labelKey]; ok && v == na.Name {
+		return true
+	}
+	if a := pod.Spec.Affinity; a != nil && a.NodeAffinity != nil && a.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		for _, term := range a.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+			for _, r := range term.MatchExpressions {
+				if r.Key == labelKey && r.Operator == corev1.NodeSelectorOpIn && sets.NewString(r.Values...).Has(na.Name) {
+					return true
+				}
+			}
+		}
 	}
+
+	taint := corev1.Taint{Key: nag.taintKey(na), Value: na.Name, Effect: na.taintEffect()}
+	for i := range pod.Spec.Tolerations {
+		if pod.Spec.Tolerations[i].Key == taint.Key && pod.Spec.Tolerations[i].ToleratesTaint(&taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// setApplied records what the assignment set on the node. Nodes that only have the group label, and maybe its
+// taint, don't need the record since Unassign always removes those.
+func (nag *NodeAssignmentGroup) setApplied(node *corev1.Node, na *NodeAssignment, mode NodeAssignmentMode) {
+	key := nag.AssignmentLabelKey()
+	applied := appliedAssignment{Name: na.Name}
+	if mode == NodeAssignmentModeLabelOnly || mode == NodeAssignmentModeLabelAndTaint {
+		applied.Labels = append(applied.Labels, nag.labelKey(na))
+	}
+	if mode == NodeAssignmentModeTaintOnly || mode == NodeAssignmentModeLabelAndTaint {
+		applied.Taints = append(applied.Taints, corev1.Taint{Key: nag.taintKey(na), Effect: na.taintEffect()})
+	}
+	if len(na.ExtraLabels) == 0 && len(na.ExtraTaints) == 0 && len(applied.Labels) == 1 && applied.Labels[0] == key &&
+		(len(applied.Taints) == 0 || applied.Taints[0].Key == key) {
+		delete(node.Annotations, nag.AppliedAnnotationKey())
+		return
+	}
+
+	for k := range na.ExtraLabels {
+		applied.Labels = append(applied.Labels, k)
+	}
+	sort.Strings(applied.Labels)
+	for _, taint := range na.ExtraTaints {
+		applied.Taints = append(applied.Taints, corev1.Taint{Key: taint.Key, Effect: taint.Effect})
+	}
+
+	value, err := json.Marshal(applied)
+	if err != nil {
+		return
+	}
+	if node.Annotations == nil {
+		node.Annotations = make(map[string]string)
+	}
+	node.Annotations[nag.AppliedAnnotationKey()] = string(value)
+}
+
+// getApplied returns the applied annotation of the group on the node. False is returned when there is none or it
+// can't be read
+func (nag *NodeAssignmentGroup) getApplied(node *corev1.Node) (*appliedAssignment, bool) {
+	value, ok := node.Annotations[nag.AppliedAnnotationKey()]
+	if !ok {
+		return nil, false
+	}
+	applied := &appliedAssignment{}
+	if err := json.Unmarshal([]byte(value), applied); err != nil {
+		return nil, false
+	}
+	return applied, true
 }
 
 func (s *PodAssignmentRuleSpec) TargetsPod(pod *corev1.Pod) bool {
@@ -181,7 +528,19 @@ func (s *PodAssignmentRuleSpec) TargetsPod(pod *corev1.Pod) bool {
 		}
 	}
 
-	return matched
+	return matched && SelectorMatchesLabels(s.PodSelector, pod.GetLabels())
+}
+
+// TargetsNamespace returns true if the rule applies to pods in the given namespace.
+// A rule with a namespace selector never matches a namespace that could not be found.
+func (s *ClusterPodAssignmentRuleSpec) TargetsNamespace(ns *corev1.Namespace) bool {
+	if s.NamespaceSelector == nil {
+		return true
+	}
+	if ns == nil {
+		return false
+	}
+	return SelectorMatchesLabels(s.NamespaceSelector, ns.GetLabels())
 }
 
 func (r *PodAssignmentRule) TargetsPod(pod *corev1.Pod) bool {
@@ -192,11 +551,96 @@ func (r *ClusterPodAssignmentRule) TargetsPod(pod *corev1.Pod) bool {
 	return r.Spec.TargetsPod(pod)
 }
 
+func (r *ClusterPodAssignmentRule) TargetsNamespace(ns *corev1.Namespace) bool {
+	return r.Spec.TargetsNamespace(ns)
+}
+
+// Unassign removes the assignment labels and taints of the group from the node. Labels and taints of the
+// applied annotation are removed as well.
 func (nag *NodeAssignmentGroup) Unassign(node *corev1.Node) []error {
+	if applied, ok := nag.getApplied(node); ok {
+		for _, k := range applied.Labels {
+			delete(node.ObjectMeta.Labels, k)
+		}
+		for i := range applied.Taints {
+			node.Spec.Taints, _ = deleteTaint(node.Spec.Taints, &applied.Taints[i])
+		}
+	}
+	delete(node.Annotations, nag.AppliedAnnotationKey())
 	nag.RemoveLabel(node)
+	RemoveProvenance(node, nag.AssignmentLabelKey())
+	RemoveProvenance(node, nag.PackLeftLabelKey())
 	return nag.RemoveTaint(node)
 }
 
+// AssignmentLabelKey returns the key of the assignment label and taint of the group.
+// Ex: nag.assignments.kube-valet.io/NAGNAME
+func (nag *NodeAssignmentGroup) AssignmentLabelKey() string {
+	return "nag." + GroupName + "/" + nag.ObjectMeta.Name
+}
+
+// PackLeftLabelKey returns the key of the pack left label and taint of the group.
+// Ex: nag.packleft.scheduling.kube-valet.io/NAGNAME
+func (nag *NodeAssignmentGroup) PackLeftLabelKey() string {
+	return "nag.packleft.scheduling." + Domain + "/" + nag.ObjectMeta.Name
+}
+
+// ProvenanceKey returns the key of the provenance annotation with the prefix for the label
+func ProvenanceKey(prefix string, labelKey string) string {
+	return prefix + "." + labelKey
+}
+
+// SetProvenance records on the node that the label was set by identity at now for this version of the group.
+// previous is the value the label had before. All provenance of the label that is not set again is removed.
+func (nag *NodeAssignmentGroup) SetProvenance(node *corev1.Node, labelKey string, identity string, now time.Time, previous string) {
+	RemoveProvenance(node, labelKey)
+	if node.Annotations == nil {
+		node.Annotations = make(map[string]string)
+	}
+	set := func(prefix string, value string) {
+		if value != "" {
+			node.Annotations[ProvenanceKey(prefix, labelKey)] = value
+		}
+	}
+	set(ProvenanceAssignedAt, now.UTC().Format(time.RFC3339))
+	set(ProvenanceAssignedBy, identity)
+	set(ProvenanceGeneration, strconv.FormatInt(nag.Generation, 10))
+	set(ProvenanceUID, string(nag.UID))
+	set(ProvenancePrevious, previous)
+}
+
+// RemoveProvenance removes all provenance annotations of the label from the node
+func RemoveProvenance(node *corev1.Node, labelKey string) {
+	for _, prefix := range provenancePrefixes {
+		delete(node.Annotations, ProvenanceKey(prefix, labelKey))
+	}
+}
+
+// CopyProvenance makes the provenance annotations of the label on dst the same as on src
+func CopyProvenance(dst *corev1.Node, src *corev1.Node, labelKey string) {
+	RemoveProvenance(dst, labelKey)
+	for _, prefix := range provenancePrefixes {
+		key := ProvenanceKey(prefix, labelKey)
+		if v, ok := src.Annotations[key]; ok {
+			if dst.Annotations == nil {
+				dst.Annotations = make(map[string]string)
+			}
+			dst.Annotations[key] = v
+		}
+	}
+}
+
+// GetProvenance returns the provenance annotations of the label on the node by prefix
+func GetProvenance(node *corev1.Node, labelKey string) map[string]string {
+	rtn := make(map[string]string)
+	for _, prefix := range provenancePrefixes {
+		if v, ok := node.Annotations[ProvenanceKey(prefix, labelKey)]; ok {
+			rtn[prefix] = v
+		}
+	}
+	return rtn
+}
+
 func (s *PodAssignmentRuleScheduling) GetMergeStrategy() PodAssignmentRuleSchedulingMergeStrategy {
 	if s.MergeStrategy == PodAssignmentRuleSchedulingMergeStrategyUndefined {
 		return PodAssignmentRuleSchedulingMergeStrategyDefault
@@ -204,50 +648,32 @@ func (s *PodAssignmentRuleScheduling) GetMergeStrategy() PodAssignmentRuleSchedu
 	return s.MergeStrategy
 }
 
-func (s *PodAssignmentRuleScheduling) ApplyToPod(pod *corev1.Pod) {
-	// Support all known merge strategies
-	switch ms := s.GetMergeStrategy(); ms {
-	case PodAssignmentRuleSchedulingMergeStrategyOverwriteAll:
-		if len(s.NodeSelector) != 0 {
-			pod.Spec.NodeSelector = s.NodeSelector
-		}
-		if s.Affinity != nil {
-			pod.Spec.Affinity = s.Affinity
-		}
-		if len(s.Tolerations) != 0 {
-			pod.Spec.Tolerations = s.Tolerations
+// GetCondition returns the condition with the given type or nil if it is not set
+func (s *NodeAssignmentGroupStatus) GetCondition(t NodeAssignmentGroupConditionType) *NodeAssignmentGroupCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == t {
+			return &s.Conditions[i]
 		}
 	}
+	return nil
 }
 
-func (s *PodAssignmentRuleScheduling) GetPatchOps(pod *corev1.Pod) []utils.JsonPatchOperation {
-	ops := []utils.JsonPatchOperation{}
-
-	// Support all known merge strategies
-	switch ms := s.GetMergeStrategy(); ms {
-	case PodAssignmentRuleSchedulingMergeStrategyOverwriteAll:
-		if len(s.NodeSelector) != 0 {
-			ops = append(ops, utils.JsonPatchOperation{
-				Op:    "replace",
-				Path:  "/spec/nodeSelector",
-				Value: s.NodeSelector,
-			})
-		}
-		if s.Affinity != nil {
-			ops = append(ops, utils.JsonPatchOperation{
-				Op:    "replace",
-				Path:  "/spec/affinity",
-				Value: s.Affinity,
-			})
-		}
-		if len(s.Tolerations) != 0 {
-			ops = append(ops, utils.JsonPatchOperation{
-				Op:    "replace",
-				Path:  "/spec/tolerations",
-				Value: s.Tolerations,
-			})
+// SetCondition adds or updates a condition in the status. The LastTransitionTime is only
+// changed when the status of the condition changes so that repeated reconciles are stable
+func (s *NodeAssignmentGroupStatus) SetCondition(c NodeAssignmentGroupCondition) {
+	existing := s.GetCondition(c.Type)
+	if existing == nil {
+		if c.LastTransitionTime.IsZero() {
+			c.LastTransitionTime = metav1.Now()
 		}
+		s.Conditions = append(s.Conditions, c)
+		return
 	}
 
-	return ops
+	if existing.Status == c.Status {
+		c.LastTransitionTime = existing.LastTransitionTime
+	} else if c.LastTransitionTime.IsZero() {
+		c.LastTransitionTime = metav1.Now()
+	}
+	*existing = c
 }
@@ -0,0 +1,338 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func mustParseSelector(t *testing.T, s string) *metav1.LabelSelector {
+	sel, err := ParseSelector(s)
+	if err != nil {
+		t.Fatalf("Unable to parse selector %q: %v", s, err)
+	}
+	return sel
+}
+
+func TestTargetsNode(t *testing.T) {
+	boolTrue := true
+
+	testCases := []struct {
+		name          string
+		targetLabels  labels.Set
+		nodeSelector  string
+		fieldSelector *NodeFieldSelector
+		nodeLabels    map[string]string
+		unschedulable bool
+		taints        []corev1.Taint
+		expected      bool
+	}{
+		{name: "All", nodeLabels: map[string]string{"zone": "a"}, expected: true},
+		{name: "Protected", nodeLabels: map[string]string{ProtectedNodeLabelKey: ProtectedLabelValue}, expected: false},
+		{name: "TargetLabels", targetLabels: labels.Set{"zone": "a"}, nodeLabels: map[string]string{"zone": "a"}, expected: true},
+		{name: "TargetLabelsMismatch", targetLabels: labels.Set{"zone": "a"}, nodeLabels: map[string]string{"zone": "b"}, expected: false},
+		{name: "SelectorIn", nodeSelector: "zone in (a,b),pool!=gpu", nodeLabels: map[string]string{"zone": "b", "pool": "web"}, expected: true},
+		{name: "SelectorNotIn", nodeSelector: "zone in (a,b),pool!=gpu", nodeLabels: map[string]string{"zone": "b", "pool": "gpu"}, expected: false},
+		{name: "SelectorExists", nodeSelector: "gpu", nodeLabels: map[string]string{"gpu": ""}, expected: true},
+		{name: "SelectorDoesNotExist", nodeSelector: "!gpu", nodeLabels: map[string]string{"gpu": ""}, expected: false},
+		{name: "TargetLabelsAndSelector", targetLabels: labels.Set{"pool": "web"}, nodeSelector: "zone in (a)", nodeLabels: map[string]string{"zone": "b", "pool": "web"}, expected: false},
+		{name: "Unschedulable", fieldSelector: &NodeFieldSelector{Unschedulable: &boolTrue}, unschedulable: true, expected: true},
+		{name: "NotUnschedulable", fieldSelector: &NodeFieldSelector{Unschedulable: &boolTrue}, expected: false},
+		{
+			name:          "TaintsPresent",
+			fieldSelector: &NodeFieldSelector{TaintsPresent: []corev1.Taint{{Key: "dedicated"}}},
+			taints:        []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}},
+			expected:      true,
+		},
+		{
+			name:          "TaintsPresentEffectMismatch",
+			fieldSelector: &NodeFieldSelector{TaintsPresent: []corev1.Taint{{Key: "dedicated", Effect: corev1.TaintEffectNoExecute}}},
+			taints:        []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}},
+			expected:      false,
+		},
+		{
+			name:          "TaintsAbsent",
+			fieldSelector: &NodeFieldSelector{TaintsAbsent: []corev1.Taint{{Key: "dedicated", Value: "gpu"}}},
+			taints:        []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}},
+			expected:      false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			nag := &NodeAssignmentGroup{
+				Spec: NodeAssignmentGroupSpec{
+					TargetLabels:      tc.targetLabels,
+					NodeSelector:      mustParseSelector(t, tc.nodeSelector),
+					NodeFieldSelector: tc.fieldSelector,
+				},
+			}
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Labels: tc.nodeLabels},
+				Spec: corev1.NodeSpec{
+					Unschedulable: tc.unschedulable,
+					Taints:        tc.taints,
+				},
+			}
+			if r := nag.TargetsNode(node); r != tc.expected {
+				t.Errorf("got %v, want %v", r, tc.expected)
+			}
+		})
+	}
+}
+
+func TestRequirementsMatchesNode(t *testing.T) {
+	now := time.Now()
+	newNode := func(memory string, ready corev1.ConditionStatus, age time.Duration) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:            map[string]string{"disk": "ssd"},
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(memory)},
+				Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+			},
+		}
+	}
+	reqs := &NodeAssignmentRequirements{
+		MinAllocatable:  corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Gi")},
+		NodeSelector:    mustParseSelector(t, "disk=ssd"),
+		ExcludeNotReady: true,
+		MinAgeSeconds:   600,
+	}
+
+	testCases := []struct {
+		name    string
+		node    *corev1.Node
+		matches bool
+		wait    time.Duration
+	}{
+		{"Eligible", newNode("128Gi", corev1.ConditionTrue, time.Hour), true, 0},
+		{"TooSmall", newNode("32Gi", corev1.ConditionTrue, time.Hour), false, 0},
+		{"NotReady", newNode("128Gi", corev1.ConditionFalse, time.Hour), false, 0},
+		{"TooYoung", newNode("128Gi", corev1.ConditionTrue, time.Minute), false, 9 * time.Minute},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			matches, wait := reqs.MatchesNode(tc.node, now)
+			if matches != tc.matches || wait != tc.wait {
+				t.Errorf("got %v, %s; want %v, %s", matches, wait, tc.matches, tc.wait)
+			}
+		})
+	}
+
+	var none *NodeAssignmentRequirements
+	if matches, _ := none.MatchesNode(&corev1.Node{}, now); !matches {
+		t.Errorf("nil requirements must match all nodes")
+	}
+}
+
+func TestDesiredNodes(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+
+	testCases := []struct {
+		name     string
+		na       NodeAssignment
+		numNodes int
+		desired  int
+	}{
+		{"FloorWithImplicitMin", NodeAssignment{PercentDesired: 10}, 5, 1},
+		{"MinOptOut", NodeAssignment{PercentDesired: 10, MinNodes: intPtr(0)}, 5, 0},
+		{"Floor", NodeAssignment{PercentDesired: 30, PercentRounding: NodeAssignmentPercentRoundingFloor}, 15, 4},
+		{"Ceil", NodeAssignment{PercentDesired: 30, PercentRounding: NodeAssignmentPercentRoundingCeil}, 15, 5},
+		{"NearestDown", NodeAssignment{PercentDesired: 30, PercentRounding: NodeAssignmentPercentRoundingNearest}, 11, 3},
+		{"NearestHalfUp", NodeAssignment{PercentDesired: 30, PercentRounding: NodeAssignmentPercentRoundingNearest}, 15, 5},
+		{"NumDesiredLarger", NodeAssignment{PercentDesired: 10, NumDesired: 3}, 10, 3},
+		{"MinNodes", NodeAssignment{PercentDesired: 10, MinNodes: intPtr(4)}, 10, 4},
+		{"MaxNodes", NodeAssignment{PercentDesired: 50, MaxNodes: intPtr(3)}, 100, 3},
+		{"MaxNodesCapsNumDesired", NodeAssignment{NumDesired: 5, MaxNodes: intPtr(2)}, 10, 2},
+		{"NoNodes", NodeAssignment{PercentDesired: 50, MinNodes: intPtr(0)}, 0, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if desired := tc.na.DesiredNodes(tc.numNodes); desired != tc.desired {
+				t.Errorf("got %d; want %d", desired, tc.desired)
+			}
+		})
+	}
+}
+
+func TestAssign(t *testing.T) {
+	nag := &NodeAssignmentGroup{ObjectMeta: metav1.ObjectMeta{Name: "testnag"}}
+	key := nag.AssignmentLabelKey()
+	ingress := corev1.Taint{Key: "node-role.kubernetes.io/ingress", Effect: corev1.TaintEffectNoSchedule}
+
+	testCases := []struct {
+		name    string
+		na      NodeAssignment
+		labels  map[string]string
+		taints  []corev1.Taint
+		applied bool
+	}{
+		{
+			name:   "LabelOnly",
+			na:     NodeAssignment{Name: "web"},
+			labels: map[string]string{"pool": "web", key: "web"},
+		},
+		{
+			name:   "LabelAndTaint",
+			na:     NodeAssignment{Name: "web", Mode: NodeAssignmentModeLabelAndTaint},
+			labels: map[string]string{"pool": "web", key: "web"},
+			taints: []corev1.Taint{{Key: key, Value: "web", Effect: corev1.TaintEffectNoSchedule}},
+		},
+		{
+			name:    "TaintOnly",
+			na:      NodeAssignment{Name: "web", Mode: NodeAssignmentModeTaintOnly, TaintEffect: corev1.TaintEffectNoExecute},
+			labels:  map[string]string{"pool": "web"},
+			taints:  []corev1.Taint{{Key: key, Value: "web", Effect: corev1.TaintEffectNoExecute}},
+			applied: true,
+		},
+		{
+			name: "CustomKeys",
+			na: NodeAssignment{Name: "ingress", Mode: NodeAssignmentModeLabelAndTaint, LabelKey: "role", TaintKey: "dedicated",
+				ExtraLabels: map[string]string{"node-role.kubernetes.io/ingress": ""}, ExtraTaints: []corev1.Taint{ingress}},
+			labels:  map[string]string{"pool": "web", "role": "ingress", "node-role.kubernetes.io/ingress": ""},
+			taints:  []corev1.Taint{{Key: "dedicated", Value: "ingress", Effect: corev1.TaintEffectNoSchedule}, ingress},
+			applied: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node0", Labels: map[string]string{"pool": "web"}}}
+			na := tc.na
+			nag.Assign(node, &na)
+
+			if !apiequality.Semantic.DeepEqual(node.Labels, tc.labels) {
+				t.Errorf("Unexpected labels: %v", node.Labels)
+			}
+			if !apiequality.Semantic.DeepEqual(node.Spec.Taints, tc.taints) {
+				t.Errorf("Unexpected taints: %v", node.Spec.Taints)
+			}
+			if _, ok := node.Annotations[nag.AppliedAnnotationKey()]; ok != tc.applied {
+				t.Errorf("Unexpected annotations: %v", node.Annotations)
+			}
+			if a, ok := nag.GetAssignment(node); !ok || a != na.Name {
+				t.Errorf("Expected assignment %s, got %s", na.Name, a)
+			}
+
+			// Unassign must not depend on the assignment still being in the group
+			nag.Unassign(node)
+			if len(node.Labels) != 1 || len(node.Spec.Taints) != 0 || len(node.Annotations) != 0 {
+				t.Errorf("Assignment was not removed: %v %v %v", node.Labels, node.Spec.Taints, node.Annotations)
+			}
+			if _, ok := nag.GetAssignment(node); ok {
+				t.Errorf("Node is still assigned")
+			}
+		})
+	}
+}
+
+func TestPodWantsAssignment(t *testing.T) {
+	nag := &NodeAssignmentGroup{ObjectMeta: metav1.ObjectMeta{Name: "testnag"}}
+	na := &NodeAssignment{Name: "gpu", Mode: NodeAssignmentModeLabelAndTaint, TaintEffect: corev1.TaintEffectNoSchedule}
+	custom := &NodeAssignment{Name: "gpu", LabelKey: "dedicated", TaintKey: "dedicated"}
+	key := nag.AssignmentLabelKey()
+
+	testCases := []struct {
+		name     string
+		spec     corev1.PodSpec
+		na       *NodeAssignment
+		expected bool
+	}{
+		{"Nothing", corev1.PodSpec{}, na, false},
+		{"NodeSelector", corev1.PodSpec{NodeSelector: map[string]string{key: "gpu"}}, na, true},
+		{"OtherAssignment", corev1.PodSpec{NodeSelector: map[string]string{key: "cpu"}}, na, false},
+		{"CustomLabelKey", corev1.PodSpec{NodeSelector: map[string]string{"dedicated": "gpu"}}, custom, true},
+		{"NodeAffinity", corev1.PodSpec{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: key, Operator: corev1.NodeSelectorOpIn, Values: []string{"cpu", "gpu"}}}},
+			}},
+		}}}, na, true},
+		{"Toleration", corev1.PodSpec{Tolerations: []corev1.Toleration{{Key: key, Operator: corev1.TolerationOpExists}}}, na, true},
+		{"OtherTolerationValue", corev1.PodSpec{Tolerations: []corev1.Toleration{{Key: key, Value: "cpu"}}}, na, false},
+		{"TolerateEverything", corev1.PodSpec{Tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpExists}}}, na, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if wants := nag.PodWantsAssignment(&corev1.Pod{Spec: tc.spec}, tc.na); wants != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, wants)
+			}
+		})
+	}
+}
+
+func TestParseSelector(t *testing.T) {
+	if sel := mustParseSelector(t, ""); sel != nil {
+		t.Errorf("Expected nil selector for empty string, got %+v", sel)
+	}
+
+	sel := mustParseSelector(t, "tier=web")
+	if sel.MatchExpressions != nil || sel.MatchLabels["tier"] != "web" {
+		t.Errorf("Unexpected selector for equality: %+v", sel)
+	}
+
+	if _, err := ParseSelector("zone in (a"); err == nil {
+		t.Errorf("Expected an error for an invalid selector")
+	}
+}
+
+func TestTargetsPod(t *testing.T) {
+	testCases := []struct {
+		name         string
+		targetLabels labels.Set
+		podSelector  string
+		podLabels    map[string]string
+		expected     bool
+	}{
+		{name: "All", podLabels: map[string]string{"app": "web"}, expected: true},
+		{name: "TargetLabels", targetLabels: labels.Set{"app": "web"}, podLabels: map[string]string{"app": "web"}, expected: true},
+		{name: "TargetLabelsMismatch", targetLabels: labels.Set{"app": "web"}, podLabels: map[string]string{"app": "db"}, expected: false},
+		{name: "SelectorNotIn", podSelector: "tier notin (cache)", podLabels: map[string]string{"tier": "db"}, expected: true},
+		{name: "SelectorNotInMismatch", podSelector: "tier notin (cache)", podLabels: map[string]string{"tier": "cache"}, expected: false},
+		{name: "TargetLabelsAndSelector", targetLabels: labels.Set{"app": "web"}, podSelector: "tier", podLabels: map[string]string{"app": "web"}, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := &PodAssignmentRuleSpec{
+				TargetLabels: tc.targetLabels,
+				PodSelector:  mustParseSelector(t, tc.podSelector),
+			}
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: tc.podLabels}}
+			if r := spec.TargetsPod(pod); r != tc.expected {
+				t.Errorf("got %v, want %v", r, tc.expected)
+			}
+		})
+	}
+}
+
+func TestTargetsNamespace(t *testing.T) {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "data", Labels: map[string]string{"team": "data"}}}
+
+	spec := &ClusterPodAssignmentRuleSpec{}
+	if !spec.TargetsNamespace(ns) || !spec.TargetsNamespace(nil) {
+		t.Errorf("Expected a rule without a namespaceSelector to match all namespaces")
+	}
+
+	spec.NamespaceSelector = mustParseSelector(t, "team in (data,ml)")
+	if !spec.TargetsNamespace(ns) {
+		t.Errorf("Expected namespace %s to match", ns.Name)
+	}
+	if spec.TargetsNamespace(nil) {
+		t.Errorf("Expected an unknown namespace not to match")
+	}
+
+	spec.NamespaceSelector = mustParseSelector(t, "team=web")
+	if spec.TargetsNamespace(ns) {
+		t.Errorf("Expected namespace %s not to match", ns.Name)
+	}
+}
@@ -0,0 +1,343 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/domoinc/kube-valet/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ApplyToPod applies the scheduling details to the pod using the merge strategy of the rule.
+// An error is returned when the pod conflicts with a rule that uses the Reject strategy.
+func (s *PodAssignmentRuleScheduling) ApplyToPod(pod *corev1.Pod) error {
+	// Work on a copy so the pod never shares memory with the rule
+	rule := s.DeepCopy()
+
+	// Support all known merge strategies
+	switch ms := s.GetMergeStrategy(); ms {
+	case PodAssignmentRuleSchedulingMergeStrategyOverwriteAll:
+		if len(rule.NodeSelector) != 0 {
+			pod.Spec.NodeSelector = rule.NodeSelector
+		}
+		if rule.Affinity != nil {
+			pod.Spec.Affinity = rule.Affinity
+		}
+		if len(rule.Tolerations) != 0 {
+			pod.Spec.Tolerations = rule.Tolerations
+		}
+	case PodAssignmentRuleSchedulingMergeStrategyMergeAppend:
+		pod.Spec.NodeSelector = mergeNodeSelector(pod.Spec.NodeSelector, rule.NodeSelector)
+		pod.Spec.Affinity = mergeAffinity(pod.Spec.Affinity, rule.Affinity)
+		pod.Spec.Tolerations = mergeTolerations(pod.Spec.Tolerations, rule.Tolerations)
+	case PodAssignmentRuleSchedulingMergeStrategyKeepExisting:
+		if len(pod.Spec.NodeSelector) == 0 && len(rule.NodeSelector) != 0 {
+			pod.Spec.NodeSelector = rule.NodeSelector
+		}
+		pod.Spec.Affinity = fillAffinity(pod.Spec.Affinity, rule.Affinity)
+		if len(pod.Spec.Tolerations) == 0 && len(rule.Tolerations) != 0 {
+			pod.Spec.Tolerations = rule.Tolerations
+		}
+	case PodAssignmentRuleSchedulingMergeStrategyReject:
+		if err := rule.conflictsWith(pod); err != nil {
+			return err
+		}
+		pod.Spec.NodeSelector = mergeNodeSelector(pod.Spec.NodeSelector, rule.NodeSelector)
+		pod.Spec.Affinity = mergeAffinity(pod.Spec.Affinity, rule.Affinity)
+		pod.Spec.Tolerations = mergeTolerations(pod.Spec.Tolerations, rule.Tolerations)
+	default:
+		return fmt.Errorf("unknown merge strategy %q", ms)
+	}
+
+	return nil
+}
+
+// GetPatchOps returns the JSON patch operations that apply the scheduling details to the pod.
+// The pod is not modified.
+func (s *PodAssignmentRuleScheduling) GetPatchOps(pod *corev1.Pod) ([]utils.JsonPatchOperation, error) {
+	mutated := pod.DeepCopy()
+	if err := s.ApplyToPod(mutated); err != nil {
+		return nil, err
+	}
+	return utils.PodSchedulingPatchOps(pod, mutated), nil
+}
+
+// conflictsWith returns an error describing the first scheduling requirement of the pod that contradicts the rule.
+// Requirements only conflict when no node or placement could satisfy both. Preferences never conflict.
+func (s *PodAssignmentRuleScheduling) conflictsWith(pod *corev1.Pod) error {
+	for k, v := range s.NodeSelector {
+		if pv, ok := pod.Spec.NodeSelector[k]; ok && pv != v {
+			return fmt.Errorf("pod nodeSelector %s=%s conflicts with %s=%s", k, pv, k, v)
+		}
+	}
+
+	if !nodeTermsIntersect(requiredNodeTerms(pod.Spec.NodeSelector, pod.Spec.Affinity), requiredNodeTerms(s.NodeSelector, s.Affinity)) {
+		return fmt.Errorf("pod nodeAffinity conflicts with the rule")
+	}
+
+	if s.Affinity == nil || pod.Spec.Affinity == nil {
+		return nil
+	}
+	if podAffinityTermsOverlap(requiredPodAffinityTerms(pod.Spec.Affinity), requiredPodAntiAffinityTerms(s.Affinity)) {
+		return fmt.Errorf("pod podAffinity conflicts with the podAntiAffinity of the rule")
+	}
+	if podAffinityTermsOverlap(requiredPodAntiAffinityTerms(pod.Spec.Affinity), requiredPodAffinityTerms(s.Affinity)) {
+		return fmt.Errorf("pod podAntiAffinity conflicts with the podAffinity of the rule")
+	}
+	return nil
+}
+
+// requiredNodeTerms returns the node selector terms a node has to match one of to satisfy both the nodeSelector and
+// the required nodeAffinity
+func requiredNodeTerms(nodeSelector map[string]string, affinity *corev1.Affinity) []corev1.NodeSelectorTerm {
+	base := corev1.NodeSelectorTerm{}
+	for _, k := range sets.StringKeySet(nodeSelector).List() {
+		base.MatchExpressions = append(base.MatchExpressions, corev1.NodeSelectorRequirement{Key: k, Operator: corev1.NodeSelectorOpIn, Values: []string{nodeSelector[k]}})
+	}
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil ||
+		len(affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms) == 0 {
+		return []corev1.NodeSelectorTerm{base}
+	}
+	return mergeRequiredNodeSelector(&corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{base}},
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution).NodeSelectorTerms
+}
+
+// nodeTermsIntersect returns true if a node could match one of the terms of a and one of the terms of b
+func nodeTermsIntersect(a []corev1.NodeSelectorTerm, b []corev1.NodeSelectorTerm) bool {
+	for _, at := range a {
+		for _, bt := range b {
+			if nodeRequirementsSatisfiable(append(append([]corev1.NodeSelectorRequirement{}, at.MatchExpressions...), bt.MatchExpressions...)) &&
+				nodeRequirementsSatisfiable(append(append([]corev1.NodeSelectorRequirement{}, at.MatchFields...), bt.MatchFields...)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nodeRequirementsSatisfiable returns true if a node could match all of the requirements
+func nodeRequirementsSatisfiable(reqs []corev1.NodeSelectorRequirement) bool {
+	byKey := make(map[string][]corev1.NodeSelectorRequirement)
+	for _, r := range reqs {
+		byKey[r.Key] = append(byKey[r.Key], r)
+	}
+	for _, keyReqs := range byKey {
+		if !keyRequirementsSatisfiable(keyReqs) {
+			return false
+		}
+	}
+	return true
+}
+
+// keyRequirementsSatisfiable returns true if a value of a single key could match all of the requirements. Values that
+// are not integers never match Gt and Lt.
+func keyRequirementsSatisfiable(reqs []corev1.NodeSelectorRequirement) bool {
+	var allowed sets.String
+	excluded := sets.NewString()
+	mustExist, mustNotExist := false, false
+	var gt, lt *int64
+	for _, r := range reqs {
+		switch r.Operator {
+		case corev1.NodeSelectorOpIn:
+			mustExist = true
+			if allowed == nil {
+				allowed = sets.NewString(r.Values...)
+			} else {
+				allowed = allowed.Intersection(sets.NewString(r.Values...))
+			}
+		case corev1.NodeSelectorOpNotIn:
+			excluded.Insert(r.Values...)
+		case corev1.NodeSelectorOpExists:
+			mustExist = true
+		case corev1.NodeSelectorOpDoesNotExist:
+			mustNotExist = true
+		case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+			mustExist = true
+			if len(r.Values) != 1 {
+				continue
+			}
+			v, err := strconv.ParseInt(r.Values[0], 10, 64)
+			if err != nil {
+				continue
+			}
+			if r.Operator == corev1.NodeSelectorOpGt && (gt == nil || v > *gt) {
+				gt = &v
+			}
+			if r.Operator == corev1.NodeSelectorOpLt && (lt == nil || v < *lt) {
+				lt = &v
+			}
+		}
+	}
+
+	if mustNotExist {
+		return !mustExist
+	}
+	if gt != nil && lt != nil && *gt+1 >= *lt {
+		return false
+	}
+	if allowed == nil {
+		return true
+	}
+	for _, v := range allowed.Difference(excluded).List() {
+		if gt == nil && lt == nil {
+			return true
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err == nil && (gt == nil || n > *gt) && (lt == nil || n < *lt) {
+			return true
+		}
+	}
+	return false
+}
+
+func requiredPodAffinityTerms(affinity *corev1.Affinity) []corev1.PodAffinityTerm {
+	if affinity.PodAffinity == nil {
+		return nil
+	}
+	return affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+}
+
+func requiredPodAntiAffinityTerms(affinity *corev1.Affinity) []corev1.PodAffinityTerm {
+	if affinity.PodAntiAffinity == nil {
+		return nil
+	}
+	return affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+}
+
+// podAffinityTermsOverlap returns true if a term is in both a and b. A pod can't require to be placed with and away
+// from the same pods.
+func podAffinityTermsOverlap(a []corev1.PodAffinityTerm, b []corev1.PodAffinityTerm) bool {
+	for i := range a {
+		for j := range b {
+			if apiequality.Semantic.DeepEqual(a[i], b[j]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func mergeNodeSelector(existing map[string]string, add map[string]string) map[string]string {
+	if len(add) == 0 {
+		return existing
+	}
+	merged := make(map[string]string, len(existing)+len(add))
+	for k, v := range existing {
+		merged[k] = v
+	}
+	for k, v := range add {
+		merged[k] = v
+	}
+	return merged
+}
+
+func mergeTolerations(existing []corev1.Toleration, add []corev1.Toleration) []corev1.Toleration {
+	merged := existing
+	for i := range add {
+		found := false
+		for j := range merged {
+			if merged[j].MatchToleration(&add[i]) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, add[i])
+		}
+	}
+	return merged
+}
+
+// fillAffinity sets each kind of affinity that is missing from existing
+func fillAffinity(existing *corev1.Affinity, add *corev1.Affinity) *corev1.Affinity {
+	if add == nil {
+		return existing
+	}
+	if existing == nil {
+		return add
+	}
+	if existing.NodeAffinity == nil {
+		existing.NodeAffinity = add.NodeAffinity
+	}
+	if existing.PodAffinity == nil {
+		existing.PodAffinity = add.PodAffinity
+	}
+	if existing.PodAntiAffinity == nil {
+		existing.PodAntiAffinity = add.PodAntiAffinity
+	}
+	return existing
+}
+
+func mergeAffinity(existing *corev1.Affinity, add *corev1.Affinity) *corev1.Affinity {
+	if add == nil {
+		return existing
+	}
+	if existing == nil {
+		return add
+	}
+
+	if add.NodeAffinity != nil {
+		if existing.NodeAffinity == nil {
+			existing.NodeAffinity = add.NodeAffinity
+		} else {
+			existing.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = mergeRequiredNodeSelector(
+				existing.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+				add.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution)
+			existing.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+				existing.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+				add.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution...)
+		}
+	}
+
+	if add.PodAffinity != nil {
+		if existing.PodAffinity == nil {
+			existing.PodAffinity = add.PodAffinity
+		} else {
+			existing.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(
+				existing.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+				add.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+			existing.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+				existing.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+				add.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution...)
+		}
+	}
+
+	if add.PodAntiAffinity != nil {
+		if existing.PodAntiAffinity == nil {
+			existing.PodAntiAffinity = add.PodAntiAffinity
+		} else {
+			existing.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(
+				existing.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+				add.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+			existing.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+				existing.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+				add.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution...)
+		}
+	}
+
+	return existing
+}
+
+// mergeRequiredNodeSelector requires both selectors to match. Terms within a selector are ORed,
+// so every existing term is combined with every added term.
+func mergeRequiredNodeSelector(existing *corev1.NodeSelector, add *corev1.NodeSelector) *corev1.NodeSelector {
+	if add == nil || len(add.NodeSelectorTerms) == 0 {
+		return existing
+	}
+	if existing == nil || len(existing.NodeSelectorTerms) == 0 {
+		return add
+	}
+
+	merged := &corev1.NodeSelector{}
+	for _, et := range existing.NodeSelectorTerms {
+		for _, at := range add.NodeSelectorTerms {
+			term := et.DeepCopy()
+			term.MatchExpressions = append(term.MatchExpressions, at.MatchExpressions...)
+			term.MatchFields = append(term.MatchFields, at.MatchFields...)
+			merged.NodeSelectorTerms = append(merged.NodeSelectorTerms, *term)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,279 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestRuleScheduling(ms PodAssignmentRuleSchedulingMergeStrategy) *PodAssignmentRuleScheduling {
+	return &PodAssignmentRuleScheduling{
+		MergeStrategy: ms,
+		NodeSelector:  map[string]string{"disk": "ssd"},
+		Affinity: &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{
+						{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}}},
+					},
+				},
+			},
+		},
+		Tolerations: []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}},
+	}
+}
+
+func newTestSchedulingPod() *corev1.Pod {
+	return &corev1.Pod{
+		Spec: corev1.PodSpec{
+			NodeSelector: map[string]string{"arch": "amd64"},
+			Affinity: &corev1.Affinity{
+				NodeAffinity: &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{
+							{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"web"}}}},
+							{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"api"}}}},
+						},
+					},
+				},
+			},
+			Tolerations: []corev1.Toleration{{Key: "spot", Operator: corev1.TolerationOpExists}},
+		},
+	}
+}
+
+func TestApplyToPodOverwriteAll(t *testing.T) {
+	pod := newTestSchedulingPod()
+	rule := newTestRuleScheduling(PodAssignmentRuleSchedulingMergeStrategyUndefined)
+	if err := rule.ApplyToPod(pod); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(pod.Spec.NodeSelector) != 1 || pod.Spec.NodeSelector["disk"] != "ssd" {
+		t.Errorf("Unexpected nodeSelector: %v", pod.Spec.NodeSelector)
+	}
+	if len(pod.Spec.Tolerations) != 1 || pod.Spec.Tolerations[0].Key != "dedicated" {
+		t.Errorf("Unexpected tolerations: %v", pod.Spec.Tolerations)
+	}
+
+	// The pod must not share memory with the rule
+	pod.Spec.NodeSelector["disk"] = "hdd"
+	if rule.NodeSelector["disk"] != "ssd" {
+		t.Errorf("Rule was modified through the pod")
+	}
+}
+
+func TestApplyToPodMergeAppend(t *testing.T) {
+	pod := newTestSchedulingPod()
+	if err := newTestRuleScheduling(PodAssignmentRuleSchedulingMergeStrategyMergeAppend).ApplyToPod(pod); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if pod.Spec.NodeSelector["arch"] != "amd64" || pod.Spec.NodeSelector["disk"] != "ssd" {
+		t.Errorf("Unexpected nodeSelector: %v", pod.Spec.NodeSelector)
+	}
+	if len(pod.Spec.Tolerations) != 2 {
+		t.Errorf("Expected 2 tolerations, got %v", pod.Spec.Tolerations)
+	}
+
+	// The rule requirement must be added to each existing term
+	terms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if len(terms) != 2 {
+		t.Fatalf("Expected 2 node selector terms, got %d", len(terms))
+	}
+	for _, term := range terms {
+		if len(term.MatchExpressions) != 2 || term.MatchExpressions[1].Key != "zone" {
+			t.Errorf("Unexpected node selector term: %+v", term)
+		}
+	}
+
+	// Applying the same rule again must not duplicate tolerations
+	if err := newTestRuleScheduling(PodAssignmentRuleSchedulingMergeStrategyMergeAppend).ApplyToPod(pod); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(pod.Spec.Tolerations) != 2 {
+		t.Errorf("Expected 2 tolerations after reapplying, got %v", pod.Spec.Tolerations)
+	}
+}
+
+func TestApplyToPodKeepExisting(t *testing.T) {
+	pod := newTestSchedulingPod()
+	pod.Spec.Tolerations = nil
+	if err := newTestRuleScheduling(PodAssignmentRuleSchedulingMergeStrategyKeepExisting).ApplyToPod(pod); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(pod.Spec.NodeSelector) != 1 || pod.Spec.NodeSelector["arch"] != "amd64" {
+		t.Errorf("Unexpected nodeSelector: %v", pod.Spec.NodeSelector)
+	}
+	if len(pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms) != 2 {
+		t.Errorf("Existing nodeAffinity was changed")
+	}
+	if len(pod.Spec.Tolerations) != 1 || pod.Spec.Tolerations[0].Key != "dedicated" {
+		t.Errorf("Unexpected tolerations: %v", pod.Spec.Tolerations)
+	}
+}
+
+func TestApplyToPodReject(t *testing.T) {
+	rule := newTestRuleScheduling(PodAssignmentRuleSchedulingMergeStrategyReject)
+
+	pod := newTestSchedulingPod()
+	pod.Spec.Affinity = nil
+	pod.Spec.NodeSelector["disk"] = "hdd"
+	if err := rule.ApplyToPod(pod); err == nil {
+		t.Errorf("Expected a conflicting nodeSelector to be rejected")
+	}
+
+	// The requirements of a pod that is not rejected are combined with the rule
+	pod = newTestSchedulingPod()
+	if err := rule.ApplyToPod(pod); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if pod.Spec.NodeSelector["arch"] != "amd64" || pod.Spec.NodeSelector["disk"] != "ssd" {
+		t.Errorf("Unexpected nodeSelector: %v", pod.Spec.NodeSelector)
+	}
+	for _, term := range pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if len(term.MatchExpressions) != 2 || term.MatchExpressions[1].Key != "zone" {
+			t.Errorf("Unexpected node selector term: %+v", term)
+		}
+	}
+}
+
+func TestConflictsWith(t *testing.T) {
+	nodeTerm := func(reqs ...corev1.NodeSelectorRequirement) corev1.NodeSelectorTerm {
+		return corev1.NodeSelectorTerm{MatchExpressions: reqs}
+	}
+	req := func(key string, op corev1.NodeSelectorOperator, values ...string) corev1.NodeSelectorRequirement {
+		return corev1.NodeSelectorRequirement{Key: key, Operator: op, Values: values}
+	}
+	nodeAffinity := func(terms ...corev1.NodeSelectorTerm) *corev1.Affinity {
+		return &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: terms},
+		}}
+	}
+	webTerm := corev1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		TopologyKey:   "kubernetes.io/hostname",
+	}
+	dbTerm := corev1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+		TopologyKey:   "kubernetes.io/hostname",
+	}
+
+	// The rule selects disk=ssd and requires zone a
+	testCases := []struct {
+		name         string
+		nodeSelector map[string]string
+		affinity     *corev1.Affinity
+		ruleAffinity *corev1.Affinity
+		conflict     bool
+	}{
+		{name: "Nothing"},
+		{name: "OtherNodeSelector", nodeSelector: map[string]string{"arch": "amd64"}},
+		{name: "SameNodeSelector", nodeSelector: map[string]string{"disk": "ssd"}},
+		{name: "DifferentNodeSelector", nodeSelector: map[string]string{"disk": "hdd"}, conflict: true},
+		{name: "SameZonePlusMore", affinity: nodeAffinity(nodeTerm(req("zone", corev1.NodeSelectorOpIn, "a"), req("pool", corev1.NodeSelectorOpIn, "web")))},
+		{name: "OverlappingZones", affinity: nodeAffinity(nodeTerm(req("zone", corev1.NodeSelectorOpIn, "a", "b")))},
+		{name: "OtherZone", affinity: nodeAffinity(nodeTerm(req("zone", corev1.NodeSelectorOpIn, "b"))), conflict: true},
+		{name: "OneOfTheTermsMatches", affinity: nodeAffinity(nodeTerm(req("zone", corev1.NodeSelectorOpIn, "b")), nodeTerm(req("zone", corev1.NodeSelectorOpExists)))},
+		{name: "ZoneExcluded", affinity: nodeAffinity(nodeTerm(req("zone", corev1.NodeSelectorOpNotIn, "a"))), conflict: true},
+		{name: "NoZone", affinity: nodeAffinity(nodeTerm(req("zone", corev1.NodeSelectorOpDoesNotExist))), conflict: true},
+		{name: "DiskExcluded", affinity: nodeAffinity(nodeTerm(req("disk", corev1.NodeSelectorOpNotIn, "ssd"))), conflict: true},
+		{
+			name:         "NumericRange",
+			affinity:     nodeAffinity(nodeTerm(req("cpus", corev1.NodeSelectorOpGt, "4"))),
+			ruleAffinity: nodeAffinity(nodeTerm(req("cpus", corev1.NodeSelectorOpLt, "8"))),
+		},
+		{
+			name:         "EmptyNumericRange",
+			affinity:     nodeAffinity(nodeTerm(req("cpus", corev1.NodeSelectorOpGt, "4"))),
+			ruleAffinity: nodeAffinity(nodeTerm(req("cpus", corev1.NodeSelectorOpLt, "5"))),
+			conflict:     true,
+		},
+		{
+			name: "OnlyPreferences",
+			affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{
+					{Weight: 1, Preference: nodeTerm(req("zone", corev1.NodeSelectorOpIn, "b"))},
+				},
+			}},
+		},
+		{
+			name:         "DifferentPodAffinity",
+			affinity:     &corev1.Affinity{PodAffinity: &corev1.PodAffinity{RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{webTerm}}},
+			ruleAffinity: &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{dbTerm}}},
+		},
+		{
+			name:         "PodAffinityAgainstAntiAffinity",
+			affinity:     &corev1.Affinity{PodAffinity: &corev1.PodAffinity{RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{dbTerm, webTerm}}},
+			ruleAffinity: &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{webTerm}}},
+			conflict:     true,
+		},
+		{
+			name:         "PodAntiAffinityAgainstAffinity",
+			affinity:     &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{webTerm}}},
+			ruleAffinity: &corev1.Affinity{PodAffinity: &corev1.PodAffinity{RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{webTerm}}},
+			conflict:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := newTestRuleScheduling(PodAssignmentRuleSchedulingMergeStrategyReject)
+			if tc.ruleAffinity != nil {
+				rule.Affinity = tc.ruleAffinity
+			}
+			pod := &corev1.Pod{Spec: corev1.PodSpec{NodeSelector: tc.nodeSelector, Affinity: tc.affinity}}
+			if err := rule.conflictsWith(pod); (err != nil) != tc.conflict {
+				t.Errorf("Expected conflict %v, got %v", tc.conflict, err)
+			}
+		})
+	}
+}
+
+func TestGetPatchOps(t *testing.T) {
+	// A pod without any scheduling details must only get add operations
+	ops, err := newTestRuleScheduling(PodAssignmentRuleSchedulingMergeStrategyMergeAppend).GetPatchOps(&corev1.Pod{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(ops) != 3 {
+		t.Fatalf("Expected 3 patch operations, got %+v", ops)
+	}
+	for _, op := range ops {
+		if op.Op != "add" {
+			t.Errorf("Expected add operation for %s, got %s", op.Path, op.Op)
+		}
+	}
+
+	// Existing fields must be replaced and untouched fields skipped
+	pod := newTestSchedulingPod()
+	pod.Spec.Affinity = nil
+	ops, err = newTestRuleScheduling(PodAssignmentRuleSchedulingMergeStrategyReject).GetPatchOps(pod)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]string{
+		"/spec/nodeSelector": "replace",
+		"/spec/affinity":     "add",
+		"/spec/tolerations":  "replace",
+	}
+	if len(ops) != len(expected) {
+		t.Fatalf("Expected %d patch operations, got %+v", len(expected), ops)
+	}
+	for _, op := range ops {
+		if expected[op.Path] != op.Op {
+			t.Errorf("Unexpected operation %s for %s", op.Op, op.Path)
+		}
+	}
+	if pod.Spec.Affinity != nil {
+		t.Errorf("GetPatchOps modified the pod")
+	}
+
+	pod = newTestSchedulingPod()
+	pod.Spec.NodeSelector["disk"] = "hdd"
+	if _, err := newTestRuleScheduling(PodAssignmentRuleSchedulingMergeStrategyReject).GetPatchOps(pod); err == nil {
+		t.Errorf("Expected an error for a rejected pod")
+	}
+}
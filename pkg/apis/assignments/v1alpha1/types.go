@@ -33,6 +33,15 @@ type NodeAssignmentGroupSpec struct {
 	// +optional
 	TargetLabels labels.Set `json:"targetLabels,omitempty"`
 
+	// NodeSelector is optional. It is a set-based label selector that supports the In, NotIn, Exists
+	// and DoesNotExist operators. When given along with TargetLabels, nodes must match both.
+	// +optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+
+	// NodeFieldSelector is optional. It matches nodes based on attributes other than labels.
+	// +optional
+	NodeFieldSelector *NodeFieldSelector `json:"nodeFieldSelector,omitempty"`
+
 	// Assignments is the array of assignments to be applied. This list should be ordered by the user
 	// with the most important assignments first.
 	// +optional
@@ -42,6 +51,72 @@ type NodeAssignmentGroupSpec struct {
 	// with the most important assignments first.
 	// +patchStrategy=merge
 	Assignments []NodeAssignment `json:"assignments,omitempty" patchStrategy:"merge"`
+
+	// SelectionPolicy decides which nodes keep an assignment when it shrinks and which nodes are picked when it grows.
+	// Nodes that already have an assignment always keep it while the assignment still wants them. Default: Stable
+	// +optional
+	SelectionPolicy NodeSelectionPolicy `json:"selectionPolicy,omitempty"`
+
+	// RolloutStrategy limits how many nodes change assignment at once. When it is not given every change
+	// is made in a single reconcile
+	// +optional
+	RolloutStrategy *NodeAssignmentGroupRolloutStrategy `json:"rolloutStrategy,omitempty"`
+}
+
+// NodeAssignmentGroupRolloutStrategy spreads assignment changes over several waves
+// +k8s:openapi-gen=true
+type NodeAssignmentGroupRolloutStrategy struct {
+	// MaxChangesPerReconcile is the most nodes whose assignment is changed in one wave. Default: 0 (no limit)
+	// +optional
+	MaxChangesPerReconcile int `json:"maxChangesPerReconcile,omitempty"`
+
+	// MinIntervalSeconds is the least amount of time between two waves. Default: 0
+	// +optional
+	MinIntervalSeconds int64 `json:"minIntervalSeconds,omitempty"`
+}
+
+// NodeSelectionPolicy defines the order in which nodes are preferred for an assignment
+// +k8s:openapi-gen=true
+type NodeSelectionPolicy string
+
+const (
+	// NodeSelectionPolicyDefault sets the default policy to "Stable"
+	NodeSelectionPolicyDefault NodeSelectionPolicy = "Stable"
+
+	// NodeSelectionPolicyStable prefers nodes by name
+	NodeSelectionPolicyStable NodeSelectionPolicy = "Stable"
+
+	// NodeSelectionPolicyMostPods prefers the nodes running the most pods that select the assignment label or
+	// tolerate its taint
+	NodeSelectionPolicyMostPods NodeSelectionPolicy = "MostPods"
+
+	// NodeSelectionPolicyNewest prefers the most recently created nodes
+	NodeSelectionPolicyNewest NodeSelectionPolicy = "Newest"
+
+	// NodeSelectionPolicyOldest prefers the least recently created nodes
+	NodeSelectionPolicyOldest NodeSelectionPolicy = "Oldest"
+
+	// NodeSelectionPolicyUndefined means that the resource did not have this
+	// property set and the default policy will be used
+	NodeSelectionPolicyUndefined NodeSelectionPolicy = ""
+)
+
+// NodeFieldSelector matches nodes based on attributes other than labels. All given fields must match.
+// +k8s:openapi-gen=true
+type NodeFieldSelector struct {
+	// Unschedulable is optional. When given it must match spec.unschedulable of the node.
+	// +optional
+	Unschedulable *bool `json:"unschedulable,omitempty"`
+
+	// TaintsPresent is a list of taints that must all be present on the node. Taints are matched by key,
+	// the value and effect are only compared when given.
+	// +optional
+	TaintsPresent []corev1.Taint `json:"taintsPresent,omitempty"`
+
+	// TaintsAbsent is a list of taints that must not be present on the node. Taints are matched by key,
+	// the value and effect are only compared when given.
+	// +optional
+	TaintsAbsent []corev1.Taint `json:"taintsAbsent,omitempty"`
 }
 
 const (
@@ -69,6 +144,22 @@ type NodeAssignment struct {
 	// +optional
 	TaintEffect corev1.TaintEffect `json:"taintEffect,omitempty"`
 
+	// LabelKey replaces the key of the assignment label. Default: nag.assignments.kube-valet.io/<group name>
+	// +optional
+	LabelKey string `json:"labelKey,omitempty"`
+
+	// TaintKey replaces the key of the assignment taint. Default: nag.assignments.kube-valet.io/<group name>
+	// +optional
+	TaintKey string `json:"taintKey,omitempty"`
+
+	// ExtraLabels are set on every node of the assignment in addition to the assignment label, whatever the Mode
+	// +optional
+	ExtraLabels map[string]string `json:"extraLabels,omitempty"`
+
+	// ExtraTaints are set on every node of the assignment in addition to the assignment taint, whatever the Mode
+	// +optional
+	ExtraTaints []corev1.Taint `json:"extraTaints,omitempty"`
+
 	// NumDesired is the number of nodes that should be assigned to this group. Default: 0
 	// when specified along with PercentDesired, whichever request results in the most nodes is used
 	NumDesired int `json:"numDesired,omitempty"`
@@ -77,6 +168,30 @@ type NodeAssignment struct {
 	// when specified along with NumDesired, whichever request results in the most nodes is used
 	PercentDesired int `json:"percentDesired,omitempty"`
 
+	// PercentRounding controls how PercentDesired is turned into a whole number of nodes. Default: Floor
+	// +optional
+	PercentRounding NodeAssignmentPercentRounding `json:"percentRounding,omitempty"`
+
+	// MinNodes is the fewest nodes the assignment will ask for. Default: 1
+	// Set it to 0 to allow a small percentage of a small group to result in no nodes at all
+	// +optional
+	MinNodes *int `json:"minNodes,omitempty"`
+
+	// MaxNodes is the most nodes the assignment will ask for, no matter what NumDesired or PercentDesired request.
+	// There is no limit when it is not given
+	// +optional
+	MaxNodes *int `json:"maxNodes,omitempty"`
+
+	// ReassignmentPolicy controls what happens to a node before it leaves this assignment. Default: Immediate
+	// +optional
+	ReassignmentPolicy NodeAssignmentReassignmentPolicy `json:"reassignmentPolicy,omitempty"`
+
+	// DrainTimeoutSeconds is the longest time to wait for the pods of a node to be evicted when the
+	// ReassignmentPolicy is Drain. The node leaves the assignment once it has passed, even if pods are left.
+	// Default: 600
+	// +optional
+	DrainTimeoutSeconds int64 `json:"drainTimeoutSeconds,omitempty"`
+
 	// SchedulingMode determins what kind of scheduling alteration to use on the assignment
 	// do no scheduling alterations by default
 	// +optional
@@ -85,6 +200,93 @@ type NodeAssignment struct {
 	// PackLeft holds configuration options and values here are only used when the SchedulingMode is "PackLeft"
 	// +optional
 	PackLeft *PackLeftScheduling `json:"packLeft,omitempty"`
+
+	// TopologySpread balances the nodes of the assignment across the values of a node label such as the zone
+	// +optional
+	TopologySpread *NodeAssignmentTopologySpread `json:"topologySpread,omitempty"`
+
+	// Requirements limits the assignment to nodes that are able to host it. Nodes that stop meeting the
+	// requirements lose the assignment
+	// +optional
+	Requirements *NodeAssignmentRequirements `json:"requirements,omitempty"`
+}
+
+// NodeAssignmentPercentRounding defines how a percentage of nodes is rounded to a whole number
+// +k8s:openapi-gen=true
+type NodeAssignmentPercentRounding string
+
+const (
+	// NodeAssignmentPercentRoundingDefault sets the default rounding to "Floor"
+	NodeAssignmentPercentRoundingDefault NodeAssignmentPercentRounding = "Floor"
+
+	// NodeAssignmentPercentRoundingFloor rounds down
+	NodeAssignmentPercentRoundingFloor NodeAssignmentPercentRounding = "Floor"
+
+	// NodeAssignmentPercentRoundingCeil rounds up
+	NodeAssignmentPercentRoundingCeil NodeAssignmentPercentRounding = "Ceil"
+
+	// NodeAssignmentPercentRoundingNearest rounds to the nearest whole node. Halves are rounded up
+	NodeAssignmentPercentRoundingNearest NodeAssignmentPercentRounding = "Nearest"
+
+	// NodeAssignmentPercentRoundingUndefined means that the resource did not have this
+	// property set and the default rounding will be used
+	NodeAssignmentPercentRoundingUndefined NodeAssignmentPercentRounding = ""
+)
+
+// NodeAssignmentReassignmentPolicy defines how a node is prepared before it leaves an assignment
+// +k8s:openapi-gen=true
+type NodeAssignmentReassignmentPolicy string
+
+const (
+	// NodeAssignmentReassignmentPolicyDefault sets the default policy to "Immediate"
+	NodeAssignmentReassignmentPolicyDefault NodeAssignmentReassignmentPolicy = "Immediate"
+
+	// NodeAssignmentReassignmentPolicyImmediate moves the node right away
+	NodeAssignmentReassignmentPolicyImmediate NodeAssignmentReassignmentPolicy = "Immediate"
+
+	// NodeAssignmentReassignmentPolicyDrain cordons the node and evicts its pods before it is moved
+	NodeAssignmentReassignmentPolicyDrain NodeAssignmentReassignmentPolicy = "Drain"
+
+	// NodeAssignmentReassignmentPolicyUndefined means that the resource did not have this
+	// property set and the default policy will be used
+	NodeAssignmentReassignmentPolicyUndefined NodeAssignmentReassignmentPolicy = ""
+)
+
+// NodeAssignmentRequirements describes the nodes that are eligible for an assignment. All given fields must match.
+// +k8s:openapi-gen=true
+type NodeAssignmentRequirements struct {
+	// MinAllocatable is the minimum amount of each allocatable resource a node must have. Ex: {"memory": "64Gi"}
+	// +optional
+	MinAllocatable corev1.ResourceList `json:"minAllocatable,omitempty"`
+
+	// NodeSelector is a label selector the node must match
+	// +optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+
+	// ExcludeNotReady excludes nodes whose Ready condition is not True
+	// +optional
+	ExcludeNotReady bool `json:"excludeNotReady,omitempty"`
+
+	// ExcludeUnschedulable excludes cordoned nodes
+	// +optional
+	ExcludeUnschedulable bool `json:"excludeUnschedulable,omitempty"`
+
+	// MinAgeSeconds excludes nodes that were created less than this many seconds ago
+	// +optional
+	MinAgeSeconds int64 `json:"minAgeSeconds,omitempty"`
+}
+
+// NodeAssignmentTopologySpread holds configuration for spreading an assignment across topology domains
+// +k8s:openapi-gen=true
+type NodeAssignmentTopologySpread struct {
+	// TopologyKey is the node label whose values are the domains to spread across. Ex: topology.kubernetes.io/zone
+	// Nodes without the label are never given the assignment
+	TopologyKey string `json:"topologyKey"`
+
+	// MaxSkew is the largest allowed difference between the number of assigned nodes in any two domains.
+	// Domains without any nodes left to assign are not held back by it. Default: 1
+	// +optional
+	MaxSkew int `json:"maxSkew,omitempty"`
 }
 
 // PackLeftScheduling holds configuration for PackLeft assignments
@@ -93,6 +295,17 @@ type PackLeftScheduling struct {
 	// FullPercent defines percent of the Metric that must be used for a node to be considered "Full"
 	FullPercent *int `json:"fullPercent,omitempty"`
 
+	// FullExitPercent is the percent of the Metric a node in the "Use" state has to drop to before it is no longer
+	// considered "Full". Keeping it below FullPercent stops nodes that hover around FullPercent from flapping. When not set it
+	// is FullPercent
+	// +optional
+	FullExitPercent *int `json:"fullExitPercent,omitempty"`
+
+	// MinDwellSeconds is the least time a node keeps its state before it can be moved to a more restrictive state.
+	// Moves to a less restrictive state are never held back, so room for new pods is made right away. Default: 0
+	// +optional
+	MinDwellSeconds int64 `json:"minDwellSeconds,omitempty"`
+
 	// NumAvoid indiciates the number of nodes to be set to "Avoid" for the given assignment. Assignments with few nodes should be fine
 	// with a buffer of 1, But very large cluster may be better off with a larger number. Default: 1
 	NumAvoid int `json:"numAvoid,omitempty"`
@@ -100,8 +313,47 @@ type PackLeftScheduling struct {
 	// PercentAvoid indiciates a percentage of nodes to be set to "Avoid" for the given assignment
 	// when specified along with NumAvoid, whichever request results in the most nodes is used
 	PercentAvoid *int `json:"percentAvoid,omitempty"`
+
+	// Resources are the node resources that decide how full a node is. Default: cpu and memory
+	// +optional
+	Resources []PackLeftResource `json:"resources,omitempty"`
+
+	// Aggregation decides how the percent full of each resource is combined into the percent full of the node. Default: Max
+	// +optional
+	Aggregation PackLeftAggregation `json:"aggregation,omitempty"`
 }
 
+// PackLeftResource is a node resource that counts towards how full a node is
+// +k8s:openapi-gen=true
+type PackLeftResource struct {
+	// Name of the resource. Requests of cpu, memory, ephemeral-storage and extended resources are compared to the
+	// allocatable amount of the node. "pods" compares the number of pods to the allocatable pods of the node
+	Name corev1.ResourceName `json:"name"`
+
+	// Weight of the resource in the WeightedAverage aggregation. 0 leaves the resource out of the average. Default: 1
+	// +optional
+	Weight *int `json:"weight,omitempty"`
+}
+
+// PackLeftAggregation defines how the percent full of several resources is combined
+// +k8s:openapi-gen=true
+type PackLeftAggregation string
+
+const (
+	// PackLeftAggregationDefault sets the default aggregation to "Max"
+	PackLeftAggregationDefault PackLeftAggregation = "Max"
+
+	// PackLeftAggregationMax uses the percent full of the fullest resource
+	PackLeftAggregationMax PackLeftAggregation = "Max"
+
+	// PackLeftAggregationWeightedAverage uses the weighted average of the percent full of all resources
+	PackLeftAggregationWeightedAverage PackLeftAggregation = "WeightedAverage"
+
+	// PackLeftAggregationUndefined means that the resource did not have this
+	// property set and the default aggregation will be used
+	PackLeftAggregationUndefined PackLeftAggregation = ""
+)
+
 // NodeAssignmentMode defines the operation mode of the rule
 // +k8s:openapi-gen=true
 type NodeAssignmentMode string
@@ -116,6 +368,9 @@ const (
 	// NodeAssignmentModeLabelAndTaint tells the system to apply both labels and taints for the rule
 	NodeAssignmentModeLabelAndTaint NodeAssignmentMode = "LabelAndTaint"
 
+	// NodeAssignmentModeTaintOnly tells the system to only apply taints to the node
+	NodeAssignmentModeTaintOnly NodeAssignmentMode = "TaintOnly"
+
 	// NodeAssignmentModeUndefined means that the resource did not have this
 	// property set and the default behavior will be used
 	NodeAssignmentModeUndefined NodeAssignmentMode = ""
@@ -140,19 +395,49 @@ const (
 // NodeAssignmentGroupStatus represents the current status of the group.
 // +k8s:openapi-gen=true
 type NodeAssignmentGroupStatus struct {
+	// ObservedGeneration is the most recent generation of the group that was reconciled by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
 	// NumMatched represents the number of nodes that matched the targetlabels
 	NumMatched int64 `json:"numMatched,omitempty"`
 
-	// // NumSatisfied represents the total number of assignments that have been satisifed.
-	// // If this is less than NumMatched then there weren't enough maching nodes to
-	// // fufill all the assignments
-	// NumSatisfied int64 `json:"numSatisfied"`
+	// NumSatisfied represents the total number of assignments that have been satisifed.
+	// If this is less than the number of assignments then there weren't enough maching nodes to
+	// fufill all the assignments
+	NumSatisfied int64 `json:"numSatisfied"`
 
-	// // State reports the overall health of the group
-	// State NodeAssignmentGroupState `json:"state"`
+	// State reports the overall health of the group
+	// +optional
+	State NodeAssignmentGroupState `json:"state,omitempty"`
+
+	// AssignmentStates reports the satisfaction for each assignment
+	// +optional
+	AssignmentStates []AssignmentStates `json:"assignmentStates,omitempty"`
+
+	// Rollout reports the progress of the rollout when the group has a RolloutStrategy
+	// +optional
+	Rollout *NodeAssignmentGroupRolloutStatus `json:"rollout,omitempty"`
 
-	// // AssignmentStates reports the satisfaction for each assignment
-	// AssignmentStates NodeAssignmentGroupState `json:"state"`
+	// Conditions represent the latest available observations of the group's state
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []NodeAssignmentGroupCondition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// NodeAssignmentGroupRolloutStatus reports the progress of a rollout
+// +k8s:openapi-gen=true
+type NodeAssignmentGroupRolloutStatus struct {
+	// NumPending is the number of nodes whose assignment still has to be changed
+	NumPending int64 `json:"numPending"`
+
+	// NumChanged is the number of nodes whose assignment was changed in the last wave
+	NumChanged int64 `json:"numChanged"`
+
+	// LastWaveTime is when the last wave of changes was made
+	// +optional
+	LastWaveTime *metav1.Time `json:"lastWaveTime,omitempty"`
 }
 
 // NodeAssignmentGroupState reports the overall health of the group
@@ -179,10 +464,75 @@ type AssignmentStates struct {
 	// Name is the name of the assignment
 	Name string `json:"name,omitempty"`
 
-	// NumSatisfied represents the number of nodes that were assigned
-	NumAssigned int64 `json:"numAssigned,omitempty"`
+	// NumDesired represents the number of nodes that the assignment requested.
+	// For the default assignment this is the number of matched nodes left over after all other assignments
+	NumDesired int64 `json:"numDesired"`
+
+	// NumAssigned represents the number of nodes that were assigned
+	NumAssigned int64 `json:"numAssigned"`
+
+	// MinNodes is the lower bound that was applied to NumDesired
+	// +optional
+	MinNodes int64 `json:"minNodes,omitempty"`
+
+	// MaxNodes is the upper bound that was applied to NumDesired. It is not set when there is no upper bound
+	// +optional
+	MaxNodes *int64 `json:"maxNodes,omitempty"`
+}
+
+// NodeAssignmentGroupConditionType is a valid value for NodeAssignmentGroupCondition.Type
+// +k8s:openapi-gen=true
+type NodeAssignmentGroupConditionType string
+
+const (
+	// NodeAssignmentGroupConditionReconciled is true when the last reconcile of the group completed without errors
+	NodeAssignmentGroupConditionReconciled NodeAssignmentGroupConditionType = "Reconciled"
+
+	// NodeAssignmentGroupConditionSatisfied is true when every assignment in the group has all of the nodes it desires
+	NodeAssignmentGroupConditionSatisfied NodeAssignmentGroupConditionType = "Satisfied"
+)
+
+// NodeAssignmentGroupCondition describes the state of a group at a certain point.
+// The fields follow the upstream condition conventions so that generic tooling can read them.
+// +k8s:openapi-gen=true
+type NodeAssignmentGroupCondition struct {
+	// Type of the condition
+	Type NodeAssignmentGroupConditionType `json:"type"`
+
+	// Status of the condition, one of True, False, Unknown
+	Status metav1.ConditionStatus `json:"status"`
+
+	// ObservedGeneration is the generation of the group that the condition was set for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LastTransitionTime is the last time the condition changed from one status to another
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// Reason is a brief CamelCase reason for the condition's last transition
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human readable message indicating details about the last transition
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
+const (
+	// NodeAssignmentGroupReasonReconciled is used when the group was reconciled without errors
+	NodeAssignmentGroupReasonReconciled = "Reconciled"
+
+	// NodeAssignmentGroupReasonReconcileError is used when the controller failed to reconcile the group
+	NodeAssignmentGroupReasonReconcileError = "ReconcileError"
+
+	// NodeAssignmentGroupReasonAllAssignmentsSatisfied is used when every assignment has all of its desired nodes
+	NodeAssignmentGroupReasonAllAssignmentsSatisfied = "AllAssignmentsSatisfied"
+
+	// NodeAssignmentGroupReasonInsufficientNodes is used when there were not enough matched nodes for all assignments
+	NodeAssignmentGroupReasonInsufficientNodes = "InsufficientNodes"
+)
+
 // generation tags. The empty line after is IMPORTANT!
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -222,6 +572,21 @@ type PodAssignmentRuleSpec struct {
 	// +optional
 	TargetLabels labels.Set `json:"targetLabels,omitempty"`
 
+	// PodSelector is optional. It is a set-based label selector that supports the In, NotIn, Exists
+	// and DoesNotExist operators. When given along with TargetLabels, pods must match both.
+	// +optional
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+
+	// Priority orders the rules that match a pod. Rules are applied from the lowest to the highest priority
+	// so that higher priority rules take precedence. Ties are applied ClusterPodAssignmentRules first and then
+	// by name. Default: 0
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// StopProcessing prevents any matching rule that would be applied before this one from being applied
+	// +optional
+	StopProcessing bool `json:"stopProcessing,omitempty"`
+
 	// Scheduling defines the scheduling objects to be applied to the pod
 	Scheduling PodAssignmentRuleScheduling `json:"scheduling"`
 }
@@ -261,6 +626,19 @@ const (
 	// any scheduling details in the pod with the details in the rule
 	PodAssignmentRuleSchedulingMergeStrategyOverwriteAll PodAssignmentRuleSchedulingMergeStrategy = "OverwriteAll"
 
+	// PodAssignmentRuleSchedulingMergeStrategyMergeAppend tells the system to merge the details in the rule
+	// with those in the pod. NodeSelector keys are merged, tolerations are unioned, and affinity terms are
+	// appended. Required node affinity terms from the rule are added to every existing term.
+	PodAssignmentRuleSchedulingMergeStrategyMergeAppend PodAssignmentRuleSchedulingMergeStrategy = "MergeAppend"
+
+	// PodAssignmentRuleSchedulingMergeStrategyKeepExisting tells the system to only fill in scheduling
+	// details that are empty in the pod
+	PodAssignmentRuleSchedulingMergeStrategyKeepExisting PodAssignmentRuleSchedulingMergeStrategy = "KeepExisting"
+
+	// PodAssignmentRuleSchedulingMergeStrategyReject tells the system to deny pods whose required scheduling
+	// details contradict the rule. Preferences never conflict. Pods without conflicts are merged like MergeAppend.
+	PodAssignmentRuleSchedulingMergeStrategyReject PodAssignmentRuleSchedulingMergeStrategy = "Reject"
+
 	// PodAssignmentRuleSchedulingMergeStrategyUndefined means that the document did not
 	// include a strategy and the default will be used.
 	PodAssignmentRuleSchedulingMergeStrategyUndefined PodAssignmentRuleSchedulingMergeStrategy = ""
@@ -293,7 +671,18 @@ type ClusterPodAssignmentRule struct {
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
 	// Spec defines the behavior of the rule
-	Spec PodAssignmentRuleSpec `json:"spec"`
+	Spec ClusterPodAssignmentRuleSpec `json:"spec"`
+}
+
+// ClusterPodAssignmentRuleSpec defines the behavior of the ClusterPodAssignmentRule
+// +k8s:openapi-gen=true
+type ClusterPodAssignmentRuleSpec struct {
+	PodAssignmentRuleSpec `json:",inline"`
+
+	// NamespaceSelector is optional. It limits the rule to pods in namespaces whose labels match.
+	// When not given, the rule will match pods in all namespaces.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
 }
 
 // generation tags. The empty line after is IMPORTANT!
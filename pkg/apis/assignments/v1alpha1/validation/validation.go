@@ -0,0 +1,361 @@
+package validation
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	assignmentsv1alpha1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1alpha1"
+)
+
+var (
+	supportedModes = sets.NewString(
+		string(assignmentsv1alpha1.NodeAssignmentModeUndefined),
+		string(assignmentsv1alpha1.NodeAssignmentModeLabelOnly),
+		string(assignmentsv1alpha1.NodeAssignmentModeLabelAndTaint),
+		string(assignmentsv1alpha1.NodeAssignmentModeTaintOnly),
+	)
+
+	supportedTaintEffects = sets.NewString(
+		string(assignmentsv1alpha1.NodeAssignmentTaintEffectNotSpecified),
+		string(corev1.TaintEffectNoSchedule),
+		string(corev1.TaintEffectPreferNoSchedule),
+		string(corev1.TaintEffectNoExecute),
+	)
+
+	supportedSchedulingModes = sets.NewString(
+		assignmentsv1alpha1.NodeAssignmentSchedulingModeUndefined,
+		assignmentsv1alpha1.NodeAssignmentSchedulingModePackLeft,
+	)
+
+	supportedPackLeftAggregations = sets.NewString(
+		string(assignmentsv1alpha1.PackLeftAggregationUndefined),
+		string(assignmentsv1alpha1.PackLeftAggregationMax),
+		string(assignmentsv1alpha1.PackLeftAggregationWeightedAverage),
+	)
+
+	supportedSelectionPolicies = sets.NewString(
+		string(assignmentsv1alpha1.NodeSelectionPolicyUndefined),
+		string(assignmentsv1alpha1.NodeSelectionPolicyStable),
+		string(assignmentsv1alpha1.NodeSelectionPolicyMostPods),
+		string(assignmentsv1alpha1.NodeSelectionPolicyNewest),
+		string(assignmentsv1alpha1.NodeSelectionPolicyOldest),
+	)
+
+	supportedPercentRoundings = sets.NewString(
+		string(assignmentsv1alpha1.NodeAssignmentPercentRoundingUndefined),
+		string(assignmentsv1alpha1.NodeAssignmentPercentRoundingFloor),
+		string(assignmentsv1alpha1.NodeAssignmentPercentRoundingCeil),
+		string(assignmentsv1alpha1.NodeAssignmentPercentRoundingNearest),
+	)
+
+	supportedReassignmentPolicies = sets.NewString(
+		string(assignmentsv1alpha1.NodeAssignmentReassignmentPolicyUndefined),
+		string(assignmentsv1alpha1.NodeAssignmentReassignmentPolicyImmediate),
+		string(assignmentsv1alpha1.NodeAssignmentReassignmentPolicyDrain),
+	)
+
+	supportedMergeStrategies = sets.NewString(
+		string(assignmentsv1alpha1.PodAssignmentRuleSchedulingMergeStrategyUndefined),
+		string(assignmentsv1alpha1.PodAssignmentRuleSchedulingMergeStrategyOverwriteAll),
+		string(assignmentsv1alpha1.PodAssignmentRuleSchedulingMergeStrategyMergeAppend),
+		string(assignmentsv1alpha1.PodAssignmentRuleSchedulingMergeStrategyKeepExisting),
+		string(assignmentsv1alpha1.PodAssignmentRuleSchedulingMergeStrategyReject),
+	)
+
+	supportedTolerationOperators = sets.NewString(
+		"",
+		string(corev1.TolerationOpExists),
+		string(corev1.TolerationOpEqual),
+	)
+)
+
+// ValidateNodeAssignmentGroup validates a NodeAssignmentGroup and returns a list of errors
+func ValidateNodeAssignmentGroup(nag *assignmentsv1alpha1.NodeAssignmentGroup) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	// The group name is used as the name part of the assignment label and taint keys
+	for _, msg := range validation.IsQualifiedName("nag." + assignmentsv1alpha1.GroupName + "/" + nag.GetName()) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "name"), nag.GetName(), msg))
+	}
+
+	allErrs = append(allErrs, ValidateNodeAssignmentGroupSpec(&nag.Spec, field.NewPath("spec"))...)
+	return allErrs
+}
+
+// ValidateNodeAssignmentGroupSpec validates the spec of a NodeAssignmentGroup
+func ValidateNodeAssignmentGroupSpec(spec *assignmentsv1alpha1.NodeAssignmentGroupSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, metav1validation.ValidateLabels(spec.TargetLabels, fldPath.Child("targetLabels"))...)
+	allErrs = append(allErrs, metav1validation.ValidateLabelSelector(spec.NodeSelector, fldPath.Child("nodeSelector"))...)
+
+	if spec.NodeFieldSelector != nil {
+		fsPath := fldPath.Child("nodeFieldSelector")
+		for i, taint := range spec.NodeFieldSelector.TaintsPresent {
+			allErrs = append(allErrs, validateTaint(&taint, fsPath.Child("taintsPresent").Index(i))...)
+		}
+		for i, taint := range spec.NodeFieldSelector.TaintsAbsent {
+			allErrs = append(allErrs, validateTaint(&taint, fsPath.Child("taintsAbsent").Index(i))...)
+		}
+	}
+
+	if !supportedSelectionPolicies.Has(string(spec.SelectionPolicy)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("selectionPolicy"), spec.SelectionPolicy, supportedSelectionPolicies.List()))
+	}
+
+	if rs := spec.RolloutStrategy; rs != nil {
+		rsPath := fldPath.Child("rolloutStrategy")
+		if rs.MaxChangesPerReconcile < 0 {
+			allErrs = append(allErrs, field.Invalid(rsPath.Child("maxChangesPerReconcile"), rs.MaxChangesPerReconcile, "must be greater than or equal to 0"))
+		}
+		if rs.MinIntervalSeconds < 0 {
+			allErrs = append(allErrs, field.Invalid(rsPath.Child("minIntervalSeconds"), rs.MinIntervalSeconds, "must be greater than or equal to 0"))
+		}
+	}
+
+	names := sets.NewString()
+	for i := range spec.Assignments {
+		idxPath := fldPath.Child("assignments").Index(i)
+		allErrs = append(allErrs, ValidateNodeAssignment(&spec.Assignments[i], idxPath)...)
+
+		if names.Has(spec.Assignments[i].Name) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), spec.Assignments[i].Name))
+		}
+		names.Insert(spec.Assignments[i].Name)
+	}
+
+	if spec.DefaultAssignment != nil {
+		defPath := fldPath.Child("defaultAssignment")
+		allErrs = append(allErrs, ValidateNodeAssignment(spec.DefaultAssignment, defPath)...)
+
+		if names.Has(spec.DefaultAssignment.Name) {
+			allErrs = append(allErrs, field.Duplicate(defPath.Child("name"), spec.DefaultAssignment.Name))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateNodeAssignment validates a single assignment of a NodeAssignmentGroup
+func ValidateNodeAssignment(na *assignmentsv1alpha1.NodeAssignment, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	// The name is used as the value of the assignment label and taint
+	if na.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), ""))
+	}
+	for _, msg := range validation.IsValidLabelValue(na.Name) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), na.Name, msg))
+	}
+
+	if !supportedModes.Has(string(na.Mode)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("mode"), na.Mode, supportedModes.List()))
+	}
+	if !supportedTaintEffects.Has(string(na.TaintEffect)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("taintEffect"), na.TaintEffect, supportedTaintEffects.List()))
+	}
+	if na.LabelKey != "" {
+		allErrs = append(allErrs, validateAssignmentKey(na.LabelKey, fldPath.Child("labelKey"))...)
+	}
+	if na.TaintKey != "" {
+		allErrs = append(allErrs, validateAssignmentKey(na.TaintKey, fldPath.Child("taintKey"))...)
+	}
+	allErrs = append(allErrs, metav1validation.ValidateLabels(na.ExtraLabels, fldPath.Child("extraLabels"))...)
+	for k := range na.ExtraLabels {
+		allErrs = append(allErrs, validateReservedKey(k, fldPath.Child("extraLabels").Key(k))...)
+	}
+	for i, taint := range na.ExtraTaints {
+		tPath := fldPath.Child("extraTaints").Index(i)
+		allErrs = append(allErrs, validateAssignmentKey(taint.Key, tPath.Child("key"))...)
+		for _, msg := range validation.IsValidLabelValue(taint.Value) {
+			allErrs = append(allErrs, field.Invalid(tPath.Child("value"), taint.Value, msg))
+		}
+		if taint.Effect == "" {
+			allErrs = append(allErrs, field.Required(tPath.Child("effect"), ""))
+		} else if !supportedTaintEffects.Has(string(taint.Effect)) {
+			allErrs = append(allErrs, field.NotSupported(tPath.Child("effect"), taint.Effect, supportedTaintEffects.List()))
+		}
+	}
+
+	if !supportedSchedulingModes.Has(string(na.SchedulingMode)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("schedulingMode"), na.SchedulingMode, supportedSchedulingModes.List()))
+	}
+
+	if na.NumDesired < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("numDesired"), na.NumDesired, "must be greater than or equal to 0"))
+	}
+	allErrs = append(allErrs, validatePercent(na.PercentDesired, fldPath.Child("percentDesired"))...)
+	if !supportedPercentRoundings.Has(string(na.PercentRounding)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("percentRounding"), na.PercentRounding, supportedPercentRoundings.List()))
+	}
+	if na.MinNodes != nil && *na.MinNodes < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minNodes"), *na.MinNodes, "must be greater than or equal to 0"))
+	}
+	if na.MaxNodes != nil {
+		if *na.MaxNodes < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("maxNodes"), *na.MaxNodes, "must be greater than or equal to 0"))
+		} else if *na.MaxNodes < na.GetMinNodes() {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("maxNodes"), *na.MaxNodes, "must be greater than or equal to minNodes"))
+		}
+	}
+
+	if !supportedReassignmentPolicies.Has(string(na.ReassignmentPolicy)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("reassignmentPolicy"), na.ReassignmentPolicy, supportedReassignmentPolicies.List()))
+	}
+	if na.DrainTimeoutSeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("drainTimeoutSeconds"), na.DrainTimeoutSeconds, "must be greater than or equal to 0"))
+	}
+
+	if na.PackLeft != nil {
+		plPath := fldPath.Child("packLeft")
+		if na.PackLeft.FullPercent != nil {
+			allErrs = append(allErrs, validatePercent(*na.PackLeft.FullPercent, plPath.Child("fullPercent"))...)
+		}
+		if na.PackLeft.FullExitPercent != nil {
+			fullPercent := assignmentsv1alpha1.PackLeftFullPercentDefault
+			if na.PackLeft.FullPercent != nil {
+				fullPercent = *na.PackLeft.FullPercent
+			}
+			if errs := validatePercent(*na.PackLeft.FullExitPercent, plPath.Child("fullExitPercent")); len(errs) != 0 {
+				allErrs = append(allErrs, errs...)
+			} else if *na.PackLeft.FullExitPercent > fullPercent {
+				allErrs = append(allErrs, field.Invalid(plPath.Child("fullExitPercent"), *na.PackLeft.FullExitPercent, "must be less than or equal to fullPercent"))
+			}
+		}
+		if na.PackLeft.MinDwellSeconds < 0 {
+			allErrs = append(allErrs, field.Invalid(plPath.Child("minDwellSeconds"), na.PackLeft.MinDwellSeconds, "must be greater than or equal to 0"))
+		}
+		if na.PackLeft.NumAvoid < 0 {
+			allErrs = append(allErrs, field.Invalid(plPath.Child("numAvoid"), na.PackLeft.NumAvoid, "must be greater than or equal to 0"))
+		}
+		if na.PackLeft.PercentAvoid != nil {
+			allErrs = append(allErrs, validatePercent(*na.PackLeft.PercentAvoid, plPath.Child("percentAvoid"))...)
+		}
+		names := sets.NewString()
+		for i, r := range na.PackLeft.Resources {
+			rPath := plPath.Child("resources").Index(i)
+			if r.Name == "" {
+				allErrs = append(allErrs, field.Required(rPath.Child("name"), ""))
+			} else {
+				for _, msg := range validation.IsQualifiedName(string(r.Name)) {
+					allErrs = append(allErrs, field.Invalid(rPath.Child("name"), r.Name, msg))
+				}
+			}
+			if names.Has(string(r.Name)) {
+				allErrs = append(allErrs, field.Duplicate(rPath.Child("name"), r.Name))
+			}
+			names.Insert(string(r.Name))
+			if r.Weight != nil && *r.Weight < 0 {
+				allErrs = append(allErrs, field.Invalid(rPath.Child("weight"), *r.Weight, "must be greater than or equal to 0"))
+			}
+		}
+		if !supportedPackLeftAggregations.Has(string(na.PackLeft.Aggregation)) {
+			allErrs = append(allErrs, field.NotSupported(plPath.Child("aggregation"), na.PackLeft.Aggregation, supportedPackLeftAggregations.List()))
+		}
+	}
+
+	if na.TopologySpread != nil {
+		tsPath := fldPath.Child("topologySpread")
+		if na.TopologySpread.TopologyKey == "" {
+			allErrs = append(allErrs, field.Required(tsPath.Child("topologyKey"), ""))
+		} else {
+			allErrs = append(allErrs, metav1validation.ValidateLabelName(na.TopologySpread.TopologyKey, tsPath.Child("topologyKey"))...)
+		}
+		if na.TopologySpread.MaxSkew < 0 {
+			allErrs = append(allErrs, field.Invalid(tsPath.Child("maxSkew"), na.TopologySpread.MaxSkew, "must be greater than or equal to 0"))
+		}
+	}
+
+	if na.Requirements != nil {
+		reqPath := fldPath.Child("requirements")
+		for name, quantity := range na.Requirements.MinAllocatable {
+			if quantity.Sign() < 0 {
+				allErrs = append(allErrs, field.Invalid(reqPath.Child("minAllocatable").Key(string(name)), quantity.String(), "must be greater than or equal to 0"))
+			}
+		}
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(na.Requirements.NodeSelector, reqPath.Child("nodeSelector"))...)
+		if na.Requirements.MinAgeSeconds < 0 {
+			allErrs = append(allErrs, field.Invalid(reqPath.Child("minAgeSeconds"), na.Requirements.MinAgeSeconds, "must be greater than or equal to 0"))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidatePodAssignmentRule validates a PodAssignmentRule and returns a list of errors
+func ValidatePodAssignmentRule(par *assignmentsv1alpha1.PodAssignmentRule) field.ErrorList {
+	return ValidatePodAssignmentRuleSpec(&par.Spec, field.NewPath("spec"))
+}
+
+// ValidateClusterPodAssignmentRule validates a ClusterPodAssignmentRule and returns a list of errors
+func ValidateClusterPodAssignmentRule(cpar *assignmentsv1alpha1.ClusterPodAssignmentRule) field.ErrorList {
+	fldPath := field.NewPath("spec")
+	allErrs := ValidatePodAssignmentRuleSpec(&cpar.Spec.PodAssignmentRuleSpec, fldPath)
+	allErrs = append(allErrs, metav1validation.ValidateLabelSelector(cpar.Spec.NamespaceSelector, fldPath.Child("namespaceSelector"))...)
+	return allErrs
+}
+
+// ValidatePodAssignmentRuleSpec validates the spec shared by PodAssignmentRules and ClusterPodAssignmentRules
+func ValidatePodAssignmentRuleSpec(spec *assignmentsv1alpha1.PodAssignmentRuleSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, metav1validation.ValidateLabels(spec.TargetLabels, fldPath.Child("targetLabels"))...)
+	allErrs = append(allErrs, metav1validation.ValidateLabelSelector(spec.PodSelector, fldPath.Child("podSelector"))...)
+
+	schedPath := fldPath.Child("scheduling")
+	if !supportedMergeStrategies.Has(string(spec.Scheduling.MergeStrategy)) {
+		allErrs = append(allErrs, field.NotSupported(schedPath.Child("mergeStrategy"), spec.Scheduling.MergeStrategy, supportedMergeStrategies.List()))
+	}
+	allErrs = append(allErrs, metav1validation.ValidateLabels(spec.Scheduling.NodeSelector, schedPath.Child("nodeSelector"))...)
+
+	for i, toleration := range spec.Scheduling.Tolerations {
+		idxPath := schedPath.Child("tolerations").Index(i)
+		if !supportedTolerationOperators.Has(string(toleration.Operator)) {
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("operator"), toleration.Operator, supportedTolerationOperators.List()))
+		}
+		if toleration.Operator == corev1.TolerationOpExists && toleration.Value != "" {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("value"), toleration.Value, "must be empty when operator is Exists"))
+		}
+		if !supportedTaintEffects.Has(string(toleration.Effect)) {
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("effect"), toleration.Effect, supportedTaintEffects.List()))
+		}
+	}
+
+	return allErrs
+}
+
+func validateTaint(taint *corev1.Taint, fldPath *field.Path) field.ErrorList {
+	allErrs := metav1validation.ValidateLabelName(taint.Key, fldPath.Child("key"))
+	if !supportedTaintEffects.Has(string(taint.Effect)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("effect"), taint.Effect, supportedTaintEffects.List()))
+	}
+	return allErrs
+}
+
+// validateAssignmentKey validates a key of a label or taint set by an assignment
+func validateAssignmentKey(key string, fldPath *field.Path) field.ErrorList {
+	allErrs := metav1validation.ValidateLabelName(key, fldPath)
+	return append(allErrs, validateReservedKey(key, fldPath)...)
+}
+
+// validateReservedKey rejects keys in the kube-valet domain. Those are managed by kube-valet itself and an
+// assignment setting them could take the labels or taints of another group.
+func validateReservedKey(key string, fldPath *field.Path) field.ErrorList {
+	parts := strings.SplitN(key, "/", 2)
+	if len(parts) == 2 && (parts[0] == assignmentsv1alpha1.Domain || strings.HasSuffix(parts[0], "."+assignmentsv1alpha1.Domain)) {
+		return field.ErrorList{field.Invalid(fldPath, key, "keys in the "+assignmentsv1alpha1.Domain+" domain are reserved")}
+	}
+	return nil
+}
+
+func validatePercent(percent int, fldPath *field.Path) field.ErrorList {
+	if percent < 0 || percent > 100 {
+		return field.ErrorList{field.Invalid(fldPath, percent, "must be between 0 and 100")}
+	}
+	return nil
+}
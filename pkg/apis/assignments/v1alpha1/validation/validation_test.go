@@ -0,0 +1,249 @@
+package validation
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	assignmentsv1alpha1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1alpha1"
+)
+
+func newValidNag() *assignmentsv1alpha1.NodeAssignmentGroup {
+	fullPercent := 80
+	return &assignmentsv1alpha1.NodeAssignmentGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "testnag"},
+		Spec: assignmentsv1alpha1.NodeAssignmentGroupSpec{
+			TargetLabels: map[string]string{"pool": "web"},
+			Assignments: []assignmentsv1alpha1.NodeAssignment{
+				{Name: "first", NumDesired: 2, Mode: assignmentsv1alpha1.NodeAssignmentModeLabelAndTaint, TaintEffect: corev1.TaintEffectNoExecute},
+				{
+					Name:           "second",
+					PercentDesired: 50,
+					SchedulingMode: assignmentsv1alpha1.NodeAssignmentSchedulingModePackLeft,
+					PackLeft:       &assignmentsv1alpha1.PackLeftScheduling{FullPercent: &fullPercent, NumAvoid: 1},
+				},
+			},
+			DefaultAssignment: &assignmentsv1alpha1.NodeAssignment{Name: "rest"},
+		},
+	}
+}
+
+func TestValidateNodeAssignmentGroup(t *testing.T) {
+	testCases := []struct {
+		name   string
+		mutate func(nag *assignmentsv1alpha1.NodeAssignmentGroup)
+		fields []string
+	}{
+		{name: "Valid", mutate: func(nag *assignmentsv1alpha1.NodeAssignmentGroup) {}},
+		{
+			name:   "InvalidName",
+			mutate: func(nag *assignmentsv1alpha1.NodeAssignmentGroup) { nag.Name = "bad/name" },
+			fields: []string{"metadata.name"},
+		},
+		{
+			name:   "DuplicateAssignment",
+			mutate: func(nag *assignmentsv1alpha1.NodeAssignmentGroup) { nag.Spec.Assignments[1].Name = "first" },
+			fields: []string{"spec.assignments[1].name"},
+		},
+		{
+			name:   "DefaultSharesName",
+			mutate: func(nag *assignmentsv1alpha1.NodeAssignmentGroup) { nag.Spec.DefaultAssignment.Name = "second" },
+			fields: []string{"spec.defaultAssignment.name"},
+		},
+		{
+			name:   "MissingName",
+			mutate: func(nag *assignmentsv1alpha1.NodeAssignmentGroup) { nag.Spec.Assignments[0].Name = "" },
+			fields: []string{"spec.assignments[0].name"},
+		},
+		{
+			name:   "PercentDesiredOver100",
+			mutate: func(nag *assignmentsv1alpha1.NodeAssignmentGroup) { nag.Spec.Assignments[1].PercentDesired = 101 },
+			fields: []string{"spec.assignments[1].percentDesired"},
+		},
+		{
+			name: "RolloutStrategy",
+			mutate: func(nag *assignmentsv1alpha1.NodeAssignmentGroup) {
+				nag.Spec.RolloutStrategy = &assignmentsv1alpha1.NodeAssignmentGroupRolloutStrategy{MaxChangesPerReconcile: -1, MinIntervalSeconds: -1}
+			},
+			fields: []string{"spec.rolloutStrategy.maxChangesPerReconcile", "spec.rolloutStrategy.minIntervalSeconds"},
+		},
+		{
+			name: "Reassignment",
+			mutate: func(nag *assignmentsv1alpha1.NodeAssignmentGroup) {
+				nag.Spec.Assignments[0].ReassignmentPolicy = "Evict"
+				nag.Spec.Assignments[1].DrainTimeoutSeconds = -1
+			},
+			fields: []string{"spec.assignments[0].reassignmentPolicy", "spec.assignments[1].drainTimeoutSeconds"},
+		},
+		{
+			name: "NodeBounds",
+			mutate: func(nag *assignmentsv1alpha1.NodeAssignmentGroup) {
+				minNodes, maxNodes, zero := 3, 2, 0
+				nag.Spec.Assignments[0].MinNodes = &minNodes
+				nag.Spec.Assignments[0].MaxNodes = &maxNodes
+				nag.Spec.Assignments[1].PercentRounding = "Up"
+				// The implicit minimum of 1 also applies to MaxNodes
+				nag.Spec.Assignments[1].MaxNodes = &zero
+			},
+			fields: []string{"spec.assignments[0].maxNodes", "spec.assignments[1].percentRounding", "spec.assignments[1].maxNodes"},
+		},
+		{
+			name: "UnknownEnums",
+			mutate: func(nag *assignmentsv1alpha1.NodeAssignmentGroup) {
+				nag.Spec.Assignments[0].Mode = "Taint"
+				nag.Spec.Assignments[0].TaintEffect = "NoSchedul"
+				nag.Spec.DefaultAssignment.SchedulingMode = "PackRight"
+				nag.Spec.SelectionPolicy = "Random"
+			},
+			fields: []string{"spec.selectionPolicy", "spec.assignments[0].mode", "spec.assignments[0].taintEffect", "spec.defaultAssignment.schedulingMode"},
+		},
+		{
+			name: "CustomKeys",
+			mutate: func(nag *assignmentsv1alpha1.NodeAssignmentGroup) {
+				nag.Spec.Assignments[0].Mode = assignmentsv1alpha1.NodeAssignmentModeTaintOnly
+				nag.Spec.Assignments[0].TaintKey = "dedicated"
+				nag.Spec.Assignments[0].ExtraLabels = map[string]string{"node-role.kubernetes.io/ingress": ""}
+				nag.Spec.Assignments[0].ExtraTaints = []corev1.Taint{{Key: "node-role.kubernetes.io/ingress", Effect: corev1.TaintEffectNoSchedule}}
+			},
+		},
+		{
+			name: "InvalidCustomKeys",
+			mutate: func(nag *assignmentsv1alpha1.NodeAssignmentGroup) {
+				nag.Spec.Assignments[0].LabelKey = "bad key"
+				nag.Spec.Assignments[0].TaintKey = "nag.assignments.kube-valet.io/other"
+				nag.Spec.Assignments[1].ExtraLabels = map[string]string{"kube-valet.io/role": "web"}
+				nag.Spec.Assignments[1].ExtraTaints = []corev1.Taint{{Key: "dedicated", Value: "bad value"}}
+			},
+			fields: []string{
+				"spec.assignments[0].labelKey",
+				"spec.assignments[0].taintKey",
+				"spec.assignments[1].extraLabels[kube-valet.io/role]",
+				"spec.assignments[1].extraTaints[0].value",
+				"spec.assignments[1].extraTaints[0].effect",
+			},
+		},
+		{
+			name: "TopologySpread",
+			mutate: func(nag *assignmentsv1alpha1.NodeAssignmentGroup) {
+				nag.Spec.Assignments[0].TopologySpread = &assignmentsv1alpha1.NodeAssignmentTopologySpread{MaxSkew: -1}
+				nag.Spec.Assignments[1].TopologySpread = &assignmentsv1alpha1.NodeAssignmentTopologySpread{TopologyKey: "bad key"}
+			},
+			fields: []string{"spec.assignments[0].topologySpread.topologyKey", "spec.assignments[0].topologySpread.maxSkew", "spec.assignments[1].topologySpread.topologyKey"},
+		},
+		{
+			name: "Requirements",
+			mutate: func(nag *assignmentsv1alpha1.NodeAssignmentGroup) {
+				nag.Spec.Assignments[0].Requirements = &assignmentsv1alpha1.NodeAssignmentRequirements{
+					MinAllocatable: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("-1Gi")},
+					MinAgeSeconds:  -1,
+				}
+			},
+			fields: []string{"spec.assignments[0].requirements.minAllocatable[memory]", "spec.assignments[0].requirements.minAgeSeconds"},
+		},
+		{
+			name: "PackLeftRanges",
+			mutate: func(nag *assignmentsv1alpha1.NodeAssignmentGroup) {
+				percent := 150
+				nag.Spec.Assignments[1].PackLeft.PercentAvoid = &percent
+				nag.Spec.Assignments[1].PackLeft.NumAvoid = -1
+			},
+			fields: []string{"spec.assignments[1].packLeft.numAvoid", "spec.assignments[1].packLeft.percentAvoid"},
+		},
+		{
+			name: "PackLeftHysteresis",
+			mutate: func(nag *assignmentsv1alpha1.NodeAssignmentGroup) {
+				fullExitPercent := 90
+				nag.Spec.Assignments[1].PackLeft.FullExitPercent = &fullExitPercent
+				nag.Spec.Assignments[1].PackLeft.MinDwellSeconds = -1
+			},
+			fields: []string{"spec.assignments[1].packLeft.fullExitPercent", "spec.assignments[1].packLeft.minDwellSeconds"},
+		},
+		{
+			name: "PackLeftResources",
+			mutate: func(nag *assignmentsv1alpha1.NodeAssignmentGroup) {
+				zero, two, negative := 0, 2, -1
+				nag.Spec.Assignments[1].PackLeft.Resources = []assignmentsv1alpha1.PackLeftResource{
+					{Name: corev1.ResourcePods, Weight: &two},
+					{Name: "example.com/license", Weight: &zero},
+					{Name: ""},
+					{Name: "bad name", Weight: &negative},
+					{Name: corev1.ResourcePods},
+				}
+				nag.Spec.Assignments[1].PackLeft.Aggregation = "Min"
+			},
+			fields: []string{
+				"spec.assignments[1].packLeft.resources[2].name",
+				"spec.assignments[1].packLeft.resources[3].name",
+				"spec.assignments[1].packLeft.resources[3].weight",
+				"spec.assignments[1].packLeft.resources[4].name",
+				"spec.assignments[1].packLeft.aggregation",
+			},
+		},
+		{
+			name: "InvalidSelector",
+			mutate: func(nag *assignmentsv1alpha1.NodeAssignmentGroup) {
+				nag.Spec.NodeSelector = &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "zone", Operator: metav1.LabelSelectorOpIn}},
+				}
+			},
+			fields: []string{"spec.nodeSelector.matchExpressions[0].values"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			nag := newValidNag()
+			tc.mutate(nag)
+
+			errs := ValidateNodeAssignmentGroup(nag)
+			if len(errs) != len(tc.fields) {
+				t.Fatalf("Expected %d errors, got %v", len(tc.fields), errs)
+			}
+			for i, err := range errs {
+				if err.Field != tc.fields[i] {
+					t.Errorf("Unexpected error field: got %s; expected %s", err.Field, tc.fields[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateClusterPodAssignmentRule(t *testing.T) {
+	cpar := &assignmentsv1alpha1.ClusterPodAssignmentRule{
+		ObjectMeta: metav1.ObjectMeta{Name: "testcpar"},
+		Spec: assignmentsv1alpha1.ClusterPodAssignmentRuleSpec{
+			PodAssignmentRuleSpec: assignmentsv1alpha1.PodAssignmentRuleSpec{
+				TargetLabels: map[string]string{"app": "web"},
+				Scheduling: assignmentsv1alpha1.PodAssignmentRuleScheduling{
+					MergeStrategy: assignmentsv1alpha1.PodAssignmentRuleSchedulingMergeStrategyMergeAppend,
+					Tolerations:   []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}},
+				},
+			},
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "web"}},
+		},
+	}
+	if errs := ValidateClusterPodAssignmentRule(cpar); len(errs) != 0 {
+		t.Fatalf("Unexpected errors for a valid rule: %v", errs)
+	}
+
+	cpar.Spec.Scheduling.MergeStrategy = "Merge"
+	cpar.Spec.Scheduling.Tolerations[0].Value = "web"
+	cpar.Spec.NamespaceSelector.MatchLabels["team"] = "bad value"
+
+	expected := []string{
+		"spec.scheduling.mergeStrategy",
+		"spec.scheduling.tolerations[0].value",
+		"spec.namespaceSelector.matchLabels",
+	}
+	errs := ValidateClusterPodAssignmentRule(cpar)
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors, got %v", len(expected), errs)
+	}
+	for i, err := range errs {
+		if err.Field != expected[i] {
+			t.Errorf("Unexpected error field: got %s; expected %s", err.Field, expected[i])
+		}
+	}
+}
@@ -21,7 +21,8 @@ limitations under the License.
 package v1alpha1
 
 import (
-	v1 "k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -29,6 +30,11 @@ import (
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AssignmentStates) DeepCopyInto(out *AssignmentStates) {
 	*out = *in
+	if in.MaxNodes != nil {
+		in, out := &in.MaxNodes, &out.MaxNodes
+		*out = new(int64)
+		**out = **in
+	}
 	return
 }
 
@@ -102,14 +108,70 @@ func (in *ClusterPodAssignmentRuleList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterPodAssignmentRuleSpec) DeepCopyInto(out *ClusterPodAssignmentRuleSpec) {
+	*out = *in
+	in.PodAssignmentRuleSpec.DeepCopyInto(&out.PodAssignmentRuleSpec)
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPodAssignmentRuleSpec.
+func (in *ClusterPodAssignmentRuleSpec) DeepCopy() *ClusterPodAssignmentRuleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterPodAssignmentRuleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeAssignment) DeepCopyInto(out *NodeAssignment) {
 	*out = *in
+	if in.ExtraLabels != nil {
+		in, out := &in.ExtraLabels, &out.ExtraLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExtraTaints != nil {
+		in, out := &in.ExtraTaints, &out.ExtraTaints
+		*out = make([]corev1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MinNodes != nil {
+		in, out := &in.MinNodes, &out.MinNodes
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxNodes != nil {
+		in, out := &in.MaxNodes, &out.MaxNodes
+		*out = new(int)
+		**out = **in
+	}
 	if in.PackLeft != nil {
 		in, out := &in.PackLeft, &out.PackLeft
 		*out = new(PackLeftScheduling)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TopologySpread != nil {
+		in, out := &in.TopologySpread, &out.TopologySpread
+		*out = new(NodeAssignmentTopologySpread)
+		**out = **in
+	}
+	if in.Requirements != nil {
+		in, out := &in.Requirements, &out.Requirements
+		*out = new(NodeAssignmentRequirements)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -129,7 +191,7 @@ func (in *NodeAssignmentGroup) DeepCopyInto(out *NodeAssignmentGroup) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -151,6 +213,23 @@ func (in *NodeAssignmentGroup) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAssignmentGroupCondition) DeepCopyInto(out *NodeAssignmentGroupCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAssignmentGroupCondition.
+func (in *NodeAssignmentGroupCondition) DeepCopy() *NodeAssignmentGroupCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAssignmentGroupCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeAssignmentGroupList) DeepCopyInto(out *NodeAssignmentGroupList) {
 	*out = *in
@@ -184,6 +263,42 @@ func (in *NodeAssignmentGroupList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAssignmentGroupRolloutStatus) DeepCopyInto(out *NodeAssignmentGroupRolloutStatus) {
+	*out = *in
+	if in.LastWaveTime != nil {
+		in, out := &in.LastWaveTime, &out.LastWaveTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAssignmentGroupRolloutStatus.
+func (in *NodeAssignmentGroupRolloutStatus) DeepCopy() *NodeAssignmentGroupRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAssignmentGroupRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAssignmentGroupRolloutStrategy) DeepCopyInto(out *NodeAssignmentGroupRolloutStrategy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAssignmentGroupRolloutStrategy.
+func (in *NodeAssignmentGroupRolloutStrategy) DeepCopy() *NodeAssignmentGroupRolloutStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAssignmentGroupRolloutStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeAssignmentGroupSpec) DeepCopyInto(out *NodeAssignmentGroupSpec) {
 	*out = *in
@@ -194,6 +309,16 @@ func (in *NodeAssignmentGroupSpec) DeepCopyInto(out *NodeAssignmentGroupSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeFieldSelector != nil {
+		in, out := &in.NodeFieldSelector, &out.NodeFieldSelector
+		*out = new(NodeFieldSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.DefaultAssignment != nil {
 		in, out := &in.DefaultAssignment, &out.DefaultAssignment
 		*out = new(NodeAssignment)
@@ -206,6 +331,11 @@ func (in *NodeAssignmentGroupSpec) DeepCopyInto(out *NodeAssignmentGroupSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RolloutStrategy != nil {
+		in, out := &in.RolloutStrategy, &out.RolloutStrategy
+		*out = new(NodeAssignmentGroupRolloutStrategy)
+		**out = **in
+	}
 	return
 }
 
@@ -222,6 +352,25 @@ func (in *NodeAssignmentGroupSpec) DeepCopy() *NodeAssignmentGroupSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeAssignmentGroupStatus) DeepCopyInto(out *NodeAssignmentGroupStatus) {
 	*out = *in
+	if in.AssignmentStates != nil {
+		in, out := &in.AssignmentStates, &out.AssignmentStates
+		*out = make([]AssignmentStates, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(NodeAssignmentGroupRolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]NodeAssignmentGroupCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -235,6 +384,106 @@ func (in *NodeAssignmentGroupStatus) DeepCopy() *NodeAssignmentGroupStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAssignmentRequirements) DeepCopyInto(out *NodeAssignmentRequirements) {
+	*out = *in
+	if in.MinAllocatable != nil {
+		in, out := &in.MinAllocatable, &out.MinAllocatable
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAssignmentRequirements.
+func (in *NodeAssignmentRequirements) DeepCopy() *NodeAssignmentRequirements {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAssignmentRequirements)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAssignmentTopologySpread) DeepCopyInto(out *NodeAssignmentTopologySpread) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAssignmentTopologySpread.
+func (in *NodeAssignmentTopologySpread) DeepCopy() *NodeAssignmentTopologySpread {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAssignmentTopologySpread)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFieldSelector) DeepCopyInto(out *NodeFieldSelector) {
+	*out = *in
+	if in.Unschedulable != nil {
+		in, out := &in.Unschedulable, &out.Unschedulable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TaintsPresent != nil {
+		in, out := &in.TaintsPresent, &out.TaintsPresent
+		*out = make([]corev1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TaintsAbsent != nil {
+		in, out := &in.TaintsAbsent, &out.TaintsAbsent
+		*out = make([]corev1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFieldSelector.
+func (in *NodeFieldSelector) DeepCopy() *NodeFieldSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFieldSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackLeftResource) DeepCopyInto(out *PackLeftResource) {
+	*out = *in
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackLeftResource.
+func (in *PackLeftResource) DeepCopy() *PackLeftResource {
+	if in == nil {
+		return nil
+	}
+	out := new(PackLeftResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PackLeftScheduling) DeepCopyInto(out *PackLeftScheduling) {
 	*out = *in
@@ -243,11 +492,23 @@ func (in *PackLeftScheduling) DeepCopyInto(out *PackLeftScheduling) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.FullExitPercent != nil {
+		in, out := &in.FullExitPercent, &out.FullExitPercent
+		*out = new(int)
+		**out = **in
+	}
 	if in.PercentAvoid != nil {
 		in, out := &in.PercentAvoid, &out.PercentAvoid
 		*out = new(int)
 		**out = **in
 	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]PackLeftResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -333,12 +594,12 @@ func (in *PodAssignmentRuleScheduling) DeepCopyInto(out *PodAssignmentRuleSchedu
 	}
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
+		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
+		*out = make([]corev1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -366,6 +627,11 @@ func (in *PodAssignmentRuleSpec) DeepCopyInto(out *PodAssignmentRuleSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	in.Scheduling.DeepCopyInto(&out.Scheduling)
 	return
 }
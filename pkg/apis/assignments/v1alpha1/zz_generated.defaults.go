@@ -0,0 +1,100 @@
+// +build !ignore_autogenerated
+
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by defaulter-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// RegisterDefaults adds defaulters functions to the given scheme.
+// Public to allow building arbitrary schemes.
+// All generated defaulters are covering - they call all nested defaulters.
+func RegisterDefaults(scheme *runtime.Scheme) error {
+	scheme.AddTypeDefaultingFunc(&ClusterPodAssignmentRule{}, func(obj interface{}) { SetObjectDefaults_ClusterPodAssignmentRule(obj.(*ClusterPodAssignmentRule)) })
+	scheme.AddTypeDefaultingFunc(&ClusterPodAssignmentRuleList{}, func(obj interface{}) {
+		SetObjectDefaults_ClusterPodAssignmentRuleList(obj.(*ClusterPodAssignmentRuleList))
+	})
+	scheme.AddTypeDefaultingFunc(&NodeAssignmentGroup{}, func(obj interface{}) { SetObjectDefaults_NodeAssignmentGroup(obj.(*NodeAssignmentGroup)) })
+	scheme.AddTypeDefaultingFunc(&NodeAssignmentGroupList{}, func(obj interface{}) { SetObjectDefaults_NodeAssignmentGroupList(obj.(*NodeAssignmentGroupList)) })
+	scheme.AddTypeDefaultingFunc(&PodAssignmentRule{}, func(obj interface{}) { SetObjectDefaults_PodAssignmentRule(obj.(*PodAssignmentRule)) })
+	scheme.AddTypeDefaultingFunc(&PodAssignmentRuleList{}, func(obj interface{}) { SetObjectDefaults_PodAssignmentRuleList(obj.(*PodAssignmentRuleList)) })
+	return nil
+}
+
+func SetObjectDefaults_ClusterPodAssignmentRule(in *ClusterPodAssignmentRule) {
+	SetDefaults_PodAssignmentRuleScheduling(&in.Spec.PodAssignmentRuleSpec.Scheduling)
+}
+
+func SetObjectDefaults_ClusterPodAssignmentRuleList(in *ClusterPodAssignmentRuleList) {
+	for i := range in.Items {
+		a := &in.Items[i]
+		SetObjectDefaults_ClusterPodAssignmentRule(a)
+	}
+}
+
+func SetObjectDefaults_NodeAssignmentGroup(in *NodeAssignmentGroup) {
+	SetDefaults_NodeAssignmentGroupSpec(&in.Spec)
+	if in.Spec.DefaultAssignment != nil {
+		SetDefaults_NodeAssignment(in.Spec.DefaultAssignment)
+		if in.Spec.DefaultAssignment.PackLeft != nil {
+			SetDefaults_PackLeftScheduling(in.Spec.DefaultAssignment.PackLeft)
+			for i := range in.Spec.DefaultAssignment.PackLeft.Resources {
+				a := &in.Spec.DefaultAssignment.PackLeft.Resources[i]
+				SetDefaults_PackLeftResource(a)
+			}
+		}
+		if in.Spec.DefaultAssignment.TopologySpread != nil {
+			SetDefaults_NodeAssignmentTopologySpread(in.Spec.DefaultAssignment.TopologySpread)
+		}
+	}
+	for i := range in.Spec.Assignments {
+		a := &in.Spec.Assignments[i]
+		SetDefaults_NodeAssignment(a)
+		if a.PackLeft != nil {
+			SetDefaults_PackLeftScheduling(a.PackLeft)
+			for j := range a.PackLeft.Resources {
+				b := &a.PackLeft.Resources[j]
+				SetDefaults_PackLeftResource(b)
+			}
+		}
+		if a.TopologySpread != nil {
+			SetDefaults_NodeAssignmentTopologySpread(a.TopologySpread)
+		}
+	}
+}
+
+func SetObjectDefaults_NodeAssignmentGroupList(in *NodeAssignmentGroupList) {
+	for i := range in.Items {
+		a := &in.Items[i]
+		SetObjectDefaults_NodeAssignmentGroup(a)
+	}
+}
+
+func SetObjectDefaults_PodAssignmentRule(in *PodAssignmentRule) {
+	SetDefaults_PodAssignmentRuleScheduling(&in.Spec.Scheduling)
+}
+
+func SetObjectDefaults_PodAssignmentRuleList(in *PodAssignmentRuleList) {
+	for i := range in.Items {
+		a := &in.Items[i]
+		SetObjectDefaults_PodAssignmentRule(a)
+	}
+}
@@ -0,0 +1,507 @@
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/domoinc/kube-valet/pkg/apis/assignments/v1alpha1"
+)
+
+// TargetLabelsAnnotationKey keeps the v1alpha1 targetLabels of an object converted to v1beta1, which folds them into
+// its selector, so that converting it back gives the same object
+const TargetLabelsAnnotationKey = GroupName + "/v1alpha1-target-labels"
+
+func addConversionFuncs(scheme *runtime.Scheme) error {
+	if err := scheme.AddConversionFunc((*v1alpha1.NodeAssignmentGroup)(nil), (*NodeAssignmentGroup)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_NodeAssignmentGroup_To_v1beta1_NodeAssignmentGroup(a.(*v1alpha1.NodeAssignmentGroup), b.(*NodeAssignmentGroup), scope)
+	}); err != nil {
+		return err
+	}
+	if err := scheme.AddConversionFunc((*NodeAssignmentGroup)(nil), (*v1alpha1.NodeAssignmentGroup)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_NodeAssignmentGroup_To_v1alpha1_NodeAssignmentGroup(a.(*NodeAssignmentGroup), b.(*v1alpha1.NodeAssignmentGroup), scope)
+	}); err != nil {
+		return err
+	}
+	if err := scheme.AddConversionFunc((*v1alpha1.PodAssignmentRule)(nil), (*PodAssignmentRule)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_PodAssignmentRule_To_v1beta1_PodAssignmentRule(a.(*v1alpha1.PodAssignmentRule), b.(*PodAssignmentRule), scope)
+	}); err != nil {
+		return err
+	}
+	if err := scheme.AddConversionFunc((*PodAssignmentRule)(nil), (*v1alpha1.PodAssignmentRule)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_PodAssignmentRule_To_v1alpha1_PodAssignmentRule(a.(*PodAssignmentRule), b.(*v1alpha1.PodAssignmentRule), scope)
+	}); err != nil {
+		return err
+	}
+	if err := scheme.AddConversionFunc((*v1alpha1.ClusterPodAssignmentRule)(nil), (*ClusterPodAssignmentRule)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ClusterPodAssignmentRule_To_v1beta1_ClusterPodAssignmentRule(a.(*v1alpha1.ClusterPodAssignmentRule), b.(*ClusterPodAssignmentRule), scope)
+	}); err != nil {
+		return err
+	}
+	return scheme.AddConversionFunc((*ClusterPodAssignmentRule)(nil), (*v1alpha1.ClusterPodAssignmentRule)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_ClusterPodAssignmentRule_To_v1alpha1_ClusterPodAssignmentRule(a.(*ClusterPodAssignmentRule), b.(*v1alpha1.ClusterPodAssignmentRule), scope)
+	})
+}
+
+// Convert_v1alpha1_NodeAssignmentGroup_To_v1beta1_NodeAssignmentGroup converts a group to v1beta1.
+// TargetLabels are folded into the node selector and kept in the TargetLabelsAnnotationKey annotation.
+func Convert_v1alpha1_NodeAssignmentGroup_To_v1beta1_NodeAssignmentGroup(in *v1alpha1.NodeAssignmentGroup, out *NodeAssignmentGroup, s conversion.Scope) error {
+	in = in.DeepCopy()
+	out.ObjectMeta = in.ObjectMeta
+	if err := setTargetLabelsAnnotation(&out.ObjectMeta, in.Spec.TargetLabels); err != nil {
+		return err
+	}
+
+	out.Spec = NodeAssignmentGroupSpec{
+		NodeSelector:    mergeTargetLabels(in.Spec.TargetLabels, in.Spec.NodeSelector),
+		SelectionPolicy: NodeSelectionPolicy(in.Spec.SelectionPolicy),
+	}
+	if fs := in.Spec.NodeFieldSelector; fs != nil {
+		out.Spec.NodeFieldSelector = &NodeFieldSelector{
+			Unschedulable: fs.Unschedulable,
+			TaintsPresent: fs.TaintsPresent,
+			TaintsAbsent:  fs.TaintsAbsent,
+		}
+	}
+	if rs := in.Spec.RolloutStrategy; rs != nil {
+		out.Spec.RolloutStrategy = &NodeAssignmentGroupRolloutStrategy{
+			MaxChangesPerReconcile: int32(rs.MaxChangesPerReconcile),
+			MinIntervalSeconds:     rs.MinIntervalSeconds,
+		}
+	}
+	if in.Spec.DefaultAssignment != nil {
+		out.Spec.DefaultAssignment = &NodeAssignment{}
+		convertNodeAssignmentToV1beta1(in.Spec.DefaultAssignment, out.Spec.DefaultAssignment)
+	}
+	if in.Spec.Assignments != nil {
+		out.Spec.Assignments = make([]NodeAssignment, len(in.Spec.Assignments))
+		for i := range in.Spec.Assignments {
+			convertNodeAssignmentToV1beta1(&in.Spec.Assignments[i], &out.Spec.Assignments[i])
+		}
+	}
+
+	out.Status = NodeAssignmentGroupStatus{
+		ObservedGeneration: in.Status.ObservedGeneration,
+		NumMatched:         int32(in.Status.NumMatched),
+		NumSatisfied:       int32(in.Status.NumSatisfied),
+		State:              NodeAssignmentGroupState(in.Status.State),
+	}
+	for _, as := range in.Status.AssignmentStates {
+		out.Status.Assignments = append(out.Status.Assignments, NodeAssignmentStatus{
+			Name:        as.Name,
+			NumDesired:  int32(as.NumDesired),
+			NumAssigned: int32(as.NumAssigned),
+			MinNodes:    int32(as.MinNodes),
+			MaxNodes:    int32PtrFromInt64(as.MaxNodes),
+		})
+	}
+	if r := in.Status.Rollout; r != nil {
+		out.Status.Rollout = &NodeAssignmentGroupRolloutStatus{
+			NumPending:   int32(r.NumPending),
+			NumChanged:   int32(r.NumChanged),
+			LastWaveTime: r.LastWaveTime,
+		}
+	}
+	for _, c := range in.Status.Conditions {
+		out.Status.Conditions = append(out.Status.Conditions, NodeAssignmentGroupCondition{
+			Type:               NodeAssignmentGroupConditionType(c.Type),
+			Status:             c.Status,
+			ObservedGeneration: c.ObservedGeneration,
+			LastTransitionTime: c.LastTransitionTime,
+			Reason:             c.Reason,
+			Message:            c.Message,
+		})
+	}
+	return nil
+}
+
+// Convert_v1beta1_NodeAssignmentGroup_To_v1alpha1_NodeAssignmentGroup converts a group to v1alpha1.
+// TargetLabels kept in the TargetLabelsAnnotationKey annotation are taken back out of the node selector.
+func Convert_v1beta1_NodeAssignmentGroup_To_v1alpha1_NodeAssignmentGroup(in *NodeAssignmentGroup, out *v1alpha1.NodeAssignmentGroup, s conversion.Scope) error {
+	in = in.DeepCopy()
+	out.ObjectMeta = in.ObjectMeta
+	targetLabels, nodeSelector, err := restoreTargetLabels(&out.ObjectMeta, in.Spec.NodeSelector)
+	if err != nil {
+		return err
+	}
+
+	out.Spec = v1alpha1.NodeAssignmentGroupSpec{
+		TargetLabels:    targetLabels,
+		NodeSelector:    nodeSelector,
+		SelectionPolicy: v1alpha1.NodeSelectionPolicy(in.Spec.SelectionPolicy),
+	}
+	if fs := in.Spec.NodeFieldSelector; fs != nil {
+		out.Spec.NodeFieldSelector = &v1alpha1.NodeFieldSelector{
+			Unschedulable: fs.Unschedulable,
+			TaintsPresent: fs.TaintsPresent,
+			TaintsAbsent:  fs.TaintsAbsent,
+		}
+	}
+	if rs := in.Spec.RolloutStrategy; rs != nil {
+		out.Spec.RolloutStrategy = &v1alpha1.NodeAssignmentGroupRolloutStrategy{
+			MaxChangesPerReconcile: int(rs.MaxChangesPerReconcile),
+			MinIntervalSeconds:     rs.MinIntervalSeconds,
+		}
+	}
+	if in.Spec.DefaultAssignment != nil {
+		out.Spec.DefaultAssignment = &v1alpha1.NodeAssignment{}
+		convertNodeAssignmentToV1alpha1(in.Spec.DefaultAssignment, out.Spec.DefaultAssignment)
+	}
+	if in.Spec.Assignments != nil {
+		out.Spec.Assignments = make([]v1alpha1.NodeAssignment, len(in.Spec.Assignments))
+		for i := range in.Spec.Assignments {
+			convertNodeAssignmentToV1alpha1(&in.Spec.Assignments[i], &out.Spec.Assignments[i])
+		}
+	}
+
+	out.Status = v1alpha1.NodeAssignmentGroupStatus{
+		ObservedGeneration: in.Status.ObservedGeneration,
+		NumMatched:         int64(in.Status.NumMatched),
+		NumSatisfied:       int64(in.Status.NumSatisfied),
+		State:              v1alpha1.NodeAssignmentGroupState(in.Status.State),
+	}
+	for _, as := range in.Status.Assignments {
+		out.Status.AssignmentStates = append(out.Status.AssignmentStates, v1alpha1.AssignmentStates{
+			Name:        as.Name,
+			NumDesired:  int64(as.NumDesired),
+			NumAssigned: int64(as.NumAssigned),
+			MinNodes:    int64(as.MinNodes),
+			MaxNodes:    int64Ptr(as.MaxNodes),
+		})
+	}
+	if r := in.Status.Rollout; r != nil {
+		out.Status.Rollout = &v1alpha1.NodeAssignmentGroupRolloutStatus{
+			NumPending:   int64(r.NumPending),
+			NumChanged:   int64(r.NumChanged),
+			LastWaveTime: r.LastWaveTime,
+		}
+	}
+	for _, c := range in.Status.Conditions {
+		out.Status.Conditions = append(out.Status.Conditions, v1alpha1.NodeAssignmentGroupCondition{
+			Type:               v1alpha1.NodeAssignmentGroupConditionType(c.Type),
+			Status:             c.Status,
+			ObservedGeneration: c.ObservedGeneration,
+			LastTransitionTime: c.LastTransitionTime,
+			Reason:             c.Reason,
+			Message:            c.Message,
+		})
+	}
+	return nil
+}
+
+func convertNodeAssignmentToV1beta1(in *v1alpha1.NodeAssignment, out *NodeAssignment) {
+	*out = NodeAssignment{
+		Name:                in.Name,
+		Mode:                NodeAssignmentMode(in.Mode),
+		TaintEffect:         in.TaintEffect,
+		LabelKey:            in.LabelKey,
+		TaintKey:            in.TaintKey,
+		ExtraLabels:         in.ExtraLabels,
+		ExtraTaints:         in.ExtraTaints,
+		NumDesired:          int32(in.NumDesired),
+		PercentDesired:      int32(in.PercentDesired),
+		PercentRounding:     NodeAssignmentPercentRounding(in.PercentRounding),
+		MinNodes:            int32Ptr(in.MinNodes),
+		MaxNodes:            int32Ptr(in.MaxNodes),
+		SchedulingMode:      NodeAssignmentSchedulingMode(in.SchedulingMode),
+		ReassignmentPolicy:  NodeAssignmentReassignmentPolicy(in.ReassignmentPolicy),
+		DrainTimeoutSeconds: in.DrainTimeoutSeconds,
+	}
+	if pl := in.PackLeft; pl != nil {
+		out.PackLeft = &PackLeftScheduling{
+			FullPercent:     int32Ptr(pl.FullPercent),
+			FullExitPercent: int32Ptr(pl.FullExitPercent),
+			MinDwellSeconds: pl.MinDwellSeconds,
+			NumAvoid:        int32(pl.NumAvoid),
+			PercentAvoid:    int32Ptr(pl.PercentAvoid),
+			Aggregation:     PackLeftAggregation(pl.Aggregation),
+		}
+		for _, r := range pl.Resources {
+			out.PackLeft.Resources = append(out.PackLeft.Resources, PackLeftResource{Name: r.Name, Weight: int32Ptr(r.Weight)})
+		}
+	}
+	if ts := in.TopologySpread; ts != nil {
+		out.TopologySpread = &NodeAssignmentTopologySpread{
+			TopologyKey: ts.TopologyKey,
+			MaxSkew:     int32(ts.MaxSkew),
+		}
+	}
+	if r := in.Requirements; r != nil {
+		out.Requirements = &NodeAssignmentRequirements{
+			MinAllocatable:       r.MinAllocatable,
+			NodeSelector:         r.NodeSelector,
+			ExcludeNotReady:      r.ExcludeNotReady,
+			ExcludeUnschedulable: r.ExcludeUnschedulable,
+			MinAgeSeconds:        r.MinAgeSeconds,
+		}
+	}
+}
+
+func convertNodeAssignmentToV1alpha1(in *NodeAssignment, out *v1alpha1.NodeAssignment) {
+	*out = v1alpha1.NodeAssignment{
+		Name:                in.Name,
+		Mode:                v1alpha1.NodeAssignmentMode(in.Mode),
+		TaintEffect:         in.TaintEffect,
+		LabelKey:            in.LabelKey,
+		TaintKey:            in.TaintKey,
+		ExtraLabels:         in.ExtraLabels,
+		ExtraTaints:         in.ExtraTaints,
+		NumDesired:          int(in.NumDesired),
+		PercentDesired:      int(in.PercentDesired),
+		PercentRounding:     v1alpha1.NodeAssignmentPercentRounding(in.PercentRounding),
+		MinNodes:            intPtr(in.MinNodes),
+		MaxNodes:            intPtr(in.MaxNodes),
+		SchedulingMode:      v1alpha1.NodeAssignmentSchedulingMode(in.SchedulingMode),
+		ReassignmentPolicy:  v1alpha1.NodeAssignmentReassignmentPolicy(in.ReassignmentPolicy),
+		DrainTimeoutSeconds: in.DrainTimeoutSeconds,
+	}
+	if pl := in.PackLeft; pl != nil {
+		out.PackLeft = &v1alpha1.PackLeftScheduling{
+			FullPercent:     intPtr(pl.FullPercent),
+			FullExitPercent: intPtr(pl.FullExitPercent),
+			MinDwellSeconds: pl.MinDwellSeconds,
+			NumAvoid:        int(pl.NumAvoid),
+			PercentAvoid:    intPtr(pl.PercentAvoid),
+			Aggregation:     v1alpha1.PackLeftAggregation(pl.Aggregation),
+		}
+		for _, r := range pl.Resources {
+			out.PackLeft.Resources = append(out.PackLeft.Resources, v1alpha1.PackLeftResource{Name: r.Name, Weight: intPtr(r.Weight)})
+		}
+	}
+	if ts := in.TopologySpread; ts != nil {
+		out.TopologySpread = &v1alpha1.NodeAssignmentTopologySpread{
+			TopologyKey: ts.TopologyKey,
+			MaxSkew:     int(ts.MaxSkew),
+		}
+	}
+	if r := in.Requirements; r != nil {
+		out.Requirements = &v1alpha1.NodeAssignmentRequirements{
+			MinAllocatable:       r.MinAllocatable,
+			NodeSelector:         r.NodeSelector,
+			ExcludeNotReady:      r.ExcludeNotReady,
+			ExcludeUnschedulable: r.ExcludeUnschedulable,
+			MinAgeSeconds:        r.MinAgeSeconds,
+		}
+	}
+}
+
+// Convert_v1alpha1_PodAssignmentRule_To_v1beta1_PodAssignmentRule converts a rule to v1beta1.
+// TargetLabels are folded into the pod selector and kept in the TargetLabelsAnnotationKey annotation.
+func Convert_v1alpha1_PodAssignmentRule_To_v1beta1_PodAssignmentRule(in *v1alpha1.PodAssignmentRule, out *PodAssignmentRule, s conversion.Scope) error {
+	in = in.DeepCopy()
+	out.ObjectMeta = in.ObjectMeta
+	return convertPodAssignmentRuleSpecToV1beta1(&in.Spec, &out.Spec, &out.ObjectMeta)
+}
+
+// Convert_v1beta1_PodAssignmentRule_To_v1alpha1_PodAssignmentRule converts a rule to v1alpha1.
+// TargetLabels kept in the TargetLabelsAnnotationKey annotation are taken back out of the pod selector.
+func Convert_v1beta1_PodAssignmentRule_To_v1alpha1_PodAssignmentRule(in *PodAssignmentRule, out *v1alpha1.PodAssignmentRule, s conversion.Scope) error {
+	in = in.DeepCopy()
+	out.ObjectMeta = in.ObjectMeta
+	return convertPodAssignmentRuleSpecToV1alpha1(&in.Spec, &out.Spec, &out.ObjectMeta)
+}
+
+// Convert_v1alpha1_ClusterPodAssignmentRule_To_v1beta1_ClusterPodAssignmentRule converts a cluster rule to v1beta1.
+// TargetLabels are folded into the pod selector and kept in the TargetLabelsAnnotationKey annotation.
+func Convert_v1alpha1_ClusterPodAssignmentRule_To_v1beta1_ClusterPodAssignmentRule(in *v1alpha1.ClusterPodAssignmentRule, out *ClusterPodAssignmentRule, s conversion.Scope) error {
+	in = in.DeepCopy()
+	out.ObjectMeta = in.ObjectMeta
+	if err := convertPodAssignmentRuleSpecToV1beta1(&in.Spec.PodAssignmentRuleSpec, &out.Spec.PodAssignmentRuleSpec, &out.ObjectMeta); err != nil {
+		return err
+	}
+	out.Spec.NamespaceSelector = in.Spec.NamespaceSelector
+	return nil
+}
+
+// Convert_v1beta1_ClusterPodAssignmentRule_To_v1alpha1_ClusterPodAssignmentRule converts a cluster rule to v1alpha1.
+// TargetLabels kept in the TargetLabelsAnnotationKey annotation are taken back out of the pod selector.
+func Convert_v1beta1_ClusterPodAssignmentRule_To_v1alpha1_ClusterPodAssignmentRule(in *ClusterPodAssignmentRule, out *v1alpha1.ClusterPodAssignmentRule, s conversion.Scope) error {
+	in = in.DeepCopy()
+	out.ObjectMeta = in.ObjectMeta
+	if err := convertPodAssignmentRuleSpecToV1alpha1(&in.Spec.PodAssignmentRuleSpec, &out.Spec.PodAssignmentRuleSpec, &out.ObjectMeta); err != nil {
+		return err
+	}
+	out.Spec.NamespaceSelector = in.Spec.NamespaceSelector
+	return nil
+}
+
+func convertPodAssignmentRuleSpecToV1beta1(in *v1alpha1.PodAssignmentRuleSpec, out *PodAssignmentRuleSpec, meta *metav1.ObjectMeta) error {
+	if err := setTargetLabelsAnnotation(meta, in.TargetLabels); err != nil {
+		return err
+	}
+	*out = PodAssignmentRuleSpec{
+		PodSelector:    mergeTargetLabels(in.TargetLabels, in.PodSelector),
+		Priority:       in.Priority,
+		StopProcessing: in.StopProcessing,
+		Scheduling: PodAssignmentRuleScheduling{
+			MergeStrategy: PodAssignmentRuleSchedulingMergeStrategy(in.Scheduling.MergeStrategy),
+			NodeSelector:  in.Scheduling.NodeSelector,
+			Affinity:      in.Scheduling.Affinity,
+			Tolerations:   in.Scheduling.Tolerations,
+		},
+	}
+	return nil
+}
+
+func convertPodAssignmentRuleSpecToV1alpha1(in *PodAssignmentRuleSpec, out *v1alpha1.PodAssignmentRuleSpec, meta *metav1.ObjectMeta) error {
+	targetLabels, podSelector, err := restoreTargetLabels(meta, in.PodSelector)
+	if err != nil {
+		return err
+	}
+	*out = v1alpha1.PodAssignmentRuleSpec{
+		TargetLabels:   targetLabels,
+		PodSelector:    podSelector,
+		Priority:       in.Priority,
+		StopProcessing: in.StopProcessing,
+		Scheduling: v1alpha1.PodAssignmentRuleScheduling{
+			MergeStrategy: v1alpha1.PodAssignmentRuleSchedulingMergeStrategy(in.Scheduling.MergeStrategy),
+			NodeSelector:  in.Scheduling.NodeSelector,
+			Affinity:      in.Scheduling.Affinity,
+			Tolerations:   in.Scheduling.Tolerations,
+		},
+	}
+	return nil
+}
+
+// mergeTargetLabels returns a selector that requires both the target labels and the selector to match.
+// A target label whose key is already matched to a different value becomes an In expression so the
+// selector still matches nothing, just like the v1alpha1 object.
+func mergeTargetLabels(targetLabels map[string]string, selector *metav1.LabelSelector) *metav1.LabelSelector {
+	if len(targetLabels) == 0 {
+		return selector
+	}
+	if selector == nil {
+		selector = &metav1.LabelSelector{}
+	}
+	if selector.MatchLabels == nil {
+		selector.MatchLabels = make(map[string]string, len(targetLabels))
+	}
+	keys := make([]string, 0, len(targetLabels))
+	for k := range targetLabels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := targetLabels[k]
+		if existing, ok := selector.MatchLabels[k]; ok && existing != v {
+			selector.MatchExpressions = append(selector.MatchExpressions, metav1.LabelSelectorRequirement{
+				Key:      k,
+				Operator: metav1.LabelSelectorOpIn,
+				Values:   []string{v},
+			})
+			continue
+		}
+		selector.MatchLabels[k] = v
+	}
+	return selector
+}
+
+// splitTargetLabels undoes mergeTargetLabels. It returns the target labels that are still required by the selector,
+// since it may have been edited in v1beta1, and the selector without them.
+func splitTargetLabels(targetLabels map[string]string, selector *metav1.LabelSelector) (map[string]string, *metav1.LabelSelector) {
+	if selector == nil {
+		return nil, nil
+	}
+	kept := make(map[string]string, len(targetLabels))
+	for k, v := range targetLabels {
+		if existing, ok := selector.MatchLabels[k]; ok && existing == v {
+			delete(selector.MatchLabels, k)
+			kept[k] = v
+			continue
+		}
+		for i, r := range selector.MatchExpressions {
+			if r.Key == k && r.Operator == metav1.LabelSelectorOpIn && len(r.Values) == 1 && r.Values[0] == v {
+				selector.MatchExpressions = append(selector.MatchExpressions[:i], selector.MatchExpressions[i+1:]...)
+				kept[k] = v
+				break
+			}
+		}
+	}
+
+	if len(kept) == 0 {
+		kept = nil
+	}
+	if len(selector.MatchLabels) == 0 {
+		selector.MatchLabels = nil
+	}
+	if len(selector.MatchExpressions) == 0 {
+		selector.MatchExpressions = nil
+	}
+	if selector.MatchLabels == nil && selector.MatchExpressions == nil {
+		selector = nil
+	}
+	return kept, selector
+}
+
+// setTargetLabelsAnnotation keeps the target labels of a v1alpha1 object in the annotations of its v1beta1 version
+func setTargetLabelsAnnotation(meta *metav1.ObjectMeta, targetLabels map[string]string) error {
+	if len(targetLabels) == 0 {
+		return nil
+	}
+	value, err := json.Marshal(targetLabels)
+	if err != nil {
+		return err
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string, 1)
+	}
+	meta.Annotations[TargetLabelsAnnotationKey] = string(value)
+	return nil
+}
+
+// restoreTargetLabels removes the TargetLabelsAnnotationKey annotation and returns the target labels it kept along
+// with the selector they were folded into, without them.
+func restoreTargetLabels(meta *metav1.ObjectMeta, selector *metav1.LabelSelector) (map[string]string, *metav1.LabelSelector, error) {
+	value, ok := meta.Annotations[TargetLabelsAnnotationKey]
+	if !ok {
+		return nil, selector, nil
+	}
+	delete(meta.Annotations, TargetLabelsAnnotationKey)
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+
+	targetLabels := map[string]string{}
+	if err := json.Unmarshal([]byte(value), &targetLabels); err != nil {
+		return nil, nil, fmt.Errorf("invalid %s annotation: %v", TargetLabelsAnnotationKey, err)
+	}
+	targetLabels, selector = splitTargetLabels(targetLabels, selector)
+	return targetLabels, selector, nil
+}
+
+func int32Ptr(i *int) *int32 {
+	if i == nil {
+		return nil
+	}
+	v := int32(*i)
+	return &v
+}
+
+func intPtr(i *int32) *int {
+	if i == nil {
+		return nil
+	}
+	v := int(*i)
+	return &v
+}
+
+func int32PtrFromInt64(i *int64) *int32 {
+	if i == nil {
+		return nil
+	}
+	v := int32(*i)
+	return &v
+}
+
+func int64Ptr(i *int32) *int64 {
+	if i == nil {
+		return nil
+	}
+	v := int64(*i)
+	return &v
+}
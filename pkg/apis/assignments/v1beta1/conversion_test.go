@@ -0,0 +1,191 @@
+package v1beta1
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/domoinc/kube-valet/pkg/apis/assignments/v1alpha1"
+)
+
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return scheme
+}
+
+func TestConvertNodeAssignmentGroup(t *testing.T) {
+	scheme := newTestScheme(t)
+	fullPercent, fullExitPercent, minNodes, maxNodes, maxNodesStatus, weight := 70, 60, 0, 5, int64(5), 3
+	alpha := &v1alpha1.NodeAssignmentGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "testnag", ResourceVersion: "5"},
+		Spec: v1alpha1.NodeAssignmentGroupSpec{
+			TargetLabels:    map[string]string{"pool": "web", "zone": "a"},
+			NodeSelector:    &metav1.LabelSelector{MatchLabels: map[string]string{"zone": "b"}},
+			SelectionPolicy: v1alpha1.NodeSelectionPolicyNewest,
+			RolloutStrategy: &v1alpha1.NodeAssignmentGroupRolloutStrategy{MaxChangesPerReconcile: 2, MinIntervalSeconds: 60},
+			Assignments: []v1alpha1.NodeAssignment{
+				{Name: "packed", Mode: v1alpha1.NodeAssignmentModeTaintOnly, TaintKey: "dedicated", LabelKey: "role",
+					ExtraLabels: map[string]string{"node-role.kubernetes.io/ingress": ""}, ExtraTaints: []corev1.Taint{{Key: "ingress", Effect: corev1.TaintEffectNoSchedule}},
+					NumDesired: 3, MinNodes: &minNodes, MaxNodes: &maxNodes, PercentRounding: v1alpha1.NodeAssignmentPercentRoundingCeil,
+					ReassignmentPolicy: v1alpha1.NodeAssignmentReassignmentPolicyDrain, DrainTimeoutSeconds: 120,
+					SchedulingMode: v1alpha1.NodeAssignmentSchedulingModePackLeft, PackLeft: &v1alpha1.PackLeftScheduling{FullPercent: &fullPercent, FullExitPercent: &fullExitPercent, MinDwellSeconds: 300, NumAvoid: 2,
+						Resources:   []v1alpha1.PackLeftResource{{Name: corev1.ResourcePods, Weight: &weight}, {Name: "example.com/license"}},
+						Aggregation: v1alpha1.PackLeftAggregationWeightedAverage},
+					TopologySpread: &v1alpha1.NodeAssignmentTopologySpread{TopologyKey: "zone", MaxSkew: 2}},
+			},
+		},
+		Status: v1alpha1.NodeAssignmentGroupStatus{
+			NumMatched:       4,
+			Rollout:          &v1alpha1.NodeAssignmentGroupRolloutStatus{NumPending: 1, NumChanged: 2},
+			AssignmentStates: []v1alpha1.AssignmentStates{{Name: "packed", NumDesired: 3, NumAssigned: 2, MaxNodes: &maxNodesStatus}},
+		},
+	}
+
+	obj, err := scheme.ConvertToVersion(alpha.DeepCopy(), SchemeGroupVersion)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	beta := obj.(*NodeAssignmentGroup)
+
+	if beta.APIVersion != SchemeGroupVersion.String() || beta.Name != "testnag" || beta.ResourceVersion != "5" {
+		t.Errorf("Unexpected type or object meta: %+v %+v", beta.TypeMeta, beta.ObjectMeta)
+	}
+
+	// Target labels must be folded into the selector without loosening it
+	expected := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"pool": "web", "zone": "b"},
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "zone", Operator: metav1.LabelSelectorOpIn, Values: []string{"a"}},
+		},
+	}
+	if !apiequality.Semantic.DeepEqual(beta.Spec.NodeSelector, expected) {
+		t.Errorf("Unexpected node selector: %+v", beta.Spec.NodeSelector)
+	}
+
+	pl := beta.Spec.Assignments[0].PackLeft
+	if pl == nil || *pl.FullPercent != 70 || *pl.FullExitPercent != 60 || pl.MinDwellSeconds != 300 || pl.NumAvoid != 2 || pl.PercentAvoid != nil || len(pl.Resources) != 2 || *pl.Resources[0].Weight != 3 || pl.Resources[1].Weight != nil ||
+		pl.Aggregation != PackLeftAggregationWeightedAverage {
+		t.Errorf("Unexpected PackLeft: %+v", pl)
+	}
+	if na := beta.Spec.Assignments[0]; na.MinNodes == nil || *na.MinNodes != 0 || na.MaxNodes == nil || *na.MaxNodes != 5 || na.PercentRounding != NodeAssignmentPercentRoundingCeil {
+		t.Errorf("Unexpected node bounds: %+v", na)
+	}
+	if na := beta.Spec.Assignments[0]; na.Mode != NodeAssignmentModeTaintOnly || na.TaintKey != "dedicated" || na.LabelKey != "role" || len(na.ExtraLabels) != 1 || len(na.ExtraTaints) != 1 {
+		t.Errorf("Unexpected keys: %+v", na)
+	}
+	if beta.Spec.SelectionPolicy != NodeSelectionPolicyNewest {
+		t.Errorf("Unexpected selection policy: %s", beta.Spec.SelectionPolicy)
+	}
+	if rs := beta.Spec.RolloutStrategy; rs == nil || rs.MaxChangesPerReconcile != 2 || rs.MinIntervalSeconds != 60 {
+		t.Errorf("Unexpected rollout strategy: %+v", rs)
+	}
+	if beta.Status.Rollout == nil || beta.Status.Rollout.NumPending != 1 {
+		t.Errorf("Unexpected rollout status: %+v", beta.Status.Rollout)
+	}
+	if len(beta.Status.Assignments) != 1 || beta.Status.Assignments[0].NumAssigned != 2 || beta.Status.NumMatched != 4 {
+		t.Errorf("Unexpected status: %+v", beta.Status)
+	}
+
+	if beta.Annotations[TargetLabelsAnnotationKey] != `{"pool":"web","zone":"a"}` {
+		t.Errorf("Unexpected annotations: %v", beta.Annotations)
+	}
+
+	// Converting back must give the same object, target labels included
+	obj, err = scheme.ConvertToVersion(beta.DeepCopy(), v1alpha1.SchemeGroupVersion)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	back := obj.(*v1alpha1.NodeAssignmentGroup)
+	alpha.TypeMeta = back.TypeMeta
+	if !apiequality.Semantic.DeepEqual(alpha, back) {
+		t.Errorf("Round trip mismatch:\n%+v\n%+v", alpha, back)
+	}
+
+	// Target labels that were taken out of the selector in v1beta1 are not restored
+	delete(beta.Spec.NodeSelector.MatchLabels, "pool")
+	obj, err = scheme.ConvertToVersion(beta, v1alpha1.SchemeGroupVersion)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	back = obj.(*v1alpha1.NodeAssignmentGroup)
+	if len(back.Spec.TargetLabels) != 1 || back.Spec.TargetLabels["zone"] != "a" || len(back.Spec.NodeSelector.MatchExpressions) != 0 || back.Annotations != nil {
+		t.Errorf("Unexpected edited round trip: %+v %+v", back.Spec, back.ObjectMeta)
+	}
+}
+
+func TestConvertPodAssignmentRuleTargetLabels(t *testing.T) {
+	scheme := newTestScheme(t)
+	alpha := &v1alpha1.PodAssignmentRule{
+		ObjectMeta: metav1.ObjectMeta{Name: "testpar", Namespace: "web", Annotations: map[string]string{"owner": "web"}},
+		Spec: v1alpha1.PodAssignmentRuleSpec{
+			TargetLabels: map[string]string{"app": "web"},
+			Scheduling:   v1alpha1.PodAssignmentRuleScheduling{MergeStrategy: v1alpha1.PodAssignmentRuleSchedulingMergeStrategyMergeAppend},
+		},
+	}
+
+	obj, err := scheme.ConvertToVersion(alpha.DeepCopy(), SchemeGroupVersion)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	beta := obj.(*PodAssignmentRule)
+	if beta.Spec.PodSelector == nil || beta.Spec.PodSelector.MatchLabels["app"] != "web" || len(beta.Annotations) != 2 {
+		t.Errorf("Unexpected v1beta1 rule: %+v %+v", beta.Spec, beta.ObjectMeta)
+	}
+
+	obj, err = scheme.ConvertToVersion(beta, v1alpha1.SchemeGroupVersion)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	back := obj.(*v1alpha1.PodAssignmentRule)
+	alpha.TypeMeta = back.TypeMeta
+	if !apiequality.Semantic.DeepEqual(alpha, back) {
+		t.Errorf("Round trip mismatch:\n%+v\n%+v", alpha, back)
+	}
+}
+
+func TestConvertClusterPodAssignmentRule(t *testing.T) {
+	scheme := newTestScheme(t)
+	beta := &ClusterPodAssignmentRule{
+		ObjectMeta: metav1.ObjectMeta{Name: "testcpar"},
+		Spec: ClusterPodAssignmentRuleSpec{
+			PodAssignmentRuleSpec: PodAssignmentRuleSpec{
+				PodSelector:    &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				Priority:       10,
+				StopProcessing: true,
+				Scheduling: PodAssignmentRuleScheduling{
+					MergeStrategy: PodAssignmentRuleSchedulingMergeStrategyMergeAppend,
+					NodeSelector:  map[string]string{"disk": "ssd"},
+				},
+			},
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "web"}},
+		},
+	}
+
+	obj, err := scheme.ConvertToVersion(beta.DeepCopy(), v1alpha1.SchemeGroupVersion)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	alpha := obj.(*v1alpha1.ClusterPodAssignmentRule)
+	if alpha.Spec.Priority != 10 || !alpha.Spec.StopProcessing || alpha.Spec.Scheduling.MergeStrategy != v1alpha1.PodAssignmentRuleSchedulingMergeStrategyMergeAppend {
+		t.Errorf("Unexpected v1alpha1 spec: %+v", alpha.Spec)
+	}
+
+	obj, err = scheme.ConvertToVersion(alpha, SchemeGroupVersion)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	back := obj.(*ClusterPodAssignmentRule)
+	beta.TypeMeta = back.TypeMeta
+	if !apiequality.Semantic.DeepEqual(beta, back) {
+		t.Errorf("Round trip mismatch:\n%+v\n%+v", beta, back)
+	}
+}
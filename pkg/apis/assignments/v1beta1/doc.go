@@ -0,0 +1,10 @@
+// +k8s:deepcopy-gen=package,register
+// +groupName=assignments.kube-valet.io
+
+/*
+Package v1beta1 implements the v1beta1 version of kube-valet custom resource types
+
+v1alpha1 remains the storage version. Objects are converted between the versions by the
+conversion webhook using the functions registered in this package.
+*/
+package v1beta1
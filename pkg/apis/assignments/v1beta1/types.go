@@ -0,0 +1,611 @@
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// -------------------------------------------------------------------------------- NodeAssignmentGroup
+// generation tags. The empty line after is IMPORTANT!
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NodeAssignmentGroup represents the configuration of a group of nodes that will be auto-labeled
+// +k8s:openapi-gen=true
+type NodeAssignmentGroup struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the group and it's assignments
+	Spec NodeAssignmentGroupSpec `json:"spec,omitempty"`
+
+	// Status represents the current status of the group assignments.
+	// +optional
+	Status NodeAssignmentGroupStatus `json:"status,omitempty"`
+}
+
+// NodeAssignmentGroupSpec describes the group and it's assignments
+// +k8s:openapi-gen=true
+type NodeAssignmentGroupSpec struct {
+	// NodeSelector is optional. If not provided, the group will match all nodes in the cluster.
+	// +optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+
+	// NodeFieldSelector is optional. It matches nodes based on attributes other than labels.
+	// +optional
+	NodeFieldSelector *NodeFieldSelector `json:"nodeFieldSelector,omitempty"`
+
+	// DefaultAssignment is given to any nodes that are left in the group after processing assignments
+	// +optional
+	DefaultAssignment *NodeAssignment `json:"defaultAssignment,omitempty"`
+
+	// Assignments is the array of assignments to be applied. This list should be ordered by the user
+	// with the most important assignments first.
+	// +optional
+	// +patchMergeKey=name
+	// +patchStrategy=merge
+	Assignments []NodeAssignment `json:"assignments,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+
+	// SelectionPolicy decides which nodes keep an assignment when it shrinks and which nodes are picked when it grows
+	// +optional
+	SelectionPolicy NodeSelectionPolicy `json:"selectionPolicy,omitempty"`
+
+	// RolloutStrategy limits how many nodes change assignment at once
+	// +optional
+	RolloutStrategy *NodeAssignmentGroupRolloutStrategy `json:"rolloutStrategy,omitempty"`
+}
+
+// NodeAssignmentGroupRolloutStrategy spreads assignment changes over several waves
+// +k8s:openapi-gen=true
+type NodeAssignmentGroupRolloutStrategy struct {
+	// MaxChangesPerReconcile is the most nodes whose assignment is changed in one wave. 0 means no limit
+	// +optional
+	MaxChangesPerReconcile int32 `json:"maxChangesPerReconcile,omitempty"`
+
+	// MinIntervalSeconds is the least amount of time between two waves
+	// +optional
+	MinIntervalSeconds int64 `json:"minIntervalSeconds,omitempty"`
+}
+
+// NodeSelectionPolicy defines the order in which nodes are preferred for an assignment
+// +k8s:openapi-gen=true
+type NodeSelectionPolicy string
+
+const (
+	// NodeSelectionPolicyStable prefers nodes by name
+	NodeSelectionPolicyStable NodeSelectionPolicy = "Stable"
+
+	// NodeSelectionPolicyMostPods prefers the nodes running the most pods that select the assignment label or
+	// tolerate its taint
+	NodeSelectionPolicyMostPods NodeSelectionPolicy = "MostPods"
+
+	// NodeSelectionPolicyNewest prefers the most recently created nodes
+	NodeSelectionPolicyNewest NodeSelectionPolicy = "Newest"
+
+	// NodeSelectionPolicyOldest prefers the least recently created nodes
+	NodeSelectionPolicyOldest NodeSelectionPolicy = "Oldest"
+)
+
+// NodeFieldSelector matches nodes based on attributes other than labels. All given fields must match.
+// +k8s:openapi-gen=true
+type NodeFieldSelector struct {
+	// Unschedulable is optional. When given it must match spec.unschedulable of the node.
+	// +optional
+	Unschedulable *bool `json:"unschedulable,omitempty"`
+
+	// TaintsPresent is a list of taints that must all be present on the node. Taints are matched by key,
+	// the value and effect are only compared when given.
+	// +optional
+	TaintsPresent []corev1.Taint `json:"taintsPresent,omitempty"`
+
+	// TaintsAbsent is a list of taints that must not be present on the node. Taints are matched by key,
+	// the value and effect are only compared when given.
+	// +optional
+	TaintsAbsent []corev1.Taint `json:"taintsAbsent,omitempty"`
+}
+
+// NodeAssignment describes the assignments possible for the group
+// and the number of nodes for the assignment
+// +k8s:openapi-gen=true
+type NodeAssignment struct {
+	// Name is used when applying the assignment label to the nodes
+	Name string `json:"name"`
+
+	// Mode determines whether labels or labels and taints are applied to nodes in the assignment
+	// +optional
+	Mode NodeAssignmentMode `json:"mode,omitempty"`
+
+	// TaintEffect controls the effect of the taint. Possible values
+	// come from the upstream type
+	// +optional
+	TaintEffect corev1.TaintEffect `json:"taintEffect,omitempty"`
+
+	// LabelKey replaces the key of the assignment label. Default: nag.assignments.kube-valet.io/<group name>
+	// +optional
+	LabelKey string `json:"labelKey,omitempty"`
+
+	// TaintKey replaces the key of the assignment taint. Default: nag.assignments.kube-valet.io/<group name>
+	// +optional
+	TaintKey string `json:"taintKey,omitempty"`
+
+	// ExtraLabels are set on every node of the assignment in addition to the assignment label, whatever the Mode
+	// +optional
+	ExtraLabels map[string]string `json:"extraLabels,omitempty"`
+
+	// ExtraTaints are set on every node of the assignment in addition to the assignment taint, whatever the Mode
+	// +optional
+	ExtraTaints []corev1.Taint `json:"extraTaints,omitempty"`
+
+	// NumDesired is the number of nodes that should be assigned to this group.
+	// When specified along with PercentDesired, whichever request results in the most nodes is used
+	// +optional
+	NumDesired int32 `json:"numDesired,omitempty"`
+
+	// PercentDesired is the percentage of matching nodes that should be assigned to this group.
+	// When specified along with NumDesired, whichever request results in the most nodes is used
+	// +optional
+	PercentDesired int32 `json:"percentDesired,omitempty"`
+
+	// PercentRounding controls how PercentDesired is turned into a whole number of nodes
+	// +optional
+	PercentRounding NodeAssignmentPercentRounding `json:"percentRounding,omitempty"`
+
+	// MinNodes is the fewest nodes the assignment will ask for. When it is not given the minimum is 1
+	// +optional
+	MinNodes *int32 `json:"minNodes,omitempty"`
+
+	// MaxNodes is the most nodes the assignment will ask for. There is no limit when it is not given
+	// +optional
+	MaxNodes *int32 `json:"maxNodes,omitempty"`
+
+	// ReassignmentPolicy controls what happens to a node before it leaves this assignment
+	// +optional
+	ReassignmentPolicy NodeAssignmentReassignmentPolicy `json:"reassignmentPolicy,omitempty"`
+
+	// DrainTimeoutSeconds is the longest time to wait for the pods of a node to be evicted when the
+	// ReassignmentPolicy is Drain
+	// +optional
+	DrainTimeoutSeconds int64 `json:"drainTimeoutSeconds,omitempty"`
+
+	// SchedulingMode determines what kind of scheduling alteration to use on the assignment
+	// +optional
+	SchedulingMode NodeAssignmentSchedulingMode `json:"schedulingMode,omitempty"`
+
+	// PackLeft holds configuration options that are only used when the SchedulingMode is "PackLeft"
+	// +optional
+	PackLeft *PackLeftScheduling `json:"packLeft,omitempty"`
+
+	// TopologySpread balances the nodes of the assignment across the values of a node label
+	// +optional
+	TopologySpread *NodeAssignmentTopologySpread `json:"topologySpread,omitempty"`
+
+	// Requirements limits the assignment to nodes that are able to host it
+	// +optional
+	Requirements *NodeAssignmentRequirements `json:"requirements,omitempty"`
+}
+
+// NodeAssignmentPercentRounding defines how a percentage of nodes is rounded to a whole number
+// +k8s:openapi-gen=true
+type NodeAssignmentPercentRounding string
+
+const (
+	// NodeAssignmentPercentRoundingFloor rounds down
+	NodeAssignmentPercentRoundingFloor NodeAssignmentPercentRounding = "Floor"
+
+	// NodeAssignmentPercentRoundingCeil rounds up
+	NodeAssignmentPercentRoundingCeil NodeAssignmentPercentRounding = "Ceil"
+
+	// NodeAssignmentPercentRoundingNearest rounds to the nearest whole node
+	NodeAssignmentPercentRoundingNearest NodeAssignmentPercentRounding = "Nearest"
+)
+
+// NodeAssignmentReassignmentPolicy defines how a node is prepared before it leaves an assignment
+// +k8s:openapi-gen=true
+type NodeAssignmentReassignmentPolicy string
+
+const (
+	// NodeAssignmentReassignmentPolicyImmediate moves the node right away
+	NodeAssignmentReassignmentPolicyImmediate NodeAssignmentReassignmentPolicy = "Immediate"
+
+	// NodeAssignmentReassignmentPolicyDrain cordons the node and evicts its pods before it is moved
+	NodeAssignmentReassignmentPolicyDrain NodeAssignmentReassignmentPolicy = "Drain"
+)
+
+// NodeAssignmentRequirements describes the nodes that are eligible for an assignment. All given fields must match.
+// +k8s:openapi-gen=true
+type NodeAssignmentRequirements struct {
+	// MinAllocatable is the minimum amount of each allocatable resource a node must have
+	// +optional
+	MinAllocatable corev1.ResourceList `json:"minAllocatable,omitempty"`
+
+	// NodeSelector is a label selector the node must match
+	// +optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+
+	// ExcludeNotReady excludes nodes whose Ready condition is not True
+	// +optional
+	ExcludeNotReady bool `json:"excludeNotReady,omitempty"`
+
+	// ExcludeUnschedulable excludes cordoned nodes
+	// +optional
+	ExcludeUnschedulable bool `json:"excludeUnschedulable,omitempty"`
+
+	// MinAgeSeconds excludes nodes that were created less than this many seconds ago
+	// +optional
+	MinAgeSeconds int64 `json:"minAgeSeconds,omitempty"`
+}
+
+// NodeAssignmentTopologySpread holds configuration for spreading an assignment across topology domains
+// +k8s:openapi-gen=true
+type NodeAssignmentTopologySpread struct {
+	// TopologyKey is the node label whose values are the domains to spread across
+	TopologyKey string `json:"topologyKey"`
+
+	// MaxSkew is the largest allowed difference between the number of assigned nodes in any two domains
+	// +optional
+	MaxSkew int32 `json:"maxSkew,omitempty"`
+}
+
+// PackLeftScheduling holds configuration for PackLeft assignments
+// +k8s:openapi-gen=true
+type PackLeftScheduling struct {
+	// FullPercent defines percent of the Metric that must be used for a node to be considered "Full"
+	// +optional
+	FullPercent *int32 `json:"fullPercent,omitempty"`
+
+	// FullExitPercent is the percent of the Metric a node in the "Use" state has to drop to before it is no longer
+	// considered "Full". Keeping it below FullPercent stops nodes that hover around FullPercent from flapping. When not set it
+	// is FullPercent
+	// +optional
+	FullExitPercent *int32 `json:"fullExitPercent,omitempty"`
+
+	// MinDwellSeconds is the least time a node keeps its state before it can be moved to a more restrictive state.
+	// Moves to a less restrictive state are never held back, so room for new pods is made right away. Default: 0
+	// +optional
+	MinDwellSeconds int64 `json:"minDwellSeconds,omitempty"`
+
+	// NumAvoid indicates the number of nodes to be set to "Avoid" for the given assignment
+	// +optional
+	NumAvoid int32 `json:"numAvoid,omitempty"`
+
+	// PercentAvoid indicates a percentage of nodes to be set to "Avoid" for the given assignment.
+	// When specified along with NumAvoid, whichever request results in the most nodes is used
+	// +optional
+	PercentAvoid *int32 `json:"percentAvoid,omitempty"`
+
+	// Resources are the node resources that decide how full a node is. Default: cpu and memory
+	// +optional
+	Resources []PackLeftResource `json:"resources,omitempty"`
+
+	// Aggregation decides how the percent full of each resource is combined into the percent full of the node. Default: Max
+	// +optional
+	Aggregation PackLeftAggregation `json:"aggregation,omitempty"`
+}
+
+// PackLeftResource is a node resource that counts towards how full a node is
+// +k8s:openapi-gen=true
+type PackLeftResource struct {
+	// Name of the resource. Requests of cpu, memory, ephemeral-storage and extended resources are compared to the
+	// allocatable amount of the node. "pods" compares the number of pods to the allocatable pods of the node
+	Name corev1.ResourceName `json:"name"`
+
+	// Weight of the resource in the WeightedAverage aggregation. 0 leaves the resource out of the average. Default: 1
+	// +optional
+	Weight *int32 `json:"weight,omitempty"`
+}
+
+// PackLeftAggregation defines how the percent full of several resources is combined
+// +k8s:openapi-gen=true
+type PackLeftAggregation string
+
+const (
+	// PackLeftAggregationMax uses the percent full of the fullest resource
+	PackLeftAggregationMax PackLeftAggregation = "Max"
+
+	// PackLeftAggregationWeightedAverage uses the weighted average of the percent full of all resources
+	PackLeftAggregationWeightedAverage PackLeftAggregation = "WeightedAverage"
+)
+
+// NodeAssignmentMode defines the operation mode of the assignment
+// +k8s:openapi-gen=true
+type NodeAssignmentMode string
+
+const (
+	// NodeAssignmentModeLabelOnly tells the system to only apply labels to the node
+	NodeAssignmentModeLabelOnly NodeAssignmentMode = "LabelOnly"
+
+	// NodeAssignmentModeLabelAndTaint tells the system to apply both labels and taints for the assignment
+	NodeAssignmentModeLabelAndTaint NodeAssignmentMode = "LabelAndTaint"
+
+	// NodeAssignmentModeTaintOnly tells the system to only apply taints to the node
+	NodeAssignmentModeTaintOnly NodeAssignmentMode = "TaintOnly"
+)
+
+// NodeAssignmentSchedulingMode defines the way to alter scheduling in the assignment
+// +k8s:openapi-gen=true
+type NodeAssignmentSchedulingMode string
+
+const (
+	// NodeAssignmentSchedulingModePackLeft tells the system to run packleft on nodes in the assignment
+	NodeAssignmentSchedulingModePackLeft NodeAssignmentSchedulingMode = "PackLeft"
+)
+
+// NodeAssignmentGroupStatus represents the current status of the group.
+// +k8s:openapi-gen=true
+type NodeAssignmentGroupStatus struct {
+	// ObservedGeneration is the most recent generation of the group that was reconciled by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// NumMatched represents the number of nodes that matched the node selectors
+	// +optional
+	NumMatched int32 `json:"numMatched,omitempty"`
+
+	// NumSatisfied represents the total number of assignments that have all of their desired nodes
+	// +optional
+	NumSatisfied int32 `json:"numSatisfied,omitempty"`
+
+	// State reports the overall health of the group
+	// +optional
+	State NodeAssignmentGroupState `json:"state,omitempty"`
+
+	// Assignments reports the satisfaction for each assignment
+	// +optional
+	// +patchMergeKey=name
+	// +patchStrategy=merge
+	Assignments []NodeAssignmentStatus `json:"assignments,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+
+	// Rollout reports the progress of the rollout when the group has a RolloutStrategy
+	// +optional
+	Rollout *NodeAssignmentGroupRolloutStatus `json:"rollout,omitempty"`
+
+	// Conditions represent the latest available observations of the group's state
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []NodeAssignmentGroupCondition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// NodeAssignmentGroupRolloutStatus reports the progress of a rollout
+// +k8s:openapi-gen=true
+type NodeAssignmentGroupRolloutStatus struct {
+	// NumPending is the number of nodes whose assignment still has to be changed
+	NumPending int32 `json:"numPending"`
+
+	// NumChanged is the number of nodes whose assignment was changed in the last wave
+	NumChanged int32 `json:"numChanged"`
+
+	// LastWaveTime is when the last wave of changes was made
+	// +optional
+	LastWaveTime *metav1.Time `json:"lastWaveTime,omitempty"`
+}
+
+// NodeAssignmentGroupState reports the overall health of the group
+// +k8s:openapi-gen=true
+type NodeAssignmentGroupState string
+
+const (
+	// NodeAssignmentGroupStateSatisfied means that the group
+	// has matched and operated on enough nodes to satisfy all assignments
+	NodeAssignmentGroupStateSatisfied NodeAssignmentGroupState = "Satisfied"
+
+	// NodeAssignmentGroupStateNotSatisfied means that the group
+	// did not find enough nodes to satisfy all assignments
+	NodeAssignmentGroupStateNotSatisfied NodeAssignmentGroupState = "NotSatisfied"
+
+	// NodeAssignmentGroupStateError means that the controller
+	// was unable to process the group properly
+	NodeAssignmentGroupStateError NodeAssignmentGroupState = "Error"
+)
+
+// NodeAssignmentStatus reports the satisfaction of a single assignment
+// +k8s:openapi-gen=true
+type NodeAssignmentStatus struct {
+	// Name is the name of the assignment
+	Name string `json:"name"`
+
+	// NumDesired represents the number of nodes that the assignment requested.
+	// For the default assignment this is the number of matched nodes left over after all other assignments
+	NumDesired int32 `json:"numDesired"`
+
+	// NumAssigned represents the number of nodes that were assigned
+	NumAssigned int32 `json:"numAssigned"`
+
+	// MinNodes is the lower bound that was applied to NumDesired
+	// +optional
+	MinNodes int32 `json:"minNodes,omitempty"`
+
+	// MaxNodes is the upper bound that was applied to NumDesired
+	// +optional
+	MaxNodes *int32 `json:"maxNodes,omitempty"`
+}
+
+// NodeAssignmentGroupConditionType is a valid value for NodeAssignmentGroupCondition.Type
+// +k8s:openapi-gen=true
+type NodeAssignmentGroupConditionType string
+
+const (
+	// NodeAssignmentGroupConditionReconciled is true when the last reconcile of the group completed without errors
+	NodeAssignmentGroupConditionReconciled NodeAssignmentGroupConditionType = "Reconciled"
+
+	// NodeAssignmentGroupConditionSatisfied is true when every assignment in the group has all of the nodes it desires
+	NodeAssignmentGroupConditionSatisfied NodeAssignmentGroupConditionType = "Satisfied"
+)
+
+// NodeAssignmentGroupCondition describes the state of a group at a certain point
+// +k8s:openapi-gen=true
+type NodeAssignmentGroupCondition struct {
+	// Type of the condition
+	Type NodeAssignmentGroupConditionType `json:"type"`
+
+	// Status of the condition, one of True, False, Unknown
+	Status metav1.ConditionStatus `json:"status"`
+
+	// ObservedGeneration is the generation of the group that the condition was set for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LastTransitionTime is the last time the condition changed from one status to another
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// Reason is a brief CamelCase reason for the condition's last transition
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human readable message indicating details about the last transition
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// generation tags. The empty line after is IMPORTANT!
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NodeAssignmentGroupList is a list of NodeAssignmentGroups
+// +k8s:openapi-gen=true
+type NodeAssignmentGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is a list of NodeAssignmentGroups
+	Items []NodeAssignmentGroup `json:"items"`
+}
+
+// -------------------------------------------------------------------------------- PodAssignmentRule
+// generation tags. The empty line after is IMPORTANT!
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PodAssignmentRule describes pods to match and attributes to apply to them
+// +k8s:openapi-gen=true
+type PodAssignmentRule struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the behavior of the rule
+	Spec PodAssignmentRuleSpec `json:"spec"`
+}
+
+// PodAssignmentRuleSpec defines the behavior of the PodAssignmentRule
+// +k8s:openapi-gen=true
+type PodAssignmentRuleSpec struct {
+	// PodSelector defines which pods this rule will be applied to. When not given, the rule will match all pods.
+	// +optional
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+
+	// Priority orders the rules that match a pod. Rules are applied from the lowest to the highest priority
+	// so that higher priority rules take precedence.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// StopProcessing prevents any matching rule that would be applied before this one from being applied
+	// +optional
+	StopProcessing bool `json:"stopProcessing,omitempty"`
+
+	// Scheduling defines the scheduling objects to be applied to the pod
+	Scheduling PodAssignmentRuleScheduling `json:"scheduling"`
+}
+
+// PodAssignmentRuleScheduling defines the scheduling objects to be applied to the pod
+// +k8s:openapi-gen=true
+type PodAssignmentRuleScheduling struct {
+	// MergeStrategy defines the behavior of the rule when pods already have existing
+	// scheduling details defined
+	// +optional
+	MergeStrategy PodAssignmentRuleSchedulingMergeStrategy `json:"mergeStrategy,omitempty"`
+
+	// NodeSelector is a simple key-value matching for nodes
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Affinity is the upstream pod affinity resource
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// Tolerations is a list of upstream pod toleration resources
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+// PodAssignmentRuleSchedulingMergeStrategy defines the behavior of the rule when pods already have existing
+// scheduling details defined
+// +k8s:openapi-gen=true
+type PodAssignmentRuleSchedulingMergeStrategy string
+
+const (
+	// PodAssignmentRuleSchedulingMergeStrategyOverwriteAll tells the system to overwrite
+	// any scheduling details in the pod with the details in the rule
+	PodAssignmentRuleSchedulingMergeStrategyOverwriteAll PodAssignmentRuleSchedulingMergeStrategy = "OverwriteAll"
+
+	// PodAssignmentRuleSchedulingMergeStrategyMergeAppend tells the system to merge the details in the rule
+	// with those in the pod
+	PodAssignmentRuleSchedulingMergeStrategyMergeAppend PodAssignmentRuleSchedulingMergeStrategy = "MergeAppend"
+
+	// PodAssignmentRuleSchedulingMergeStrategyKeepExisting tells the system to only fill in scheduling
+	// details that are empty in the pod
+	PodAssignmentRuleSchedulingMergeStrategyKeepExisting PodAssignmentRuleSchedulingMergeStrategy = "KeepExisting"
+
+	// PodAssignmentRuleSchedulingMergeStrategyReject tells the system to deny pods whose required scheduling
+	// details contradict the rule. Preferences never conflict. Pods without conflicts are merged like MergeAppend.
+	PodAssignmentRuleSchedulingMergeStrategyReject PodAssignmentRuleSchedulingMergeStrategy = "Reject"
+)
+
+// generation tags. The empty line after is IMPORTANT!
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PodAssignmentRuleList is a list of PodAssignmentRules
+// +k8s:openapi-gen=true
+type PodAssignmentRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is a list of PodAssignmentRules
+	Items []PodAssignmentRule `json:"items"`
+}
+
+// -------------------------------------------------------------------------------- ClusterPodAssignmentRule
+// generation tags. The empty line after is IMPORTANT!
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterPodAssignmentRule defines PodAssignmentRules that are applied cluster-wide
+// +k8s:openapi-gen=true
+type ClusterPodAssignmentRule struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the behavior of the rule
+	Spec ClusterPodAssignmentRuleSpec `json:"spec"`
+}
+
+// ClusterPodAssignmentRuleSpec defines the behavior of the ClusterPodAssignmentRule
+// +k8s:openapi-gen=true
+type ClusterPodAssignmentRuleSpec struct {
+	PodAssignmentRuleSpec `json:",inline"`
+
+	// NamespaceSelector is optional. It limits the rule to pods in namespaces whose labels match.
+	// When not given, the rule will match pods in all namespaces.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// generation tags. The empty line after is IMPORTANT!
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterPodAssignmentRuleList is a list of ClusterPodAssignmentRules
+// +k8s:openapi-gen=true
+type ClusterPodAssignmentRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is a list of ClusterPodAssignmentRules
+	Items []ClusterPodAssignmentRule `json:"items"`
+}
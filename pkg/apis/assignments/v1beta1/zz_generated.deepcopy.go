@@ -0,0 +1,632 @@
+// +build !ignore_autogenerated
+
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by deepcopy-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterPodAssignmentRule) DeepCopyInto(out *ClusterPodAssignmentRule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPodAssignmentRule.
+func (in *ClusterPodAssignmentRule) DeepCopy() *ClusterPodAssignmentRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterPodAssignmentRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterPodAssignmentRule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterPodAssignmentRuleList) DeepCopyInto(out *ClusterPodAssignmentRuleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterPodAssignmentRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPodAssignmentRuleList.
+func (in *ClusterPodAssignmentRuleList) DeepCopy() *ClusterPodAssignmentRuleList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterPodAssignmentRuleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterPodAssignmentRuleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterPodAssignmentRuleSpec) DeepCopyInto(out *ClusterPodAssignmentRuleSpec) {
+	*out = *in
+	in.PodAssignmentRuleSpec.DeepCopyInto(&out.PodAssignmentRuleSpec)
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPodAssignmentRuleSpec.
+func (in *ClusterPodAssignmentRuleSpec) DeepCopy() *ClusterPodAssignmentRuleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterPodAssignmentRuleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAssignment) DeepCopyInto(out *NodeAssignment) {
+	*out = *in
+	if in.ExtraLabels != nil {
+		in, out := &in.ExtraLabels, &out.ExtraLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExtraTaints != nil {
+		in, out := &in.ExtraTaints, &out.ExtraTaints
+		*out = make([]corev1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MinNodes != nil {
+		in, out := &in.MinNodes, &out.MinNodes
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxNodes != nil {
+		in, out := &in.MaxNodes, &out.MaxNodes
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PackLeft != nil {
+		in, out := &in.PackLeft, &out.PackLeft
+		*out = new(PackLeftScheduling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpread != nil {
+		in, out := &in.TopologySpread, &out.TopologySpread
+		*out = new(NodeAssignmentTopologySpread)
+		**out = **in
+	}
+	if in.Requirements != nil {
+		in, out := &in.Requirements, &out.Requirements
+		*out = new(NodeAssignmentRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAssignment.
+func (in *NodeAssignment) DeepCopy() *NodeAssignment {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAssignment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAssignmentGroup) DeepCopyInto(out *NodeAssignmentGroup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAssignmentGroup.
+func (in *NodeAssignmentGroup) DeepCopy() *NodeAssignmentGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAssignmentGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeAssignmentGroup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAssignmentGroupCondition) DeepCopyInto(out *NodeAssignmentGroupCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAssignmentGroupCondition.
+func (in *NodeAssignmentGroupCondition) DeepCopy() *NodeAssignmentGroupCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAssignmentGroupCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAssignmentGroupList) DeepCopyInto(out *NodeAssignmentGroupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NodeAssignmentGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAssignmentGroupList.
+func (in *NodeAssignmentGroupList) DeepCopy() *NodeAssignmentGroupList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAssignmentGroupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeAssignmentGroupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAssignmentGroupRolloutStatus) DeepCopyInto(out *NodeAssignmentGroupRolloutStatus) {
+	*out = *in
+	if in.LastWaveTime != nil {
+		in, out := &in.LastWaveTime, &out.LastWaveTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAssignmentGroupRolloutStatus.
+func (in *NodeAssignmentGroupRolloutStatus) DeepCopy() *NodeAssignmentGroupRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAssignmentGroupRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAssignmentGroupRolloutStrategy) DeepCopyInto(out *NodeAssignmentGroupRolloutStrategy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAssignmentGroupRolloutStrategy.
+func (in *NodeAssignmentGroupRolloutStrategy) DeepCopy() *NodeAssignmentGroupRolloutStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAssignmentGroupRolloutStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAssignmentGroupSpec) DeepCopyInto(out *NodeAssignmentGroupSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeFieldSelector != nil {
+		in, out := &in.NodeFieldSelector, &out.NodeFieldSelector
+		*out = new(NodeFieldSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DefaultAssignment != nil {
+		in, out := &in.DefaultAssignment, &out.DefaultAssignment
+		*out = new(NodeAssignment)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Assignments != nil {
+		in, out := &in.Assignments, &out.Assignments
+		*out = make([]NodeAssignment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RolloutStrategy != nil {
+		in, out := &in.RolloutStrategy, &out.RolloutStrategy
+		*out = new(NodeAssignmentGroupRolloutStrategy)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAssignmentGroupSpec.
+func (in *NodeAssignmentGroupSpec) DeepCopy() *NodeAssignmentGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAssignmentGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAssignmentGroupStatus) DeepCopyInto(out *NodeAssignmentGroupStatus) {
+	*out = *in
+	if in.Assignments != nil {
+		in, out := &in.Assignments, &out.Assignments
+		*out = make([]NodeAssignmentStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(NodeAssignmentGroupRolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]NodeAssignmentGroupCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAssignmentGroupStatus.
+func (in *NodeAssignmentGroupStatus) DeepCopy() *NodeAssignmentGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAssignmentGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAssignmentRequirements) DeepCopyInto(out *NodeAssignmentRequirements) {
+	*out = *in
+	if in.MinAllocatable != nil {
+		in, out := &in.MinAllocatable, &out.MinAllocatable
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAssignmentRequirements.
+func (in *NodeAssignmentRequirements) DeepCopy() *NodeAssignmentRequirements {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAssignmentRequirements)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAssignmentStatus) DeepCopyInto(out *NodeAssignmentStatus) {
+	*out = *in
+	if in.MaxNodes != nil {
+		in, out := &in.MaxNodes, &out.MaxNodes
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAssignmentStatus.
+func (in *NodeAssignmentStatus) DeepCopy() *NodeAssignmentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAssignmentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAssignmentTopologySpread) DeepCopyInto(out *NodeAssignmentTopologySpread) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAssignmentTopologySpread.
+func (in *NodeAssignmentTopologySpread) DeepCopy() *NodeAssignmentTopologySpread {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAssignmentTopologySpread)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFieldSelector) DeepCopyInto(out *NodeFieldSelector) {
+	*out = *in
+	if in.Unschedulable != nil {
+		in, out := &in.Unschedulable, &out.Unschedulable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.TaintsPresent != nil {
+		in, out := &in.TaintsPresent, &out.TaintsPresent
+		*out = make([]corev1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TaintsAbsent != nil {
+		in, out := &in.TaintsAbsent, &out.TaintsAbsent
+		*out = make([]corev1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFieldSelector.
+func (in *NodeFieldSelector) DeepCopy() *NodeFieldSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFieldSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackLeftResource) DeepCopyInto(out *PackLeftResource) {
+	*out = *in
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackLeftResource.
+func (in *PackLeftResource) DeepCopy() *PackLeftResource {
+	if in == nil {
+		return nil
+	}
+	out := new(PackLeftResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackLeftScheduling) DeepCopyInto(out *PackLeftScheduling) {
+	*out = *in
+	if in.FullPercent != nil {
+		in, out := &in.FullPercent, &out.FullPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FullExitPercent != nil {
+		in, out := &in.FullExitPercent, &out.FullExitPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PercentAvoid != nil {
+		in, out := &in.PercentAvoid, &out.PercentAvoid
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]PackLeftResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackLeftScheduling.
+func (in *PackLeftScheduling) DeepCopy() *PackLeftScheduling {
+	if in == nil {
+		return nil
+	}
+	out := new(PackLeftScheduling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodAssignmentRule) DeepCopyInto(out *PodAssignmentRule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodAssignmentRule.
+func (in *PodAssignmentRule) DeepCopy() *PodAssignmentRule {
+	if in == nil {
+		return nil
+	}
+	out := new(PodAssignmentRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PodAssignmentRule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodAssignmentRuleList) DeepCopyInto(out *PodAssignmentRuleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PodAssignmentRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodAssignmentRuleList.
+func (in *PodAssignmentRuleList) DeepCopy() *PodAssignmentRuleList {
+	if in == nil {
+		return nil
+	}
+	out := new(PodAssignmentRuleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PodAssignmentRuleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodAssignmentRuleScheduling) DeepCopyInto(out *PodAssignmentRuleScheduling) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodAssignmentRuleScheduling.
+func (in *PodAssignmentRuleScheduling) DeepCopy() *PodAssignmentRuleScheduling {
+	if in == nil {
+		return nil
+	}
+	out := new(PodAssignmentRuleScheduling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodAssignmentRuleSpec) DeepCopyInto(out *PodAssignmentRuleSpec) {
+	*out = *in
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Scheduling.DeepCopyInto(&out.Scheduling)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodAssignmentRuleSpec.
+func (in *PodAssignmentRuleSpec) DeepCopy() *PodAssignmentRuleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PodAssignmentRuleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
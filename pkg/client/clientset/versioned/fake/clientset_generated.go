@@ -22,6 +22,8 @@ import (
 	clientset "github.com/domoinc/kube-valet/pkg/client/clientset/versioned"
 	assignmentsv1alpha1 "github.com/domoinc/kube-valet/pkg/client/clientset/versioned/typed/assignments/v1alpha1"
 	fakeassignmentsv1alpha1 "github.com/domoinc/kube-valet/pkg/client/clientset/versioned/typed/assignments/v1alpha1/fake"
+	assignmentsv1beta1 "github.com/domoinc/kube-valet/pkg/client/clientset/versioned/typed/assignments/v1beta1"
+	fakeassignmentsv1beta1 "github.com/domoinc/kube-valet/pkg/client/clientset/versioned/typed/assignments/v1beta1/fake"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
@@ -80,3 +82,8 @@ var _ clientset.Interface = &Clientset{}
 func (c *Clientset) AssignmentsV1alpha1() assignmentsv1alpha1.AssignmentsV1alpha1Interface {
 	return &fakeassignmentsv1alpha1.FakeAssignmentsV1alpha1{Fake: &c.Fake}
 }
+
+// AssignmentsV1beta1 retrieves the AssignmentsV1beta1Client
+func (c *Clientset) AssignmentsV1beta1() assignmentsv1beta1.AssignmentsV1beta1Interface {
+	return &fakeassignmentsv1beta1.FakeAssignmentsV1beta1{Fake: &c.Fake}
+}
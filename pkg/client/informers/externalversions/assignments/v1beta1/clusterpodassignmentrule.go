@@ -0,0 +1,88 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	time "time"
+
+	assignmentsv1beta1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1beta1"
+	versioned "github.com/domoinc/kube-valet/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/domoinc/kube-valet/pkg/client/informers/externalversions/internalinterfaces"
+	v1beta1 "github.com/domoinc/kube-valet/pkg/client/listers/assignments/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// ClusterPodAssignmentRuleInformer provides access to a shared informer and lister for
+// ClusterPodAssignmentRules.
+type ClusterPodAssignmentRuleInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1beta1.ClusterPodAssignmentRuleLister
+}
+
+type clusterPodAssignmentRuleInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewClusterPodAssignmentRuleInformer constructs a new informer for ClusterPodAssignmentRule type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewClusterPodAssignmentRuleInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredClusterPodAssignmentRuleInformer(client, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredClusterPodAssignmentRuleInformer constructs a new informer for ClusterPodAssignmentRule type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredClusterPodAssignmentRuleInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.AssignmentsV1beta1().ClusterPodAssignmentRules().List(options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.AssignmentsV1beta1().ClusterPodAssignmentRules().Watch(options)
+			},
+		},
+		&assignmentsv1beta1.ClusterPodAssignmentRule{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *clusterPodAssignmentRuleInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredClusterPodAssignmentRuleInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *clusterPodAssignmentRuleInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&assignmentsv1beta1.ClusterPodAssignmentRule{}, f.defaultInformer)
+}
+
+func (f *clusterPodAssignmentRuleInformer) Lister() v1beta1.ClusterPodAssignmentRuleLister {
+	return v1beta1.NewClusterPodAssignmentRuleLister(f.Informer().GetIndexer())
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	internalinterfaces "github.com/domoinc/kube-valet/pkg/client/informers/externalversions/internalinterfaces"
+)
+
+// Interface provides access to all the informers in this group version.
+type Interface interface {
+	// ClusterPodAssignmentRules returns a ClusterPodAssignmentRuleInformer.
+	ClusterPodAssignmentRules() ClusterPodAssignmentRuleInformer
+	// NodeAssignmentGroups returns a NodeAssignmentGroupInformer.
+	NodeAssignmentGroups() NodeAssignmentGroupInformer
+	// PodAssignmentRules returns a PodAssignmentRuleInformer.
+	PodAssignmentRules() PodAssignmentRuleInformer
+}
+
+type version struct {
+	factory          internalinterfaces.SharedInformerFactory
+	namespace        string
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// New returns a new Interface.
+func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakListOptions internalinterfaces.TweakListOptionsFunc) Interface {
+	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
+}
+
+// ClusterPodAssignmentRules returns a ClusterPodAssignmentRuleInformer.
+func (v *version) ClusterPodAssignmentRules() ClusterPodAssignmentRuleInformer {
+	return &clusterPodAssignmentRuleInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// NodeAssignmentGroups returns a NodeAssignmentGroupInformer.
+func (v *version) NodeAssignmentGroups() NodeAssignmentGroupInformer {
+	return &nodeAssignmentGroupInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// PodAssignmentRules returns a PodAssignmentRuleInformer.
+func (v *version) PodAssignmentRules() PodAssignmentRuleInformer {
+	return &podAssignmentRuleInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	time "time"
+
+	assignmentsv1beta1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1beta1"
+	versioned "github.com/domoinc/kube-valet/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/domoinc/kube-valet/pkg/client/informers/externalversions/internalinterfaces"
+	v1beta1 "github.com/domoinc/kube-valet/pkg/client/listers/assignments/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// NodeAssignmentGroupInformer provides access to a shared informer and lister for
+// NodeAssignmentGroups.
+type NodeAssignmentGroupInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1beta1.NodeAssignmentGroupLister
+}
+
+type nodeAssignmentGroupInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewNodeAssignmentGroupInformer constructs a new informer for NodeAssignmentGroup type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewNodeAssignmentGroupInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredNodeAssignmentGroupInformer(client, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredNodeAssignmentGroupInformer constructs a new informer for NodeAssignmentGroup type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredNodeAssignmentGroupInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.AssignmentsV1beta1().NodeAssignmentGroups().List(options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.AssignmentsV1beta1().NodeAssignmentGroups().Watch(options)
+			},
+		},
+		&assignmentsv1beta1.NodeAssignmentGroup{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *nodeAssignmentGroupInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredNodeAssignmentGroupInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *nodeAssignmentGroupInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&assignmentsv1beta1.NodeAssignmentGroup{}, f.defaultInformer)
+}
+
+func (f *nodeAssignmentGroupInformer) Lister() v1beta1.NodeAssignmentGroupLister {
+	return v1beta1.NewNodeAssignmentGroupLister(f.Informer().GetIndexer())
+}
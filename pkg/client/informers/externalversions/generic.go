@@ -22,6 +22,7 @@ import (
 	"fmt"
 
 	v1alpha1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1alpha1"
+	v1beta1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1beta1"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 )
@@ -60,6 +61,14 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case v1alpha1.SchemeGroupVersion.WithResource("podassignmentrules"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Assignments().V1alpha1().PodAssignmentRules().Informer()}, nil
 
+		// Group=assignments.kube-valet.io, Version=v1beta1
+	case v1beta1.SchemeGroupVersion.WithResource("clusterpodassignmentrules"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Assignments().V1beta1().ClusterPodAssignmentRules().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("nodeassignmentgroups"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Assignments().V1beta1().NodeAssignmentGroups().Informer()}, nil
+	case v1beta1.SchemeGroupVersion.WithResource("podassignmentrules"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Assignments().V1beta1().PodAssignmentRules().Informer()}, nil
+
 	}
 
 	return nil, fmt.Errorf("no informer found for %v", resource)
@@ -0,0 +1,65 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	v1beta1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ClusterPodAssignmentRuleLister helps list ClusterPodAssignmentRules.
+type ClusterPodAssignmentRuleLister interface {
+	// List lists all ClusterPodAssignmentRules in the indexer.
+	List(selector labels.Selector) (ret []*v1beta1.ClusterPodAssignmentRule, err error)
+	// Get retrieves the ClusterPodAssignmentRule from the index for a given name.
+	Get(name string) (*v1beta1.ClusterPodAssignmentRule, error)
+	ClusterPodAssignmentRuleListerExpansion
+}
+
+// clusterPodAssignmentRuleLister implements the ClusterPodAssignmentRuleLister interface.
+type clusterPodAssignmentRuleLister struct {
+	indexer cache.Indexer
+}
+
+// NewClusterPodAssignmentRuleLister returns a new ClusterPodAssignmentRuleLister.
+func NewClusterPodAssignmentRuleLister(indexer cache.Indexer) ClusterPodAssignmentRuleLister {
+	return &clusterPodAssignmentRuleLister{indexer: indexer}
+}
+
+// List lists all ClusterPodAssignmentRules in the indexer.
+func (s *clusterPodAssignmentRuleLister) List(selector labels.Selector) (ret []*v1beta1.ClusterPodAssignmentRule, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1beta1.ClusterPodAssignmentRule))
+	})
+	return ret, err
+}
+
+// Get retrieves the ClusterPodAssignmentRule from the index for a given name.
+func (s *clusterPodAssignmentRuleLister) Get(name string) (*v1beta1.ClusterPodAssignmentRule, error) {
+	obj, exists, err := s.indexer.GetByKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1beta1.Resource("clusterpodassignmentrule"), name)
+	}
+	return obj.(*v1beta1.ClusterPodAssignmentRule), nil
+}
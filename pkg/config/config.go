@@ -1,12 +1,26 @@
 package config
 
-import logging "github.com/op/go-logging"
+import (
+	"time"
+
+	logging "github.com/op/go-logging"
+	"k8s.io/client-go/tools/record"
+)
 
 type ValetConfig struct {
 	ParController  ControllerConfig
 	NagController  ControllerConfig
 	PLController   ControllerConfig
 	LoggingBackend logging.LeveledBackend
+	EventRecorder  record.EventRecorder
+	// Identity names this replica in the provenance annotations it writes to nodes
+	Identity string
+	// DryRun makes the NodeAssignmentGroup and pack left controllers log and record events for the node changes they
+	// would make instead of making them
+	DryRun bool
+	// PackLeftCoalesceWindow is how long pod and node changes are collected before the nags they affect are
+	// rebalanced. Zero rebalances on every change
+	PackLeftCoalesceWindow time.Duration
 }
 
 type ControllerConfig struct {
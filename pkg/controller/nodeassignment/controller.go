@@ -9,6 +9,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 )
 
 //Controller listens for changes to NodeAssignmentGroups and Nodes to reset allocation of nodes
@@ -20,12 +21,12 @@ type Controller struct {
 }
 
 //NewController creates a new Controller
-func NewController(nagIndex cache.Indexer, nodeIndex cache.Indexer, kubeClient kubernetes.Interface, valetClient valet.Interface, threadiness int, stopChannel chan struct{}) *Controller {
+func NewController(nagIndex cache.Indexer, nodeIndex cache.Indexer, podIndex cache.Indexer, kubeClient kubernetes.Interface, valetClient valet.Interface, recorder record.EventRecorder, identity string, dryRun bool, threadiness int, stopChannel chan struct{}) *Controller {
 	return &Controller{
 		queue:    queues.NewRetryingWorkQueue("NodeAssignmentGroup", nagIndex, threadiness, stopChannel),
 		log:      logging.MustGetLogger("NodeAssignmentController"),
 		nagIndex: nagIndex,
-		nagm:     NewManager(kubeClient, valetClient),
+		nagm:     NewManager(nodeIndex, podIndex, kubeClient, valetClient, recorder, identity, dryRun),
 	}
 }
 
@@ -34,32 +35,36 @@ func (c *Controller) Run() {
 	c.queue.Run(func(obj interface{}) error {
 		nag := obj.(*assignmentsv1alpha1.NodeAssignmentGroup)
 		c.log.Debugf("processing business logic for nag %s", nag.Name)
-		err := c.nagm.ReconcileNag(nag)
+		requeueAfter, err := c.nagm.ReconcileNag(nag)
+		if err == nil && requeueAfter > 0 {
+			c.log.Debugf("requeueing nag %s in %s", nag.Name, requeueAfter)
+			c.queue.AddItemAfter(nag, requeueAfter)
+		}
 		return err
 	})
 }
 
-// OnAddNode queue all nags for processing.
+// OnAddNode queues the nags that target or are assigned to the added node
 func (c *Controller) OnAddNode(node *corev1.Node) {
-	c.log.Debugf("NodeAssignment: Node %s added. Requeueing all Nags", node.GetName())
-	c.queueAllNags()
+	c.log.Debugf("NodeAssignment: Node %s added. Requeueing its Nags", node.GetName())
+	c.queueNodeNags(node)
 }
 
-// OnUpdateNode recalculates all nags if targeting attributes have changed
+// OnUpdateNode recalculates the nags of the node if targeting or eligibility attributes have changed
 func (c *Controller) OnUpdateNode(oldNode *corev1.Node, newNode *corev1.Node) {
-	// Only trigger on changes to targetable attributes. This avoids excessive churn due to node status updates
-	if utils.NodeTargetingHasChanged(oldNode, newNode) {
-		c.log.Debugf("NodeAssignment: Node %s has updated targetable attributes. Requeueing all Nags", oldNode.GetName())
-		c.queueAllNags()
+	// Only trigger on changes to targetable attributes, readiness and allocatable resources. This avoids excessive
+	// churn due to node status updates
+	if utils.NodeTargetingHasChanged(oldNode, newNode) || utils.NodeEligibilityHasChanged(oldNode, newNode) {
+		c.log.Debugf("NodeAssignment: Node %s has updated targetable attributes. Requeueing its Nags", oldNode.GetName())
+		// Nags matching either version need to be reconciled. The queue dedupes nags matching both
+		c.queueNodeNags(oldNode)
+		c.queueNodeNags(newNode)
 	}
 }
 
 // OnDeleteNode when a node is deleted process the applicable nag
 func (c *Controller) OnDeleteNode(node *corev1.Node) {
-	nags := c.getNodeNags(node)
-	for _, nag := range nags {
-		c.OnAddNag(nag)
-	}
+	c.queueNodeNags(node)
 }
 
 // OnAddNag process and added nag
@@ -85,21 +90,22 @@ func (c *Controller) OnDeleteNag(nag *assignmentsv1alpha1.NodeAssignmentGroup) {
 	c.queue.AddItem(nag)
 }
 
+// getNodeNags returns the nags that target the node or still have an assignment on it
 func (c *Controller) getNodeNags(node *corev1.Node) []*assignmentsv1alpha1.NodeAssignmentGroup {
 	var rtn []*assignmentsv1alpha1.NodeAssignmentGroup
 	for _, obj := range c.nagIndex.List() {
 		nag := obj.(*assignmentsv1alpha1.NodeAssignmentGroup)
-		if nag.TargetsNode(node) {
+		_, assigned := nag.GetAssignment(node)
+		if assigned || nag.TargetsNode(node) {
 			rtn = append(rtn, nag.DeepCopy())
 		}
 	}
 	return rtn
 }
 
-// queueAllNags Will queue all nags for reconciliation
-func (c *Controller) queueAllNags() {
-	for _, obj := range c.nagIndex.List() {
-		nag := obj.(*assignmentsv1alpha1.NodeAssignmentGroup)
-		c.queue.AddItem(nag)
+// queueNodeNags queues the nags affected by a change to the node for reconciliation
+func (c *Controller) queueNodeNags(node *corev1.Node) {
+	for _, nag := range c.getNodeNags(node) {
+		c.OnAddNag(nag)
 	}
 }
@@ -0,0 +1,140 @@
+package nodeassignment
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/domoinc/kube-valet/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	assignmentsv1alpha1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1alpha1"
+)
+
+const (
+	// drainPollInterval is how often a group is reconciled while one of its nodes is draining
+	drainPollInterval = 10 * time.Second
+
+	// drainStateDraining is the value of the drain state annotation while pods are being evicted
+	drainStateDraining = "Draining"
+)
+
+// drainAnnotationKey returns the key of a drain annotation for the group.
+// Ex: drain-started.nag.assignments.kube-valet.io/NAGNAME
+func drainAnnotationKey(prefix string, nag *assignmentsv1alpha1.NodeAssignmentGroup) string {
+	return prefix + "." + nag.AssignmentLabelKey()
+}
+
+// isDraining returns true if the group started draining the node
+func (wc *WriterContext) isDraining(node *corev1.Node) bool {
+	_, ok := node.Annotations[drainAnnotationKey("drain", wc.Nag)]
+	return ok
+}
+
+// drainNode prepares a node to leave an assignment with the Drain reassignment policy. The node is cordoned and
+// its pods are evicted. PodDisruptionBudgets are honoured by the Eviction API. True is returned once no pods are
+// left or the drain timed out. Until then the node keeps its assignment and the group is requeued.
+func (wc *WriterContext) drainNode(node *corev1.Node, from *assignmentsv1alpha1.NodeAssignment) (bool, error) {
+	stateKey := drainAnnotationKey("drain", wc.Nag)
+	startedKey := drainAnnotationKey("drain-started", wc.Nag)
+	podsKey := drainAnnotationKey("drain-pods", wc.Nag)
+	cordonedKey := drainAnnotationKey("drain-cordoned", wc.Nag)
+
+	started := wc.now
+	if t, err := time.Parse(time.RFC3339, node.Annotations[startedKey]); err == nil {
+		started = t
+	}
+	deadline := started.Add(time.Duration(from.DrainTimeoutSeconds) * time.Second)
+
+	pods := wc.drainablePods(node.Name)
+	if len(pods) == 0 {
+		wc.log.Infof("%s has been drained and can leave %s", node.ObjectMeta.Name, from.Name)
+		return true, nil
+	}
+	if !wc.now.Before(deadline) {
+		wc.log.Warningf("Timed out draining %s. Moving it out of %s with %d pods left", node.ObjectMeta.Name, from.Name, len(pods))
+		return true, nil
+	}
+
+	// Cordon first so that evicted pods are not scheduled back onto the node
+	_, err := utils.PatchNode(wc.kubeClient, node, func(n *corev1.Node) {
+		if n.Annotations == nil {
+			n.Annotations = make(map[string]string)
+		}
+		if _, ok := n.Annotations[stateKey]; !ok {
+			n.Annotations[startedKey] = started.UTC().Format(time.RFC3339)
+			// Only uncordon nodes later that were not already cordoned by someone else
+			if !n.Spec.Unschedulable {
+				n.Spec.Unschedulable = true
+				n.Annotations[cordonedKey] = "true"
+			}
+		}
+		n.Annotations[stateKey] = drainStateDraining
+		n.Annotations[podsKey] = strconv.Itoa(len(pods))
+	})
+	if err != nil {
+		wc.patchFailed(node, err)
+		return false, err
+	}
+
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		err := wc.kubeClient.PolicyV1beta1().Evictions(pod.Namespace).Evict(&policyv1beta1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+		})
+		switch {
+		case err == nil:
+			wc.log.Debugf("Evicted %s/%s from %s", pod.Namespace, pod.Name, node.ObjectMeta.Name)
+		case errors.IsTooManyRequests(err):
+			wc.log.Infof("Eviction of %s/%s is blocked by a PodDisruptionBudget. Retrying later", pod.Namespace, pod.Name)
+		case errors.IsNotFound(err):
+		default:
+			return false, err
+		}
+	}
+
+	wait := deadline.Sub(wc.now)
+	if wait > drainPollInterval {
+		wait = drainPollInterval
+	}
+	wc.requeueWithin(wait)
+	return false, nil
+}
+
+// clearDrainState removes the drain annotations of the group from the node. The node is uncordoned if the
+// group cordoned it.
+func clearDrainState(nag *assignmentsv1alpha1.NodeAssignmentGroup, node *corev1.Node) {
+	cordonedKey := drainAnnotationKey("drain-cordoned", nag)
+	if node.Annotations[cordonedKey] == "true" {
+		node.Spec.Unschedulable = false
+	}
+	for _, prefix := range []string{"drain", "drain-started", "drain-pods", "drain-cordoned"} {
+		delete(node.Annotations, drainAnnotationKey(prefix, nag))
+	}
+}
+
+// drainablePods returns the pods on the node that have to be evicted. DaemonSet pods, mirror pods and pods
+// that have finished are left alone.
+func (wc *WriterContext) drainablePods(nodeName string) []*corev1.Pod {
+	var pods []*corev1.Pod
+	if wc.podIndex == nil {
+		return pods
+	}
+	for _, pod := range utils.PodsOnNode(wc.podIndex, nodeName) {
+		if !utils.PodHoldsResources(pod) {
+			continue
+		}
+		if _, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+			continue
+		}
+		if ref := metav1.GetControllerOf(pod); ref != nil && ref.Kind == "DaemonSet" {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	return pods
+}
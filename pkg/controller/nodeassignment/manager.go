@@ -1,12 +1,20 @@
 package nodeassignment
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	assignmentsv1alpha1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1alpha1"
 	valet "github.com/domoinc/kube-valet/pkg/client/clientset/versioned"
 	"github.com/domoinc/kube-valet/pkg/utils"
 	logging "github.com/op/go-logging"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 )
 
@@ -15,41 +23,62 @@ const (
 )
 
 type Manager struct {
+	nodeIndex   cache.Indexer
+	podIndex    cache.Indexer
 	kubeClient  kubernetes.Interface
 	valetClient valet.Interface
+	recorder    record.EventRecorder
+	identity    string
+	dryRun      bool
 	log            *logging.Logger
 }
 
-func NewManager(kubeClient kubernetes.Interface, valetClient valet.Interface) *Manager {
+func NewManager(nodeIndex cache.Indexer, podIndex cache.Indexer, kubeClient kubernetes.Interface, valetClient valet.Interface, recorder record.EventRecorder, identity string, dryRun bool) *Manager {
 	return &Manager{
+		nodeIndex:   nodeIndex,
+		podIndex:    podIndex,
 		kubeClient:  kubeClient,
 		valetClient: valetClient,
+		recorder:    recorder,
+		identity:    identity,
+		dryRun:      dryRun,
 		log:            logging.MustGetLogger("NodeAssignmentManager"),
 	}
 }
 
-// ReconcileNag handles the business logic for NodeAssigmentGroup changes
-func (m *Manager) ReconcileNag(nag *assignmentsv1alpha1.NodeAssignmentGroup) error {
+// ReconcileNag handles the business logic for NodeAssigmentGroup changes. A non-zero duration is returned when
+// the group has to be reconciled again, either once nodes become old enough for an assignment or when the next
+// wave of a rollout is due.
+func (m *Manager) ReconcileNag(nag *assignmentsv1alpha1.NodeAssignmentGroup) (time.Duration, error) {
 	// Note that you also have to check the uid if you have a local controlled resource, which
 	// is dependent on the actual instance, to detect that a NodeAssignmentGroup was recreated with the same name
 	m.log.Debugf("Sync/Add/Update for NodeAssignmentGroup %s\n", nag.GetName())
 
+	if m.dryRun {
+		return m.PreviewNag(nag), nil
+	}
+
 	// Create a new NagController
-	nagWc := NewWriterContext(m.kubeClient, nag)
+	nagWc := NewWriterContext(m.kubeClient, m.nodeIndex, m.podIndex, m.recorder, m.identity, nag)
 
 	if nag.GetDeletionTimestamp() == nil {
 		m.log.Debug("Handling NAG Add/Update")
 		// try to add the finalizer
 		added, err := m.AddFinalizer(nag)
 		if err != nil {
-			return err
+			return 0, err
 		}
 
 		// If a finalizer was added to the group then the update event will do the reconciling. No need to do it twice
 		if !added {
-			if err := nagWc.Reconcile(); err != nil {
-				return err
+			reconcileErr := nagWc.Reconcile()
+			if err := m.UpdateStatus(nag, nagWc, reconcileErr); err != nil {
+				m.log.Errorf("Failed to update status for nag %s: %v", nag.GetName(), err)
+			}
+			if reconcileErr != nil {
+				return 0, reconcileErr
 			}
+			return nagWc.RequeueAfter, nil
 		}
 	} else {
 		m.log.Debug("Handling NAG Finalizer")
@@ -57,7 +86,76 @@ func (m *Manager) ReconcileNag(nag *assignmentsv1alpha1.NodeAssignmentGroup) err
 		nagWc.UnassignAllNodes()
 		m.RemoveFinalizer(nag)
 	}
-	return nil
+	return 0, nil
+}
+
+// PreviewNag logs and records an event for every node change that reconciling the nag would make. Nothing is
+// written to the api, not even the finalizer or status of the nag. The duration until the plan may change is returned.
+func (m *Manager) PreviewNag(nag *assignmentsv1alpha1.NodeAssignmentGroup) time.Duration {
+	objs := m.nodeIndex.List()
+	nodes := make([]*corev1.Node, 0, len(objs))
+	for _, obj := range objs {
+		nodes = append(nodes, obj.(*corev1.Node))
+	}
+
+	planner := NewPlanner(nag, nodes, m.podIndex, time.Now())
+	for _, np := range planner.Plan() {
+		// Deleting a group removes all of its assignments
+		if nag.GetDeletionTimestamp() != nil {
+			np.Planned = nil
+		}
+		if !np.Changed() {
+			continue
+		}
+		changes := strings.Join(DiffNodes(np.Node, planner.Apply(np)), ", ")
+		m.log.Noticef("Dry run of NAG %s would %s: %s", nag.GetName(), np, changes)
+		m.recorder.Eventf(nag, corev1.EventTypeNormal, utils.EventReasonDryRun, "Would %s: %s", np, changes)
+	}
+	return planner.RequeueAfter
+}
+
+// UpdateStatus writes the status generated by the WriterContext to the status subresource of the nag.
+// The write is skipped when nothing has changed to avoid needless update events. A NotSatisfied event is recorded
+// when the group stops being satisfied.
+func (m *Manager) UpdateStatus(nag *assignmentsv1alpha1.NodeAssignmentGroup, wc *WriterContext, reconcileErr error) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		// Retrieve the latest version before attempting update
+		// RetryOnConflict uses exponential backoff to avoid exhausting the apiserver
+		result, getErr := m.valetClient.AssignmentsV1alpha1().NodeAssignmentGroups().Get(nag.GetName(), metav1.GetOptions{})
+		if getErr != nil {
+			return getErr
+		}
+
+		status := wc.Status(result.Status, reconcileErr)
+		if apiequality.Semantic.DeepEqual(result.Status, status) {
+			m.log.Debugf("Status of nag %s is unchanged", nag.GetName())
+			return nil
+		}
+
+		becameUnsatisfied := status.State == assignmentsv1alpha1.NodeAssignmentGroupStateNotSatisfied &&
+			result.Status.State != assignmentsv1alpha1.NodeAssignmentGroupStateNotSatisfied
+		result.Status = status
+		if _, updateErr := m.valetClient.AssignmentsV1alpha1().NodeAssignmentGroups().UpdateStatus(result); updateErr != nil {
+			return updateErr
+		}
+
+		if becameUnsatisfied {
+			m.recorder.Eventf(nag, corev1.EventTypeWarning, utils.EventReasonNotSatisfied, "Not enough nodes for %s", strings.Join(unsatisfiedAssignments(status), ", "))
+		}
+		return nil
+	})
+}
+
+// unsatisfiedAssignments describes the assignments of the status that have fewer nodes than they want.
+// Ex: first (1/2 nodes)
+func unsatisfiedAssignments(status assignmentsv1alpha1.NodeAssignmentGroupStatus) []string {
+	var rtn []string
+	for _, s := range status.AssignmentStates {
+		if s.NumAssigned < s.NumDesired {
+			rtn = append(rtn, fmt.Sprintf("%s (%d/%d nodes)", s.Name, s.NumAssigned, s.NumDesired))
+		}
+	}
+	return rtn
 }
 
 func (m *Manager) AddFinalizer(nag *assignmentsv1alpha1.NodeAssignmentGroup) (bool, error) {
@@ -0,0 +1,443 @@
+package nodeassignment
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	logging "github.com/op/go-logging"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	assignmentsv1alpha1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1alpha1"
+)
+
+// Planner decides the assignment of every node of a group. It never talks to the api so it can be used to
+// preview what reconciling a group would do.
+type Planner struct {
+	Nag                *assignmentsv1alpha1.NodeAssignmentGroup
+	KnownAssignments   map[string]struct{}
+	TargetedNodes      []*corev1.Node
+	UntargetedNodes    []*corev1.Node
+	CurrentAssignments map[string]int
+	DesiredAssignments map[string]int
+	AssignmentChanges  map[string]int
+	// RequeueAfter is the shortest time until the group has to be reconciled again. Either because a node that is
+	// too young becomes eligible for an assignment or because the next wave of a rollout is due
+	RequeueAfter time.Duration
+	now          time.Time
+	podIndex     cache.Indexer
+	log          *logging.Logger
+}
+
+// NodePlan is the planned assignment of a single node
+type NodePlan struct {
+	Node *corev1.Node
+	// Current is the name of the assignment the node has now. Empty when it has none
+	Current string
+	// Planned is the assignment the node should have. Nil when it should not have one
+	Planned *assignmentsv1alpha1.NodeAssignment
+	// Targeted is false for nodes that still have an assignment but are no longer targeted by the group
+	Targeted bool
+	// Outdated is true when the node keeps its assignment but does not have the labels and taints the assignment
+	// sets now. Ex: after the mode, keys or extras of the assignment were edited
+	Outdated bool
+}
+
+// NewPlanner creates a Planner for the nag and nodes. podIndex is only read and may be nil.
+func NewPlanner(nag *assignmentsv1alpha1.NodeAssignmentGroup, nodes []*corev1.Node, podIndex cache.Indexer, now time.Time) *Planner {
+	// Work on a defaulted copy so the shared cache object is never modified
+	defaulted := nag.DeepCopy()
+	assignmentsv1alpha1.SetObjectDefaults_NodeAssignmentGroup(defaulted)
+
+	p := &Planner{
+		Nag:      defaulted,
+		now:      now,
+		podIndex: podIndex,
+		log:      logging.MustGetLogger("NodeAssignmentPlanner"),
+	}
+	p.updateKnownAssignments()
+	p.updateNodeSets(nodes)
+	p.updateCurrentAssignments()
+	p.updateAssignmentChanges()
+	return p
+}
+
+// updateKnownAssignments Generates a map of all known assignments in a group and sets the struct field
+func (p *Planner) updateKnownAssignments() map[string]struct{} {
+	p.KnownAssignments = make(map[string]struct{})
+	for _, a := range p.Nag.Spec.Assignments {
+		p.KnownAssignments[a.Name] = struct{}{}
+	}
+	// Default is asignment is is "known" as well
+	if p.Nag.Spec.DefaultAssignment != nil {
+		p.KnownAssignments[p.Nag.Spec.DefaultAssignment.Name] = struct{}{}
+	}
+	return p.KnownAssignments
+}
+
+// isKnown returns true if the group has an assignment with the name
+func (p *Planner) isKnown(name string) bool {
+	_, ok := p.KnownAssignments[name]
+	return ok
+}
+
+// updateNodeSets splits the nodes into the ones targeted by the group and the ones that are not. Both are sorted
+// by name so that assignments are stable between reconciles
+func (p *Planner) updateNodeSets(nodes []*corev1.Node) {
+	sorted := append([]*corev1.Node(nil), nodes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	for _, node := range sorted {
+		if p.Nag.TargetsNode(node) {
+			p.log.Debug("targeting node", node.GetObjectMeta().GetName())
+			p.TargetedNodes = append(p.TargetedNodes, node)
+		} else {
+			p.log.Debug("not targeting node", node.GetObjectMeta().GetName())
+			p.UntargetedNodes = append(p.UntargetedNodes, node)
+		}
+	}
+}
+
+func (p *Planner) updateCurrentAssignments() {
+	p.CurrentAssignments = make(map[string]int)
+	// Generate map of current assigments and their satisfactions. Assignments that are no longer in the group are
+	// left out since their nodes are always unassigned
+	for _, node := range p.TargetedNodes {
+		if a, ok := p.Nag.GetAssignment(node); ok && p.isKnown(a) {
+			if p.Nag.Spec.DefaultAssignment == nil ||
+				(p.Nag.Spec.DefaultAssignment != nil && a != p.Nag.Spec.DefaultAssignment.Name) {
+				p.CurrentAssignments[a]++
+			}
+		}
+	}
+	p.log.Debugf("Current Assignments: %+v", p.CurrentAssignments)
+}
+
+func (p *Planner) updateAssignmentChanges() {
+	p.AssignmentChanges = make(map[string]int)
+	p.DesiredAssignments = make(map[string]int)
+	// Calculate any changes that are required
+	for _, a := range p.Nag.Spec.Assignments {
+		desired := a.DesiredNodes(len(p.TargetedNodes))
+		p.DesiredAssignments[a.Name] = desired
+
+		curNum, ok := p.CurrentAssignments[a.Name]
+		if !ok {
+			curNum = 0
+		}
+		d := desired - curNum
+
+		if d != 0 {
+			p.AssignmentChanges[a.Name] = d
+		}
+	}
+	p.log.Debugf("Assignment Changes: %+v", p.AssignmentChanges)
+}
+
+// Plan returns the planned assignment of every node the group has to look at. Untargeted nodes that still have an
+// assignment come first and are always planned to be unassigned. They are followed by all targeted nodes.
+func (p *Planner) Plan() []NodePlan {
+	planned := p.planAssignments()
+
+	var plans []NodePlan
+	for _, node := range p.UntargetedNodes {
+		if ca, ok := p.Nag.GetAssignment(node); ok {
+			p.log.Debugf("%s is no longer targeted by %s but has an assignment. Unassigning", node.ObjectMeta.Name, ca)
+			plans = append(plans, NodePlan{Node: node, Current: ca})
+		}
+	}
+	for _, node := range p.TargetedNodes {
+		ca, _ := p.Nag.GetAssignment(node)
+		if ca != "" && !p.isKnown(ca) {
+			p.log.Debugf("%s is part of an unknown assignment: %s", node.ObjectMeta.Name, ca)
+		}
+		np := NodePlan{Node: node, Current: ca, Planned: planned[node.Name], Targeted: true}
+		np.Outdated = ca != "" && ca == np.PlannedName() && p.outdated(np)
+		plans = append(plans, np)
+	}
+	return plans
+}
+
+// outdated returns true if carrying out the plan would change the labels, taints or applied annotation of the node
+func (p *Planner) outdated(np NodePlan) bool {
+	after := p.Apply(np)
+	key := p.Nag.AppliedAnnotationKey()
+	return len(DiffNodes(np.Node, after)) > 0 || np.Node.Annotations[key] != after.Annotations[key]
+}
+
+// planAssignments decides the assignment of every targeted node. Nodes keep their assignment while it still
+// wants them, so resizing one assignment never moves nodes between the others. Nodes that are not kept fill the
+// assignments that need more nodes, in assignment order, and whatever is left gets the default assignment.
+// Both which nodes are kept and which are picked follow the selection policy of the group. Assignments with a
+// topology spread also keep and pick nodes so that their domains stay balanced. They are balanced in their turn of
+// the fill, against the free nodes the assignments before them left. Nodes that do not meet the requirements of an
+// assignment are never given it.
+func (p *Planner) planAssignments() map[string]*assignmentsv1alpha1.NodeAssignment {
+	ranker := newNodeRanker(p.Nag, p.podIndex)
+	plan := make(map[string]*assignmentsv1alpha1.NodeAssignment)
+
+	holders := make(map[string][]*corev1.Node)
+	p.RequeueAfter = 0
+	var free []*corev1.Node
+	for _, node := range p.TargetedNodes {
+		// DesiredAssignments only has the assignments of the spec. The default assignment is not included
+		ca, ok := p.Nag.GetAssignment(node)
+		if _, wanted := p.DesiredAssignments[ca]; ok && wanted {
+			holders[ca] = append(holders[ca], node)
+		} else {
+			// Unassigned nodes and nodes in the default or an unknown assignment can always be reassigned
+			free = append(free, node)
+		}
+	}
+
+	needed := make(map[string]int)
+	spreaders := make(map[string]*topologySpreader)
+	spreadKept := make(map[string][]*corev1.Node)
+	for i := range p.Nag.Spec.Assignments {
+		a := &p.Nag.Spec.Assignments[i]
+		eligible := p.eligibleFunc(a)
+
+		// Nodes that no longer meet the requirements always lose the assignment
+		var nodes, released []*corev1.Node
+		for _, node := range holders[a.Name] {
+			if eligible(node) {
+				nodes = append(nodes, node)
+			} else {
+				released = append(released, node)
+			}
+		}
+		ranker.sort(nodes, a)
+
+		var kept []*corev1.Node
+		if a.TopologySpread != nil {
+			// Only release the nodes beyond the desired number for now. Without free nodes the spread can't grow or
+			// move any domain
+			spreaders[a.Name] = newTopologySpreader(a.TopologySpread)
+			var surplus []*corev1.Node
+			kept, surplus = spreaders[a.Name].keep(nodes, nil, p.DesiredAssignments[a.Name], eligible)
+			spreadKept[a.Name] = kept
+			released = append(released, surplus...)
+		} else {
+			keep := p.DesiredAssignments[a.Name]
+			if keep > len(nodes) {
+				keep = len(nodes)
+			}
+			kept = nodes[:keep]
+			released = append(released, nodes[keep:]...)
+		}
+
+		for _, node := range kept {
+			plan[node.Name] = a
+		}
+		if len(released) > 0 {
+			p.log.Debugf("%d nodes should no longer be assigned to %s", len(released), a.Name)
+		}
+		free = append(free, released...)
+		needed[a.Name] = p.DesiredAssignments[a.Name] - len(kept)
+	}
+
+	for i := range p.Nag.Spec.Assignments {
+		a := &p.Nag.Spec.Assignments[i]
+		eligible := p.eligibleFunc(a)
+		ranker.sort(free, a)
+		if spreader, ok := spreaders[a.Name]; ok {
+			// The free nodes are final for this assignment now, so the domains can be balanced without counting on
+			// nodes an earlier assignment took. The nodes that are moved are free for the assignments after it.
+			kept, unbalanced := spreader.keep(spreadKept[a.Name], free, p.DesiredAssignments[a.Name], eligible)
+			for _, node := range unbalanced {
+				delete(plan, node.Name)
+			}
+			free = append(free, unbalanced...)
+			ranker.sort(free, a)
+			needed[a.Name] = p.DesiredAssignments[a.Name] - len(kept)
+		}
+		for ; needed[a.Name] > 0; needed[a.Name]-- {
+			var next int
+			if spreader, ok := spreaders[a.Name]; ok {
+				next = spreader.pick(free, eligible)
+			} else {
+				next = firstEligible(free, eligible)
+			}
+			if next < 0 {
+				break
+			}
+			plan[free[next].Name] = a
+			free = append(free[:next], free[next+1:]...)
+		}
+	}
+
+	// Nodes that are not eligible for the default assignment, or all nodes when there is none, are left unassigned
+	for _, node := range free {
+		if p.Nag.Spec.DefaultAssignment != nil && p.eligibleFunc(p.Nag.Spec.DefaultAssignment)(node) {
+			plan[node.Name] = p.Nag.Spec.DefaultAssignment
+		}
+	}
+
+	return plan
+}
+
+// eligibleFunc returns a func that checks nodes against the requirements of the assignment. Nodes that are only
+// too young shorten RequeueAfter so that the group is reconciled again once they are old enough.
+func (p *Planner) eligibleFunc(na *assignmentsv1alpha1.NodeAssignment) func(*corev1.Node) bool {
+	return func(node *corev1.Node) bool {
+		ok, wait := na.Requirements.MatchesNode(node, p.now)
+		p.requeueWithin(wait)
+		return ok
+	}
+}
+
+// requeueWithin shortens RequeueAfter to d. Durations that are not positive are ignored
+func (p *Planner) requeueWithin(d time.Duration) {
+	if d > 0 && (p.RequeueAfter == 0 || d < p.RequeueAfter) {
+		p.RequeueAfter = d
+	}
+}
+
+// findAssignment returns the assignment of the group with the given name, including the default assignment
+func (p *Planner) findAssignment(name string) *assignmentsv1alpha1.NodeAssignment {
+	for i := range p.Nag.Spec.Assignments {
+		if p.Nag.Spec.Assignments[i].Name == name {
+			return &p.Nag.Spec.Assignments[i]
+		}
+	}
+	if da := p.Nag.Spec.DefaultAssignment; da != nil && da.Name == name {
+		return da
+	}
+	return nil
+}
+
+// Apply returns a copy of the node as it will look once the plan has been carried out
+func (p *Planner) Apply(np NodePlan) *corev1.Node {
+	node := np.Node.DeepCopy()
+	applyAssignment(p.Nag, node, np.Planned)
+	return node
+}
+
+// firstEligible returns the index of the first eligible node or -1 if there is none
+func firstEligible(nodes []*corev1.Node, eligible func(*corev1.Node) bool) int {
+	for i, node := range nodes {
+		if eligible(node) {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyAssignment gives the node the assignment, or takes its assignment away when na is nil. Any previous
+// assignment and drain state of the group are always removed first. A node that keeps its assignment keeps its
+// provenance and pack left state.
+func applyAssignment(nag *assignmentsv1alpha1.NodeAssignmentGroup, node *corev1.Node, na *assignmentsv1alpha1.NodeAssignment) {
+	before := node.DeepCopy()
+	nag.Unassign(node)
+	clearDrainState(nag, node)
+	if na == nil {
+		return
+	}
+	nag.Assign(node, na)
+	if current, _ := nag.GetAssignment(before); current == na.Name {
+		keepAssignmentState(nag, node, before)
+	}
+}
+
+// keepAssignmentState copies the provenance and the pack left label and taints of the group from src to dst
+func keepAssignmentState(nag *assignmentsv1alpha1.NodeAssignmentGroup, dst *corev1.Node, src *corev1.Node) {
+	plk := nag.PackLeftLabelKey()
+	assignmentsv1alpha1.CopyProvenance(dst, src, nag.AssignmentLabelKey())
+	assignmentsv1alpha1.CopyProvenance(dst, src, plk)
+	if state, ok := src.Labels[plk]; ok {
+		if dst.Labels == nil {
+			dst.Labels = make(map[string]string)
+		}
+		dst.Labels[plk] = state
+	}
+	for _, taint := range src.Spec.Taints {
+		if taint.Key == plk {
+			dst.Spec.Taints = append(dst.Spec.Taints, taint)
+		}
+	}
+}
+
+// PlannedName returns the name of the planned assignment. Empty when the node should not have one
+func (np NodePlan) PlannedName() string {
+	if np.Planned == nil {
+		return ""
+	}
+	return np.Planned.Name
+}
+
+// Changed returns true if carrying out the plan changes the node
+func (np NodePlan) Changed() bool {
+	return np.Reassigned() || np.Outdated
+}
+
+// Reassigned returns true if carrying out the plan changes the assignment of the node
+func (np NodePlan) Reassigned() bool {
+	return np.Current != np.PlannedName()
+}
+
+func (np NodePlan) String() string {
+	switch {
+	case np.Current == "" && np.Planned == nil:
+		return fmt.Sprintf("%s stays unassigned", np.Node.Name)
+	case np.Current == "":
+		return fmt.Sprintf("assign %s to %s", np.Node.Name, np.Planned.Name)
+	case np.Planned == nil:
+		return fmt.Sprintf("unassign %s from %s", np.Node.Name, np.Current)
+	case np.Reassigned():
+		return fmt.Sprintf("move %s from %s to %s", np.Node.Name, np.Current, np.Planned.Name)
+	case np.Outdated:
+		return fmt.Sprintf("update %s for %s", np.Node.Name, np.Current)
+	}
+	return fmt.Sprintf("%s stays assigned to %s", np.Node.Name, np.Current)
+}
+
+// DiffNodes lists the label and taint changes that turn before into after. Ex: +label k=v, -taint k=v:NoSchedule
+func DiffNodes(before *corev1.Node, after *corev1.Node) []string {
+	var changes []string
+	for _, k := range sortedKeys(before.Labels, after.Labels) {
+		old, hadOld := before.Labels[k]
+		cur, hasCur := after.Labels[k]
+		switch {
+		case !hadOld:
+			changes = append(changes, fmt.Sprintf("+label %s=%s", k, cur))
+		case !hasCur:
+			changes = append(changes, fmt.Sprintf("-label %s=%s", k, old))
+		case old != cur:
+			changes = append(changes, fmt.Sprintf("~label %s=%s (was %s)", k, cur, old))
+		}
+	}
+
+	taintSet := func(taints []corev1.Taint) map[string]string {
+		set := make(map[string]string)
+		for _, t := range taints {
+			set[t.ToString()] = ""
+		}
+		return set
+	}
+	oldTaints, curTaints := taintSet(before.Spec.Taints), taintSet(after.Spec.Taints)
+	for _, t := range sortedKeys(oldTaints, curTaints) {
+		if _, ok := curTaints[t]; !ok {
+			changes = append(changes, "-taint "+t)
+		} else if _, ok := oldTaints[t]; !ok {
+			changes = append(changes, "+taint "+t)
+		}
+	}
+	return changes
+}
+
+// sortedKeys returns the keys of both maps in order
+func sortedKeys(a map[string]string, b map[string]string) []string {
+	seen := make(map[string]struct{})
+	var keys []string
+	for _, m := range []map[string]string{a, b} {
+		for k := range m {
+			if _, ok := seen[k]; !ok {
+				seen[k] = struct{}{}
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
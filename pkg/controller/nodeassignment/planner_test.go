@@ -0,0 +1,112 @@
+package nodeassignment
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	assignmentsv1alpha1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1alpha1"
+	fakevalet "github.com/domoinc/kube-valet/pkg/client/clientset/versioned/fake"
+)
+
+func newTestPlannerNodes() []*corev1.Node {
+	labelKey := "nag." + assignmentsv1alpha1.GroupName + "/testnag"
+	return []*corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node0", Labels: map[string]string{"pool": "web", labelKey: "second"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: map[string]string{"pool": "web", labelKey: "gone"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node2", Labels: map[string]string{"pool": "web"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node3", Labels: map[string]string{"pool": "web", labelKey: "rest"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "other", Labels: map[string]string{labelKey: "first"}}},
+	}
+}
+
+func newTestPlannerNag() *assignmentsv1alpha1.NodeAssignmentGroup {
+	nag := newTestNag()
+	nag.Spec.TargetLabels = map[string]string{"pool": "web"}
+	nag.Spec.Assignments[1].NumDesired = 1
+	nag.Spec.Assignments[1].Mode = assignmentsv1alpha1.NodeAssignmentModeLabelAndTaint
+	return nag
+}
+
+func TestPlan(t *testing.T) {
+	nodes := newTestPlannerNodes()
+	p := NewPlanner(newTestPlannerNag(), nodes, nil, time.Now())
+
+	expected := []struct {
+		node, current, planned string
+		targeted               bool
+	}{
+		{"other", "first", "", false},
+		{"node0", "second", "second", true},
+		// Nodes of assignments that are no longer in the group can be given any assignment
+		{"node1", "gone", "first", true},
+		{"node2", "", "first", true},
+		{"node3", "rest", "rest", true},
+	}
+	plans := p.Plan()
+	if len(plans) != len(expected) {
+		t.Fatalf("Expected %d node plans, got %d: %v", len(expected), len(plans), plans)
+	}
+	for i, e := range expected {
+		np := plans[i]
+		if np.Node.Name != e.node || np.Current != e.current || np.PlannedName() != e.planned || np.Targeted != e.targeted {
+			t.Errorf("Unexpected plan for %s: %s", e.node, np)
+		}
+	}
+
+	// node0 keeps second but is missing the taint of its mode
+	if !plans[1].Outdated || !plans[1].Changed() || plans[4].Outdated {
+		t.Errorf("Expected only node0 to be outdated: %s, %s", plans[1], plans[4])
+	}
+
+	// Previewing a plan must not modify the nodes it was made from
+	changes := DiffNodes(plans[1].Node, p.Apply(plans[1]))
+	if len(changes) != 1 || changes[0] != "+taint nag.assignments.kube-valet.io/testnag=second:NoSchedule" {
+		t.Errorf("Unexpected changes: %v", changes)
+	}
+	if len(nodes[0].Spec.Taints) != 0 {
+		t.Errorf("Node was modified: %v", nodes[0].Spec.Taints)
+	}
+
+	byName := make(map[string]NodePlan)
+	for _, np := range plans {
+		byName[np.Node.Name] = np
+	}
+	for name, want := range map[string][]string{
+		"other": {"-label nag.assignments.kube-valet.io/testnag=first"},
+		"node1": {"~label nag.assignments.kube-valet.io/testnag=first (was gone)"},
+		"node2": {"+label nag.assignments.kube-valet.io/testnag=first"},
+	} {
+		if got := DiffNodes(byName[name].Node, p.Apply(byName[name])); !reflect.DeepEqual(got, want) {
+			t.Errorf("Unexpected changes for %s: %v", name, got)
+		}
+	}
+}
+
+func TestReconcileNagDryRun(t *testing.T) {
+	nodeIndex := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, node := range newTestPlannerNodes() {
+		nodeIndex.Add(node)
+	}
+	kubeClient := newTestKubeClient()
+	valetClient := fakevalet.NewSimpleClientset()
+	recorder := record.NewFakeRecorder(10)
+
+	m := NewManager(nodeIndex, nil, kubeClient, valetClient, recorder, "valet-0", true)
+	if _, err := m.ReconcileNag(newTestPlannerNag()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(kubeClient.Actions()) != 0 || len(valetClient.Actions()) != 0 {
+		t.Errorf("Dry run used the api: %v %v", kubeClient.Actions(), valetClient.Actions())
+	}
+	// One event for each of the three nodes that change assignment and one for node0 which is missing its taint
+	if len(recorder.Events) != 4 {
+		t.Errorf("Expected 4 events, got %d", len(recorder.Events))
+	}
+}
@@ -0,0 +1,78 @@
+package nodeassignment
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	assignmentsv1alpha1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1alpha1"
+)
+
+// nodeRanker orders nodes by the selection policy of a group
+type nodeRanker struct {
+	policy assignmentsv1alpha1.NodeSelectionPolicy
+	// podCounts is the number of running pods that want an assignment by assignment and node name
+	podCounts map[string]map[string]int
+}
+
+func newNodeRanker(nag *assignmentsv1alpha1.NodeAssignmentGroup, podIndex cache.Indexer) *nodeRanker {
+	r := &nodeRanker{policy: nag.Spec.SelectionPolicy}
+	// Only count pods when the policy needs them. Listing all pods is not free
+	if r.policy == assignmentsv1alpha1.NodeSelectionPolicyMostPods {
+		r.podCounts = countAssignmentPods(nag, podIndex)
+	}
+	return r
+}
+
+// sort orders nodes from most to least preferred for the assignment. Ties are broken by name so the order never
+// depends on the order nodes were listed in.
+func (r *nodeRanker) sort(nodes []*corev1.Node, na *assignmentsv1alpha1.NodeAssignment) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return r.less(nodes[i], nodes[j], na)
+	})
+}
+
+func (r *nodeRanker) less(a *corev1.Node, b *corev1.Node, na *assignmentsv1alpha1.NodeAssignment) bool {
+	switch r.policy {
+	case assignmentsv1alpha1.NodeSelectionPolicyMostPods:
+		counts := r.podCounts[na.Name]
+		if counts[a.Name] != counts[b.Name] {
+			return counts[a.Name] > counts[b.Name]
+		}
+	case assignmentsv1alpha1.NodeSelectionPolicyNewest:
+		if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+			return b.CreationTimestamp.Before(&a.CreationTimestamp)
+		}
+	case assignmentsv1alpha1.NodeSelectionPolicyOldest:
+		if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+			return a.CreationTimestamp.Before(&b.CreationTimestamp)
+		}
+	}
+	return a.Name < b.Name
+}
+
+// countAssignmentPods returns the number of running pods on each node that want each assignment of the group.
+// Pods that run anywhere, like most DaemonSet pods, don't tell the nodes apart so they are not counted.
+func countAssignmentPods(nag *assignmentsv1alpha1.NodeAssignmentGroup, podIndex cache.Indexer) map[string]map[string]int {
+	counts := make(map[string]map[string]int)
+	if podIndex == nil {
+		return counts
+	}
+	for _, obj := range podIndex.List() {
+		pod := obj.(*corev1.Pod)
+		if pod.Spec.NodeName == "" || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		for i := range nag.Spec.Assignments {
+			na := &nag.Spec.Assignments[i]
+			if nag.PodWantsAssignment(pod, na) {
+				if counts[na.Name] == nil {
+					counts[na.Name] = make(map[string]int)
+				}
+				counts[na.Name][pod.Spec.NodeName]++
+			}
+		}
+	}
+	return counts
+}
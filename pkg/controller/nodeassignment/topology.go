@@ -0,0 +1,159 @@
+package nodeassignment
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+
+	assignmentsv1alpha1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1alpha1"
+)
+
+// topologySpreader balances the nodes of an assignment across the values of a topology label
+type topologySpreader struct {
+	topologyKey string
+	maxSkew     int
+	// counts is the number of nodes in each domain that have been given the assignment so far
+	counts map[string]int
+}
+
+func newTopologySpreader(spread *assignmentsv1alpha1.NodeAssignmentTopologySpread) *topologySpreader {
+	maxSkew := spread.MaxSkew
+	if maxSkew < 1 {
+		maxSkew = assignmentsv1alpha1.TopologySpreadMaxSkewDefault
+	}
+	return &topologySpreader{
+		topologyKey: spread.TopologyKey,
+		maxSkew:     maxSkew,
+		counts:      make(map[string]int),
+	}
+}
+
+func (s *topologySpreader) domain(node *corev1.Node) (string, bool) {
+	d, ok := node.Labels[s.topologyKey]
+	return d, ok
+}
+
+// keep splits the ranked holders of the assignment into the nodes that keep it and the nodes that are released.
+// Holders only move when the domains would otherwise be more than maxSkew apart after filling the assignment
+// from the eligible free nodes. Without free nodes only the holders beyond desired are released.
+func (s *topologySpreader) keep(holders []*corev1.Node, free []*corev1.Node, desired int, eligible func(*corev1.Node) bool) ([]*corev1.Node, []*corev1.Node) {
+	var released []*corev1.Node
+	byDomain := make(map[string][]*corev1.Node)
+	planned := make(map[string]int)
+	total := 0
+	for _, node := range holders {
+		d, ok := s.domain(node)
+		if !ok {
+			// Nodes outside of every domain can't be spread
+			released = append(released, node)
+			continue
+		}
+		byDomain[d] = append(byDomain[d], node)
+		planned[d]++
+		total++
+	}
+
+	available := make(map[string]int)
+	for _, node := range free {
+		if d, ok := s.domain(node); ok && eligible(node) {
+			available[d]++
+			// Domains without holders can still be grown
+			if _, ok := planned[d]; !ok {
+				planned[d] = 0
+			}
+		}
+	}
+
+	// Too many nodes. Shrink the largest domains first
+	for ; total > desired; total-- {
+		planned[s.largest(planned)]--
+	}
+
+	// Too few nodes. Grow the smallest domains that still have free nodes
+	for ; total < desired; total++ {
+		d, ok := s.smallest(planned, available)
+		if !ok {
+			break
+		}
+		planned[d]++
+		available[d]--
+	}
+
+	// Move nodes from the largest domain to the smallest one until they are within maxSkew
+	for {
+		max := s.largest(planned)
+		min, ok := s.smallest(planned, available)
+		if !ok || planned[max]-planned[min] <= s.maxSkew {
+			break
+		}
+		planned[max]--
+		planned[min]++
+		available[min]--
+	}
+
+	var kept []*corev1.Node
+	for d, nodes := range byDomain {
+		n := planned[d]
+		if n > len(nodes) {
+			n = len(nodes)
+		}
+		kept = append(kept, nodes[:n]...)
+		released = append(released, nodes[n:]...)
+		s.counts[d] = n
+	}
+	return kept, released
+}
+
+// pick returns the index of the ranked free node that should be given the assignment next, or -1 when none of
+// the free nodes can be used. The best eligible node of the domain with the fewest nodes is picked.
+func (s *topologySpreader) pick(free []*corev1.Node, eligible func(*corev1.Node) bool) int {
+	best, bestDomain := -1, ""
+	for i, node := range free {
+		d, ok := s.domain(node)
+		if !ok || !eligible(node) {
+			continue
+		}
+		// free is ranked so the first node of a domain is its best one
+		if best == -1 || s.counts[d] < s.counts[bestDomain] {
+			best, bestDomain = i, d
+		}
+	}
+	if best != -1 {
+		s.counts[bestDomain]++
+	}
+	return best
+}
+
+// largest returns the domain with the most planned nodes
+func (s *topologySpreader) largest(planned map[string]int) string {
+	var rtn string
+	found := false
+	for _, d := range sortedDomains(planned) {
+		if !found || planned[d] > planned[rtn] {
+			rtn, found = d, true
+		}
+	}
+	return rtn
+}
+
+// smallest returns the domain with the fewest planned nodes that still has free nodes
+func (s *topologySpreader) smallest(planned map[string]int, available map[string]int) (string, bool) {
+	var rtn string
+	found := false
+	for _, d := range sortedDomains(planned) {
+		if available[d] > 0 && (!found || planned[d] < planned[rtn]) {
+			rtn, found = d, true
+		}
+	}
+	return rtn, found
+}
+
+// sortedDomains returns the domains in name order so that ties are always broken the same way
+func sortedDomains(planned map[string]int) []string {
+	domains := make([]string, 0, len(planned))
+	for d := range planned {
+		domains = append(domains, d)
+	}
+	sort.Strings(domains)
+	return domains
+}
@@ -1,294 +1,329 @@
 package nodeassignment
 
 import (
-	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/domoinc/kube-valet/pkg/utils"
 	logging "github.com/op/go-logging"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 
 	assignmentsv1alpha1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1alpha1"
 )
 
+// rolloutRequeueMinimum is the shortest delay before the next wave of a rollout. It keeps groups without a
+// MinIntervalSeconds from being requeued in a tight loop
+const rolloutRequeueMinimum = time.Second
+
 type WriterContext struct {
-	Nag                 *assignmentsv1alpha1.NodeAssignmentGroup
-	KnownAssignments    map[string]struct{}
-	TargetedNodes       []*corev1.Node
-	UntargetedNodes     []*corev1.Node
-	CurrentAssignments  map[string]int
-	AssignmentChanges   map[string]int
-	UnassignedNodeNames map[string]struct{}
-	kubeClient          kubernetes.Interface
-	log                 *logging.Logger
+	*Planner
+	AssignedCounts map[string]int
+	// NumChanged and NumPending are the number of nodes whose assignment was changed and held back by Reconcile
+	NumChanged int
+	NumPending int
+	lastWave   *metav1.Time
+	kubeClient kubernetes.Interface
+	nodeIndex  cache.Indexer
+	recorder   record.EventRecorder
+	identity   string
+	log        *logging.Logger
 }
 
-func NewWriterContext(kubeClientSet kubernetes.Interface, nag *assignmentsv1alpha1.NodeAssignmentGroup) *WriterContext {
+// NewWriterContext creates a WriterContext for the nag. Nodes and pods are read from the indexes and the api is only
+// used for writes. Assignment changes and failed patches are recorded as events on the nag and the node. identity is
+// written to the provenance annotations of assigned nodes.
+func NewWriterContext(kubeClientSet kubernetes.Interface, nodeIndex cache.Indexer, podIndex cache.Indexer, recorder record.EventRecorder, identity string, nag *assignmentsv1alpha1.NodeAssignmentGroup) *WriterContext {
 	wc := &WriterContext{
-		kubeClient:          kubeClientSet,
-		Nag:                 nag,
-		UnassignedNodeNames: make(map[string]struct{}),
-		log:                 logging.MustGetLogger("NodeAssignmentModel"),
+		kubeClient:     kubeClientSet,
+		nodeIndex:      nodeIndex,
+		recorder:       recorder,
+		identity:       identity,
+		AssignedCounts: make(map[string]int),
+		log:            logging.MustGetLogger("NodeAssignmentModel"),
+	}
+	wc.Planner = NewPlanner(nag, wc.listNodes(), podIndex, time.Now())
+	if wc.Nag.Status.Rollout != nil {
+		wc.lastWave = wc.Nag.Status.Rollout.LastWaveTime
 	}
-	// initializes and populates all other struct fields
-	wc.Update()
 	return wc
 }
 
-func (wc *WriterContext) Update() {
-	wc.log.Debug("updating")
-	wc.updateKnownAssignments()
-	wc.updateNodeSets()
-	wc.updateCurrentAssignments()
-	wc.updateAssignmentChanges()
-}
-
-// updateKnownAssignments Generates a map of all known assignments in a group and sets the struct field
-func (wc *WriterContext) updateKnownAssignments() map[string]struct{} {
-	wc.KnownAssignments = make(map[string]struct{})
-	for _, a := range wc.Nag.Spec.Assignments {
-		wc.KnownAssignments[a.Name] = struct{}{}
-	}
-	// Default is asignment is is "known" as well
-	if wc.Nag.Spec.DefaultAssignment != nil {
-		wc.KnownAssignments[wc.Nag.Spec.DefaultAssignment.Name] = struct{}{}
+// listNodes returns copies of all cached nodes
+func (wc *WriterContext) listNodes() []*corev1.Node {
+	objs := wc.nodeIndex.List()
+	nodes := make([]*corev1.Node, 0, len(objs))
+	for _, obj := range objs {
+		nodes = append(nodes, obj.(*corev1.Node).DeepCopy())
 	}
-	return wc.KnownAssignments
+	return nodes
 }
 
-// updateNodeSets updates a slice of pointers to copied node objects
-func (wc *WriterContext) updateNodeSets() error {
-	// Get nodes from api
-	nodes, err := wc.kubeClient.CoreV1().Nodes().List(metav1.ListOptions{})
-	if err != nil {
-		wc.log.Fatal(err)
-		return err
-	}
-
-	for _, node := range nodes.Items {
-		// Always unassign nodes assigned to assignments that are no longer in the group
-		if ca, ok := wc.Nag.GetAssignment(&node); ok {
-			if _, ok := wc.KnownAssignments[ca]; !ok {
-				wc.log.Debugf("%s is part of an unknown assignment: %s. Unassigning", node.ObjectMeta.Name, ca)
-				// Unassign in memory only. Makes any reassignments atomic
-				wc.Nag.Unassign(&node)
-				// Keep track of all nodes that have been unassigned. If they are not reassigned by the end of reconciliation
-				// then they will need to actually be unassigned in the api
-				wc.UnassignedNodeNames[node.ObjectMeta.Name] = struct{}{}
-			}
-		}
+// UpdateNodeAssignment uses the NodeAssignmentController's clients to do api updates. The assignment is
+// reapplied to the latest version of the node if it was changed concurrently.
+func (wc *WriterContext) UpdateNodeAssignment(node *corev1.Node, na *assignmentsv1alpha1.NodeAssignment) error {
+	prev, hadPrev := wc.Nag.GetAssignment(node)
 
-		if wc.Nag.TargetsNode(&node) {
-			wc.log.Debug("targeting node", node.GetObjectMeta().GetName())
-			wc.TargetedNodes = append(wc.TargetedNodes, node.DeepCopy())
+	// Add or remove labels/taints
+	_, err := utils.PatchNode(wc.kubeClient, node, func(assignedNode *corev1.Node) {
+		if na != nil {
+			wc.log.Debug("Assigning node:", assignedNode.GetName(), "to", na.Name)
 		} else {
-			wc.log.Debug("not targeting node", node.GetObjectMeta().GetName())
-			if curAssign, ok := wc.Nag.GetAssignment(&node); ok {
-				wc.log.Debugf("%s is no longer targeted by %s but has an assignment. Unassigning", node.ObjectMeta.Name, curAssign)
-				if err := wc.UpdateNodeAssignment(&node, nil); err != nil {
-					return err
-				}
+			wc.log.Debug("Unassigning from group")
+		}
+		previous, _ := wc.Nag.GetAssignment(assignedNode)
+		// The previous assignment is always removed. A retry starts from a node that may still have it
+		applyAssignment(wc.Nag, assignedNode, na)
+		if na != nil {
+			if previous == na.Name {
+				previous = ""
+			}
+			// Nodes that only had their assignment updated keep the provenance of when they got it
+			if previous != "" || len(assignmentsv1alpha1.GetProvenance(assignedNode, wc.Nag.AssignmentLabelKey())) == 0 {
+				wc.Nag.SetProvenance(assignedNode, wc.Nag.AssignmentLabelKey(), wc.identity, wc.now, previous)
 			}
 		}
+	})
+	if err != nil {
+		wc.patchFailed(node, err)
+		return err
 	}
 
+	switch {
+	case na != nil && prev != na.Name:
+		wc.recorder.Eventf(node, corev1.EventTypeNormal, utils.EventReasonAssigned, "Assigned to %s by NodeAssignmentGroup %s", na.Name, wc.Nag.Name)
+		wc.recorder.Eventf(wc.Nag, corev1.EventTypeNormal, utils.EventReasonAssigned, "Assigned %s to %s", node.Name, na.Name)
+	case na == nil && hadPrev:
+		wc.recorder.Eventf(node, corev1.EventTypeNormal, utils.EventReasonUnassigned, "Unassigned from %s by NodeAssignmentGroup %s", prev, wc.Nag.Name)
+		wc.recorder.Eventf(wc.Nag, corev1.EventTypeNormal, utils.EventReasonUnassigned, "Unassigned %s from %s", node.Name, prev)
+	}
 	return nil
 }
 
-func (wc *WriterContext) updateCurrentAssignments() {
-	wc.CurrentAssignments = make(map[string]int)
-	// Generate map of current assigments and their satisfactions
-	for _, node := range wc.TargetedNodes {
-		if a, ok := wc.Nag.GetAssignment(node); ok {
-			if wc.Nag.Spec.DefaultAssignment == nil ||
-				(wc.Nag.Spec.DefaultAssignment != nil && a != wc.Nag.Spec.DefaultAssignment.Name) {
-				wc.CurrentAssignments[a]++
-			}
-		}
+// patchFailed records a warning event on the node and the nag when the node could not be patched
+func (wc *WriterContext) patchFailed(node *corev1.Node, err error) {
+	wc.recorder.Eventf(node, corev1.EventTypeWarning, utils.EventReasonPatchFailed, "NodeAssignmentGroup %s failed to patch the node: %v", wc.Nag.Name, err)
+	wc.recorder.Eventf(wc.Nag, corev1.EventTypeWarning, utils.EventReasonPatchFailed, "Failed to patch %s: %v", node.Name, err)
+}
+
+// nextWaveIn returns the time until the rollout strategy allows the next wave of changes
+func (wc *WriterContext) nextWaveIn() time.Duration {
+	rs := wc.Nag.Spec.RolloutStrategy
+	if rs == nil || wc.lastWave == nil {
+		return 0
 	}
-	wc.log.Debugf("Current Assignments: %+v", wc.CurrentAssignments)
+	return wc.lastWave.Add(time.Duration(rs.MinIntervalSeconds) * time.Second).Sub(wc.now)
 }
 
-func (wc *WriterContext) assignNode(node *corev1.Node) (bool, error) {
-	wc.log.Debug("assigning node", node.GetObjectMeta().GetName())
-	for _, a := range wc.Nag.Spec.Assignments {
-		// If assigned to assignment with positive delta
-		if d, ok := wc.AssignmentChanges[a.Name]; ok && d > 0 {
-			// Needs more nodes
-			// Assign and break out of loop
-			if err := wc.UpdateNodeAssignment(node, &a); err != nil {
-				return false, err
-			}
-			// One fewer node is required now
-			wc.AssignmentChanges[a.Name]--
+// rolloutBudget returns the number of nodes whose assignment may be changed by this reconcile, or -1 when there
+// is no limit
+func (wc *WriterContext) rolloutBudget() int {
+	rs := wc.Nag.Spec.RolloutStrategy
+	switch {
+	case rs == nil:
+		return -1
+	case wc.nextWaveIn() > 0:
+		return 0
+	case rs.MaxChangesPerReconcile == 0:
+		return -1
+	}
+	return rs.MaxChangesPerReconcile
+}
 
-			// Cleanup
-			if wc.AssignmentChanges[a.Name] == 0 {
-				delete(wc.AssignmentChanges, a.Name)
-			}
+// Reconcile carries out the plan of the group. Nodes the group no longer targets and nodes of assignments that were
+// removed from the group are unassigned right away. All other changes follow the rollout strategy and the
+// reassignment policy of the assignment the node is leaving.
+func (wc *WriterContext) Reconcile() error {
+	wc.log.Info("Reconciling Assignments for NAG:", wc.Nag.ObjectMeta.Name)
 
-			// Next node
-			return true, nil
+	budget := wc.rolloutBudget()
+
+	for _, np := range wc.Plan() {
+		node, ca := np.Node, np.Current
+
+		if np.Planned == nil && ca != "" && (!np.Targeted || !wc.isKnown(ca)) {
+			wc.log.Debugf("%s should no longer be assigned to %s", node.ObjectMeta.Name, ca)
+			if err := wc.UpdateNodeAssignment(node, nil); err != nil {
+				return err
+			}
+			continue
 		}
-	}
-	return false, nil
-}
 
-func (wc *WriterContext) updateAssignmentChanges() {
-	wc.AssignmentChanges = make(map[string]int)
-	// Calculate any changes that are required
-	for _, a := range wc.Nag.Spec.Assignments {
-		// get the number of desired nodes based on PercentDesired. Rounding down. With a minimum of 1
-		desired := int(float32(len(wc.TargetedNodes)) * float32(a.PercentDesired) / 100.0)
-		if desired == 0 {
-			desired = 1
+		if !np.Changed() {
+			if ca == "" {
+				wc.log.Debugf("%s is not currently assigned", node.ObjectMeta.Name)
+			} else {
+				wc.log.Debugf("%s will stay assigned to %s", node.ObjectMeta.Name, ca)
+				wc.AssignedCounts[ca]++
+			}
+			if wc.isDraining(node) {
+				// The node no longer has to move. Stop draining it
+				wc.log.Infof("%s no longer has to leave %s. Cancelling its drain", node.ObjectMeta.Name, ca)
+				if _, err := utils.PatchNode(wc.kubeClient, node, func(n *corev1.Node) { clearDrainState(wc.Nag, n) }); err != nil {
+					wc.patchFailed(node, err)
+					return err
+				}
+			}
+			continue
 		}
 
-		// if NumDesired is given, and is larger, use it instead
-		if a.NumDesired > desired {
-			desired = a.NumDesired
+		// Changes beyond the budget of the rollout strategy are left for the next wave
+		if budget == 0 {
+			wc.log.Debugf("Holding back the assignment change of %s until the next wave", node.ObjectMeta.Name)
+			wc.NumPending++
+			if ca != "" {
+				wc.AssignedCounts[ca]++
+			}
+			continue
 		}
-		curNum, ok := wc.CurrentAssignments[a.Name]
-		if !ok {
-			curNum = 0
+		if budget > 0 {
+			budget--
 		}
-		d := desired - curNum
 
-		if d != 0 {
-			wc.AssignmentChanges[a.Name] = d
+		// Nodes leaving an assignment with the Drain policy keep it until their pods have been evicted
+		if from := wc.findAssignment(ca); np.Reassigned() && from != nil && from.ReassignmentPolicy == assignmentsv1alpha1.NodeAssignmentReassignmentPolicyDrain {
+			drained, err := wc.drainNode(node, from)
+			if err != nil {
+				return err
+			}
+			if !drained {
+				wc.AssignedCounts[ca]++
+				continue
+			}
 		}
-	}
-	wc.log.Debugf("Assignment Changes: %+v", wc.AssignmentChanges)
-}
 
-// UpdateNodeAssignment uses the NodeAssignmentController's clients to do api updates
-func (wc *WriterContext) UpdateNodeAssignment(node *corev1.Node, na *assignmentsv1alpha1.NodeAssignment) error {
-	assignedNode := node.DeepCopy()
-
-	// Add or remove labels/taints
-	if na != nil {
-		wc.log.Debug("Assigning node:", assignedNode.GetName(), "to", na.Name)
-		wc.Nag.Assign(assignedNode, na)
-	} else {
-		wc.log.Debug("Unassigning from group")
-		wc.Nag.Unassign(assignedNode)
-	}
-
-	oldData, err := json.Marshal(node)
-	if err != nil {
-		return err
-	}
-
-	newData, err := json.Marshal(assignedNode)
-	if err != nil {
-		return err
-	}
-
-	patchBytes, err := strategicpatch.CreateTwoWayMergePatch(oldData, newData, corev1.Node{})
-	if err != nil {
-		return err
-	}
-
-	// an empty patch of "{}" has a len of 2. No need to send a zero patch
-	if !utils.IsEmptyPatch(patchBytes) {
-		_, err = wc.kubeClient.CoreV1().Nodes().Patch(node.Name, types.StrategicMergePatchType, patchBytes)
-		if err != nil {
+		wc.log.Debugf("Changing assignment: %s", np)
+		if err := wc.UpdateNodeAssignment(node, np.Planned); err != nil {
 			return err
 		}
+		wc.NumChanged++
+		if np.Planned != nil {
+			wc.AssignedCounts[np.Planned.Name]++
+		}
 	}
 
-	if _, ok := wc.UnassignedNodeNames[assignedNode.ObjectMeta.Name]; ok {
-		delete(wc.UnassignedNodeNames, assignedNode.ObjectMeta.Name)
+	if wc.Nag.Spec.RolloutStrategy != nil {
+		if wc.NumChanged > 0 {
+			now := metav1.NewTime(wc.now)
+			wc.lastWave = &now
+		}
+		if wc.NumPending > 0 {
+			wait := wc.nextWaveIn()
+			if wait < rolloutRequeueMinimum {
+				wait = rolloutRequeueMinimum
+			}
+			wc.log.Infof("%d assignment changes of NAG %s are pending. Next wave in %s", wc.NumPending, wc.Nag.ObjectMeta.Name, wait)
+			wc.requeueWithin(wait)
+		}
 	}
 
 	return nil
 }
 
-func (wc *WriterContext) Reconcile() error {
-	wc.log.Info("Reconciling Assignments for NAG:", wc.Nag.ObjectMeta.Name)
-
-	// new empty status
-	// s := assignmentsv1alpha1.NodeAssignmentGroupStatus{}
-
-	// Loop through targeted nodes and update assignments
-	for _, node := range wc.TargetedNodes {
-		if ca, ok := wc.Nag.GetAssignment(node); ok {
-			var unassign bool
-			if d, ok := wc.AssignmentChanges[ca]; ok && d < 0 {
-				// If assigned to assignment with negative delta, unassign
-				wc.log.Debugf("%s should no longer be assigned to %s", node.ObjectMeta.Name, ca)
-				unassign = true
-			} else if wc.Nag.Spec.DefaultAssignment != nil && ca == wc.Nag.Spec.DefaultAssignment.Name {
-				// if the assignment is the default assignment then it can be reassigned
-				wc.log.Debugf("%s is part of the default assignment and can be reassigned", node.ObjectMeta.Name)
-				unassign = true
-			} else {
-				// assigned to assignment that doesn't requires changes
-				wc.log.Debugf("%s will stay assigned to %s", node.ObjectMeta.Name, ca)
-				continue
-			}
-
-			if unassign {
-				// Unassign in memory. Allows atomic reassigns
-				wc.Nag.Unassign(node)
-
-				// Add to Unassign cleanup map
-				wc.UnassignedNodeNames[node.ObjectMeta.Name] = struct{}{}
+// Status generates the group status from the last reconcile. Conditions are carried over from prev so that
+// transition times only change when a condition actually changes. A non-nil reconcileErr puts the group in
+// the Error state.
+func (wc *WriterContext) Status(prev assignmentsv1alpha1.NodeAssignmentGroupStatus, reconcileErr error) assignmentsv1alpha1.NodeAssignmentGroupStatus {
+	status := assignmentsv1alpha1.NodeAssignmentGroupStatus{
+		ObservedGeneration: wc.Nag.GetGeneration(),
+		NumMatched:         int64(len(wc.TargetedNodes)),
+		// copy so that prev is never modified when conditions are set
+		Conditions: append([]assignmentsv1alpha1.NodeAssignmentGroupCondition(nil), prev.Conditions...),
+	}
 
-				// One fewer unassignments needed
-				wc.AssignmentChanges[ca]++
+	var totalDesired int
+	for _, a := range wc.Nag.Spec.Assignments {
+		desired := wc.DesiredAssignments[a.Name]
+		assigned := wc.AssignedCounts[a.Name]
+		totalDesired += desired
 
-				// Cleanup
-				if wc.AssignmentChanges[ca] == 0 {
-					delete(wc.AssignmentChanges, ca)
-				}
-			}
-		} else {
-			wc.log.Debugf("%s is not currently assigned", node.ObjectMeta.Name)
+		if assigned >= desired {
+			status.NumSatisfied++
 		}
-
-		if ok, err := wc.assignNode(node); ok {
-			if err != nil {
-				wc.log.Error("Error assigning node", node)
-			}
-			// Move on to next node
-			continue
+		state := assignmentsv1alpha1.AssignmentStates{
+			Name:        a.Name,
+			NumDesired:  int64(desired),
+			NumAssigned: int64(assigned),
+			MinNodes:    int64(a.GetMinNodes()),
+		}
+		if a.MaxNodes != nil {
+			maxNodes := int64(*a.MaxNodes)
+			state.MaxNodes = &maxNodes
 		}
+		status.AssignmentStates = append(status.AssignmentStates, state)
+	}
 
-		// No more assignment changes required
-		// if there is a default not currently applied then apply it
-		if _, ok := wc.Nag.GetAssignment(node); !ok && wc.Nag.Spec.DefaultAssignment != nil {
-			wc.log.Debugf("Assigning %s to default assignment", node.ObjectMeta.Name)
-			if err := wc.UpdateNodeAssignment(node, wc.Nag.Spec.DefaultAssignment); err != nil {
-				return err
-			}
+	// The default assignment wants whatever is left over after all other assignments
+	if wc.Nag.Spec.DefaultAssignment != nil {
+		leftover := len(wc.TargetedNodes) - totalDesired
+		if leftover < 0 {
+			leftover = 0
 		}
+		status.AssignmentStates = append(status.AssignmentStates, assignmentsv1alpha1.AssignmentStates{
+			Name:        wc.Nag.Spec.DefaultAssignment.Name,
+			NumDesired:  int64(leftover),
+			NumAssigned: int64(wc.AssignedCounts[wc.Nag.Spec.DefaultAssignment.Name]),
+		})
+	}
+
+	satisfied := assignmentsv1alpha1.NodeAssignmentGroupCondition{
+		Type:               assignmentsv1alpha1.NodeAssignmentGroupConditionSatisfied,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: status.ObservedGeneration,
+		Reason:             assignmentsv1alpha1.NodeAssignmentGroupReasonAllAssignmentsSatisfied,
+		Message:            fmt.Sprintf("%d of %d assignments satisfied", status.NumSatisfied, len(wc.Nag.Spec.Assignments)),
+	}
+	status.State = assignmentsv1alpha1.NodeAssignmentGroupStateSatisfied
+	if int(status.NumSatisfied) < len(wc.Nag.Spec.Assignments) {
+		status.State = assignmentsv1alpha1.NodeAssignmentGroupStateNotSatisfied
+		satisfied.Status = metav1.ConditionFalse
+		satisfied.Reason = assignmentsv1alpha1.NodeAssignmentGroupReasonInsufficientNodes
+	}
+
+	reconciled := assignmentsv1alpha1.NodeAssignmentGroupCondition{
+		Type:               assignmentsv1alpha1.NodeAssignmentGroupConditionReconciled,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: status.ObservedGeneration,
+		Reason:             assignmentsv1alpha1.NodeAssignmentGroupReasonReconciled,
+	}
+	if reconcileErr != nil {
+		status.State = assignmentsv1alpha1.NodeAssignmentGroupStateError
+		reconciled.Status = metav1.ConditionFalse
+		reconciled.Reason = assignmentsv1alpha1.NodeAssignmentGroupReasonReconcileError
+		reconciled.Message = reconcileErr.Error()
 	}
 
-	// Unassign any nodes that were not atomically reassigned
-	for nodeName := range wc.UnassignedNodeNames {
-		wc.log.Debug("Updating", nodeName, "to be unassigned")
-		if err := wc.UnassignNodeByName(nodeName); err != nil {
-			wc.log.Error("Error unassigning Node", err)
+	if wc.Nag.Spec.RolloutStrategy != nil {
+		status.Rollout = &assignmentsv1alpha1.NodeAssignmentGroupRolloutStatus{
+			NumPending:   int64(wc.NumPending),
+			NumChanged:   int64(wc.NumChanged),
+			LastWaveTime: wc.lastWave,
 		}
 	}
 
-	return nil
+	status.SetCondition(reconciled)
+	status.SetCondition(satisfied)
+
+	return status
 }
 
-// UnassignNodeByName get's the latest version of a node from the api and unassigns it
+// UnassignNodeByName gets the cached version of a node and unassigns it. Nodes that no longer exist are ignored.
 func (wc *WriterContext) UnassignNodeByName(name string) error {
 	wc.log.Debugf("Unassigning node %s", name)
 
-	node, err := wc.kubeClient.CoreV1().Nodes().Get(name, metav1.GetOptions{})
+	obj, exists, err := wc.nodeIndex.GetByKey(name)
 	if err != nil {
 		return err
 	}
+	if !exists {
+		wc.log.Debugf("Node %s no longer exists", name)
+		return nil
+	}
 
-	return wc.UpdateNodeAssignment(node, nil)
+	return wc.UpdateNodeAssignment(obj.(*corev1.Node), nil)
 }
 
 // UnassignAllNodes cleans all nodes of assignment labels/taints
@@ -298,13 +333,8 @@ func (wc *WriterContext) UnassignNodeByName(name string) error {
 func (wc *WriterContext) UnassignAllNodes() error {
 	wc.log.Debugf("Unassigning all Assignments from %s", wc.Nag.ObjectMeta.Name)
 
-	nodes, err := wc.kubeClient.CoreV1().Nodes().List(metav1.ListOptions{})
-	if err != nil {
-		return err
-	}
-
-	for _, node := range nodes.Items {
-		wc.UpdateNodeAssignment(&node, nil)
+	for _, node := range wc.listNodes() {
+		wc.UpdateNodeAssignment(node, nil)
 	}
 
 	return nil
@@ -0,0 +1,921 @@
+package nodeassignment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	assignmentsv1alpha1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1alpha1"
+	fakevalet "github.com/domoinc/kube-valet/pkg/client/clientset/versioned/fake"
+)
+
+func newTestNodes(num int) []runtime.Object {
+	var nodes []runtime.Object
+	for i := 0; i < num; i++ {
+		nodes = append(nodes, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   fmt.Sprintf("node%d", i),
+				Labels: map[string]string{},
+			},
+		})
+	}
+	return nodes
+}
+
+// newTestWriterContext creates a WriterContext with the nodes in both the fake clientset and the node index
+func newTestWriterContext(numNodes int, nag *assignmentsv1alpha1.NodeAssignmentGroup) *WriterContext {
+	return newTestWriterContextForNodes(newTestNodes(numNodes), nil, nag)
+}
+
+func newTestWriterContextForNodes(nodes []runtime.Object, pods []runtime.Object, nag *assignmentsv1alpha1.NodeAssignmentGroup) *WriterContext {
+	nodeIndex := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, node := range nodes {
+		nodeIndex.Add(node)
+	}
+	podIndex := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, pod := range pods {
+		podIndex.Add(pod)
+	}
+	return NewWriterContext(newTestKubeClient(nodes...), nodeIndex, podIndex, &record.FakeRecorder{}, "valet-0", nag)
+}
+
+// newTestKubeClient creates a fake clientset that applies node patches to a fresh object. The default reactor
+// merges the patched node into the old one, which keeps labels that the patch removed.
+func newTestKubeClient(nodes ...runtime.Object) *fakekube.Clientset {
+	kubeClient := fakekube.NewSimpleClientset(nodes...)
+	gvr := corev1.SchemeGroupVersion.WithResource("nodes")
+	kubeClient.PrependReactor("patch", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		obj, err := kubeClient.Tracker().Get(gvr, "", patch.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		oldData, err := json.Marshal(obj)
+		if err != nil {
+			return true, nil, err
+		}
+		newData, err := strategicpatch.StrategicMergePatch(oldData, patch.GetPatch(), corev1.Node{})
+		if err != nil {
+			return true, nil, err
+		}
+		node := &corev1.Node{}
+		if err := json.Unmarshal(newData, node); err != nil {
+			return true, nil, err
+		}
+		return true, node, kubeClient.Tracker().Update(gvr, node, "")
+	})
+	return kubeClient
+}
+
+// newTestWriterContextFromClient creates a WriterContext whose node index holds the current nodes of the clientset.
+// It is used to reconcile a group again after an earlier reconcile changed the nodes.
+func newTestWriterContextFromClient(t *testing.T, kubeClient kubernetes.Interface, podIndex cache.Indexer, nag *assignmentsv1alpha1.NodeAssignmentGroup) *WriterContext {
+	nodes, err := kubeClient.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	nodeIndex := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for i := range nodes.Items {
+		nodeIndex.Add(&nodes.Items[i])
+	}
+	return NewWriterContext(kubeClient, nodeIndex, podIndex, &record.FakeRecorder{}, "valet-0", nag)
+}
+
+// getTestAssignments returns the assignment of every node in the fake clientset
+func getTestAssignments(t *testing.T, wc *WriterContext) map[string]string {
+	nodes, err := wc.kubeClient.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assignments := make(map[string]string)
+	for i := range nodes.Items {
+		a, _ := wc.Nag.GetAssignment(&nodes.Items[i])
+		assignments[nodes.Items[i].Name] = a
+	}
+	return assignments
+}
+
+func newTestNag() *assignmentsv1alpha1.NodeAssignmentGroup {
+	return &assignmentsv1alpha1.NodeAssignmentGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "testnag",
+			Generation: 3,
+		},
+		Spec: assignmentsv1alpha1.NodeAssignmentGroupSpec{
+			Assignments: []assignmentsv1alpha1.NodeAssignment{
+				{Name: "first", NumDesired: 2},
+				{Name: "second", NumDesired: 2},
+			},
+			DefaultAssignment: &assignmentsv1alpha1.NodeAssignment{Name: "rest"},
+		},
+	}
+}
+
+func TestReconcileStatus(t *testing.T) {
+	testCases := []struct {
+		name          string
+		numNodes      int
+		state         assignmentsv1alpha1.NodeAssignmentGroupState
+		numSatisfied  int64
+		assignedCount map[string]int64
+	}{
+		{"Satisfied", 5, assignmentsv1alpha1.NodeAssignmentGroupStateSatisfied, 2, map[string]int64{"first": 2, "second": 2, "rest": 1}},
+		{"ExactlySatisfied", 4, assignmentsv1alpha1.NodeAssignmentGroupStateSatisfied, 2, map[string]int64{"first": 2, "second": 2, "rest": 0}},
+		{"NotSatisfied", 3, assignmentsv1alpha1.NodeAssignmentGroupStateNotSatisfied, 1, map[string]int64{"first": 2, "second": 1, "rest": 0}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wc := newTestWriterContext(tc.numNodes, newTestNag())
+			if err := wc.Reconcile(); err != nil {
+				t.Fatalf("Unexpected reconcile error: %v", err)
+			}
+
+			status := wc.Status(assignmentsv1alpha1.NodeAssignmentGroupStatus{}, nil)
+			if status.State != tc.state {
+				t.Errorf("Unexpected state: got %s; expected %s", status.State, tc.state)
+			}
+			if status.NumSatisfied != tc.numSatisfied {
+				t.Errorf("Unexpected numSatisfied: got %d; expected %d", status.NumSatisfied, tc.numSatisfied)
+			}
+			if status.NumMatched != int64(tc.numNodes) {
+				t.Errorf("Unexpected numMatched: got %d; expected %d", status.NumMatched, tc.numNodes)
+			}
+			if status.ObservedGeneration != 3 {
+				t.Errorf("Unexpected observedGeneration: got %d; expected 3", status.ObservedGeneration)
+			}
+			for _, as := range status.AssignmentStates {
+				if as.NumAssigned != tc.assignedCount[as.Name] {
+					t.Errorf("Unexpected numAssigned for %s: got %d; expected %d", as.Name, as.NumAssigned, tc.assignedCount[as.Name])
+				}
+			}
+		})
+	}
+}
+
+func TestReconcileNodeBounds(t *testing.T) {
+	minNodes, maxNodes := 0, 3
+	nag := newTestNag()
+	nag.Spec.Assignments = []assignmentsv1alpha1.NodeAssignment{
+		{Name: "first", PercentDesired: 10, MinNodes: &minNodes},
+		{Name: "second", PercentDesired: 50, PercentRounding: assignmentsv1alpha1.NodeAssignmentPercentRoundingCeil, MaxNodes: &maxNodes},
+	}
+
+	wc := newTestWriterContext(5, nag)
+	if err := wc.Reconcile(); err != nil {
+		t.Fatalf("Unexpected reconcile error: %v", err)
+	}
+
+	counts := make(map[string]int)
+	for _, a := range getTestAssignments(t, wc) {
+		counts[a]++
+	}
+	expected := map[string]int{"second": 3, "rest": 2}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("Unexpected assignments: got %v; expected %v", counts, expected)
+	}
+
+	status := wc.Status(assignmentsv1alpha1.NodeAssignmentGroupStatus{}, nil)
+	first, second := status.AssignmentStates[0], status.AssignmentStates[1]
+	if first.NumDesired != 0 || first.MinNodes != 0 || first.MaxNodes != nil {
+		t.Errorf("Unexpected status for first: %+v", first)
+	}
+	if second.NumDesired != 3 || second.MinNodes != 1 || second.MaxNodes == nil || *second.MaxNodes != 3 {
+		t.Errorf("Unexpected status for second: %+v", second)
+	}
+}
+
+func TestStatusConditions(t *testing.T) {
+	wc := newTestWriterContext(4, newTestNag())
+	if err := wc.Reconcile(); err != nil {
+		t.Fatalf("Unexpected reconcile error: %v", err)
+	}
+
+	first := wc.Status(assignmentsv1alpha1.NodeAssignmentGroupStatus{}, nil)
+	cond := first.GetCondition(assignmentsv1alpha1.NodeAssignmentGroupConditionReconciled)
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		t.Fatalf("Expected Reconciled condition to be True, got %+v", cond)
+	}
+
+	// Transition times must be stable when nothing changed
+	second := wc.Status(first, nil)
+	if !second.GetCondition(assignmentsv1alpha1.NodeAssignmentGroupConditionSatisfied).LastTransitionTime.Equal(
+		&first.GetCondition(assignmentsv1alpha1.NodeAssignmentGroupConditionSatisfied).LastTransitionTime) {
+		t.Errorf("LastTransitionTime changed without a status change")
+	}
+
+	errored := wc.Status(second, errors.New("boom"))
+	if errored.State != assignmentsv1alpha1.NodeAssignmentGroupStateError {
+		t.Errorf("Unexpected state: got %s; expected %s", errored.State, assignmentsv1alpha1.NodeAssignmentGroupStateError)
+	}
+	cond = errored.GetCondition(assignmentsv1alpha1.NodeAssignmentGroupConditionReconciled)
+	if cond.Status != metav1.ConditionFalse || cond.Message != "boom" {
+		t.Errorf("Unexpected Reconciled condition: %+v", cond)
+	}
+
+	// The previous status must never be modified
+	if second.GetCondition(assignmentsv1alpha1.NodeAssignmentGroupConditionReconciled).Status != metav1.ConditionTrue {
+		t.Errorf("Previous status was modified")
+	}
+}
+
+func TestReconcileDoesNotModifyCache(t *testing.T) {
+	wc := newTestWriterContext(3, newTestNag())
+	if err := wc.Reconcile(); err != nil {
+		t.Fatalf("Unexpected reconcile error: %v", err)
+	}
+
+	for _, obj := range wc.nodeIndex.List() {
+		if node := obj.(*corev1.Node); len(node.Labels) != 0 {
+			t.Errorf("Cached node %s was modified: %v", node.Name, node.Labels)
+		}
+	}
+
+	// Nodes must still be assigned through the api
+	node, err := wc.kubeClient.CoreV1().Nodes().Get("node0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if a, ok := wc.Nag.GetAssignment(node); !ok || a != "first" {
+		t.Errorf("Expected node0 to be assigned to first, got %q", a)
+	}
+}
+
+func TestReconcileNagEvents(t *testing.T) {
+	nodes := newTestNodes(3)
+	nodeIndex := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, node := range nodes {
+		nodeIndex.Add(node)
+	}
+	nag := newTestNag()
+	nag.Finalizers = []string{nagFinalizer}
+	kubeClient := newTestKubeClient(nodes...)
+	recorder := record.NewFakeRecorder(20)
+	m := NewManager(nodeIndex, nil, kubeClient, fakevalet.NewSimpleClientset(nag), recorder, "valet-0", false)
+
+	if _, err := m.ReconcileNag(nag); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	expected := []string{
+		"Normal Assigned Assigned to first by NodeAssignmentGroup testnag",
+		"Normal Assigned Assigned node0 to first",
+		"Normal Assigned Assigned to first by NodeAssignmentGroup testnag",
+		"Normal Assigned Assigned node1 to first",
+		"Normal Assigned Assigned to second by NodeAssignmentGroup testnag",
+		"Normal Assigned Assigned node2 to second",
+		"Warning NotSatisfied Not enough nodes for second (1/2 nodes)",
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Unexpected events:\n%s", strings.Join(events, "\n"))
+	}
+
+	// Failed patches are recorded on the node and the group
+	node0, err := kubeClient.CoreV1().Nodes().Get("node0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	kubeClient.PrependReactor("patch", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("patch failed")
+	})
+	wc := NewWriterContext(kubeClient, nodeIndex, nil, recorder, "valet-0", nag)
+	if err := wc.UpdateNodeAssignment(node0, nil); err == nil {
+		t.Fatalf("Expected an error")
+	}
+	if len(recorder.Events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(recorder.Events))
+	}
+	if e := <-recorder.Events; e != "Warning PatchFailed NodeAssignmentGroup testnag failed to patch the node: patch failed" {
+		t.Errorf("Unexpected event: %s", e)
+	}
+}
+
+func TestReconcileProvenance(t *testing.T) {
+	nodes := newTestNodes(3)
+	nodes[0].(*corev1.Node).Labels["nag.assignments.kube-valet.io/testnag"] = "rest"
+	nag := newTestNag()
+	nag.UID = "1234"
+
+	wc := newTestWriterContextForNodes(nodes, nil, nag)
+	if err := wc.Reconcile(); err != nil {
+		t.Fatalf("Unexpected reconcile error: %v", err)
+	}
+
+	getProvenance := func(name string) map[string]string {
+		node, err := wc.kubeClient.CoreV1().Nodes().Get(name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		p := assignmentsv1alpha1.GetProvenance(node, wc.Nag.AssignmentLabelKey())
+		if _, err := time.Parse(time.RFC3339, p[assignmentsv1alpha1.ProvenanceAssignedAt]); err != nil {
+			t.Errorf("Unexpected assignment time for %s: %v", name, err)
+		}
+		delete(p, assignmentsv1alpha1.ProvenanceAssignedAt)
+		return p
+	}
+
+	expected := map[string]string{"assigned-by": "valet-0", "generation": "3", "uid": "1234", "previous": "rest"}
+	if p := getProvenance("node0"); !reflect.DeepEqual(p, expected) {
+		t.Errorf("Unexpected provenance of node0: %v", p)
+	}
+	// Nodes that had no assignment have no previous one
+	delete(expected, "previous")
+	if p := getProvenance("node1"); !reflect.DeepEqual(p, expected) {
+		t.Errorf("Unexpected provenance of node1: %v", p)
+	}
+
+	// Deleting the group removes the provenance along with the assignment
+	wc = newTestWriterContextFromClient(t, wc.kubeClient, nil, nag)
+	if err := wc.UnassignAllNodes(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	nodeList, err := wc.kubeClient.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, node := range nodeList.Items {
+		if len(node.Annotations) != 0 {
+			t.Errorf("Provenance of %s was not removed: %v", node.Name, node.Annotations)
+		}
+	}
+}
+
+func TestReconcileCustomKeys(t *testing.T) {
+	nag := newTestNag()
+	nag.Spec.Assignments[0].Mode = assignmentsv1alpha1.NodeAssignmentModeTaintOnly
+	nag.Spec.Assignments[0].TaintKey = "dedicated"
+	nag.Spec.Assignments[0].ExtraLabels = map[string]string{"node-role.kubernetes.io/ingress": ""}
+
+	wc := newTestWriterContext(5, nag)
+	if err := wc.Reconcile(); err != nil {
+		t.Fatalf("Unexpected reconcile error: %v", err)
+	}
+	expected := map[string]string{"node0": "first", "node1": "first", "node2": "second", "node3": "second", "node4": "rest"}
+	if assignments := getTestAssignments(t, wc); !reflect.DeepEqual(assignments, expected) {
+		t.Fatalf("Unexpected assignments: %v", assignments)
+	}
+	node, err := wc.kubeClient.CoreV1().Nodes().Get("node0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := node.Labels["node-role.kubernetes.io/ingress"]; !ok || len(node.Labels) != 1 {
+		t.Errorf("Unexpected labels: %v", node.Labels)
+	}
+	if len(node.Spec.Taints) != 1 || node.Spec.Taints[0].Key != "dedicated" || node.Spec.Taints[0].Value != "first" {
+		t.Errorf("Unexpected taints: %v", node.Spec.Taints)
+	}
+
+	// The keys of an assignment that was removed from the group are still cleaned up
+	nag = newTestNag()
+	nag.Spec.Assignments = nag.Spec.Assignments[1:]
+	wc = newTestWriterContextFromClient(t, wc.kubeClient, nil, nag)
+	if err := wc.Reconcile(); err != nil {
+		t.Fatalf("Unexpected reconcile error: %v", err)
+	}
+	nodes, err := wc.kubeClient.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, node := range nodes.Items {
+		if _, ok := node.Labels["node-role.kubernetes.io/ingress"]; ok || len(node.Spec.Taints) != 0 {
+			t.Errorf("Keys of %s were not removed: %v %v", node.Name, node.Labels, node.Spec.Taints)
+		}
+		if _, ok := node.Annotations[nag.AppliedAnnotationKey()]; ok {
+			t.Errorf("Applied annotation of %s was not removed", node.Name)
+		}
+	}
+}
+
+func TestReconcileUpdatedAssignment(t *testing.T) {
+	wc := newTestWriterContext(5, newTestNag())
+	if err := wc.Reconcile(); err != nil {
+		t.Fatalf("Unexpected reconcile error: %v", err)
+	}
+	getNode := func(name string) *corev1.Node {
+		node, err := wc.kubeClient.CoreV1().Nodes().Get(name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return node
+	}
+	assignedAtKey := assignmentsv1alpha1.ProvenanceKey(assignmentsv1alpha1.ProvenanceAssignedAt, wc.Nag.AssignmentLabelKey())
+	node := getNode("node0")
+	assignedAt := node.Annotations[assignedAtKey]
+	node.Labels[wc.Nag.PackLeftLabelKey()] = "Use"
+	if _, err := wc.kubeClient.CoreV1().Nodes().Update(node); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Nodes that keep an edited assignment get its new keys and extras without losing their provenance
+	nag := newTestNag()
+	nag.Spec.Assignments[0].Mode = assignmentsv1alpha1.NodeAssignmentModeLabelAndTaint
+	nag.Spec.Assignments[0].LabelKey = "dedicated"
+	nag.Spec.Assignments[0].ExtraLabels = map[string]string{"node-role.kubernetes.io/ingress": ""}
+	wc = newTestWriterContextFromClient(t, wc.kubeClient, nil, nag)
+	wc.now = wc.now.Add(time.Hour)
+	if err := wc.Reconcile(); err != nil {
+		t.Fatalf("Unexpected reconcile error: %v", err)
+	}
+	if wc.NumChanged != 2 {
+		t.Errorf("Expected the 2 nodes of first to change, got %d", wc.NumChanged)
+	}
+	node = getNode("node0")
+	expectedLabels := map[string]string{"dedicated": "first", "node-role.kubernetes.io/ingress": "", wc.Nag.PackLeftLabelKey(): "Use"}
+	if !reflect.DeepEqual(node.Labels, expectedLabels) {
+		t.Errorf("Unexpected labels: %v", node.Labels)
+	}
+	if len(node.Spec.Taints) != 1 || node.Spec.Taints[0].Key != wc.Nag.AssignmentLabelKey() {
+		t.Errorf("Unexpected taints: %v", node.Spec.Taints)
+	}
+	if node.Annotations[assignedAtKey] != assignedAt {
+		t.Errorf("Provenance changed from %s to %s", assignedAt, node.Annotations[assignedAtKey])
+	}
+
+	// Undoing the edit removes the keys again
+	wc = newTestWriterContextFromClient(t, wc.kubeClient, nil, newTestNag())
+	if err := wc.Reconcile(); err != nil {
+		t.Fatalf("Unexpected reconcile error: %v", err)
+	}
+	node = getNode("node0")
+	expectedLabels = map[string]string{wc.Nag.AssignmentLabelKey(): "first", wc.Nag.PackLeftLabelKey(): "Use"}
+	if !reflect.DeepEqual(node.Labels, expectedLabels) || len(node.Spec.Taints) != 0 {
+		t.Errorf("Unexpected labels and taints: %v %v", node.Labels, node.Spec.Taints)
+	}
+	if _, ok := node.Annotations[wc.Nag.AppliedAnnotationKey()]; ok {
+		t.Errorf("Applied annotation was not removed")
+	}
+
+	// Nothing changes once the nodes are up to date
+	wc = newTestWriterContextFromClient(t, wc.kubeClient, nil, newTestNag())
+	if err := wc.Reconcile(); err != nil {
+		t.Fatalf("Unexpected reconcile error: %v", err)
+	}
+	if wc.NumChanged != 0 {
+		t.Errorf("Expected no changes, got %d", wc.NumChanged)
+	}
+}
+
+func TestReconcileResizeKeepsOtherAssignments(t *testing.T) {
+	nodes := newTestNodes(5)
+	for i, a := range []string{"second", "first", "second", "first", "rest"} {
+		nodes[i].(*corev1.Node).Labels["nag.assignments.kube-valet.io/testnag"] = a
+	}
+	nag := newTestNag()
+	nag.Spec.Assignments[0].NumDesired = 3
+
+	wc := newTestWriterContextForNodes(nodes, nil, nag)
+	if err := wc.Reconcile(); err != nil {
+		t.Fatalf("Unexpected reconcile error: %v", err)
+	}
+
+	expected := map[string]string{"node0": "second", "node1": "first", "node2": "second", "node3": "first", "node4": "first"}
+	if got := getTestAssignments(t, wc); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected assignments: got %v; expected %v", got, expected)
+	}
+}
+
+func TestReconcileSelectionPolicy(t *testing.T) {
+	// Only pods that select the assignment count for MostPods
+	newPod := func(name string, nodeName string, assignment string) runtime.Object {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: corev1.PodSpec{
+				NodeName:     nodeName,
+				NodeSelector: map[string]string{"nag.assignments.kube-valet.io/testnag": assignment},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	newDaemonPod := func(name string, nodeName string) runtime.Object {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: corev1.PodSpec{
+				NodeName:    nodeName,
+				Tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+
+	testCases := []struct {
+		policy   assignmentsv1alpha1.NodeSelectionPolicy
+		expected string
+	}{
+		{assignmentsv1alpha1.NodeSelectionPolicyUndefined, "node1"},
+		{assignmentsv1alpha1.NodeSelectionPolicyStable, "node1"},
+		{assignmentsv1alpha1.NodeSelectionPolicyMostPods, "node3"},
+		{assignmentsv1alpha1.NodeSelectionPolicyNewest, "node2"},
+		{assignmentsv1alpha1.NodeSelectionPolicyOldest, "node3"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			// node1, node2 and node3 are all assigned to first, which shrinks to a single node
+			nodes := newTestNodes(4)
+			created := metav1.Now()
+			for i, age := range []time.Duration{0, time.Hour, -time.Hour} {
+				node := nodes[i+1].(*corev1.Node)
+				node.Labels["nag.assignments.kube-valet.io/testnag"] = "first"
+				node.CreationTimestamp = metav1.NewTime(created.Add(age))
+			}
+			pods := []runtime.Object{
+				newPod("a", "node3", "first"), newPod("b", "node3", "first"), newPod("c", "node2", "first"),
+				newPod("d", "node1", "second"), newPod("e", "node1", "second"), newPod("f", "node1", "second"),
+				newDaemonPod("g", "node1"), newDaemonPod("h", "node1"), newDaemonPod("i", "node1"),
+			}
+
+			nag := newTestNag()
+			nag.Spec.SelectionPolicy = tc.policy
+			nag.Spec.Assignments[0].NumDesired = 1
+			nag.Spec.Assignments[1].NumDesired = 1
+
+			wc := newTestWriterContextForNodes(nodes, pods, nag)
+			if err := wc.Reconcile(); err != nil {
+				t.Fatalf("Unexpected reconcile error: %v", err)
+			}
+
+			assignments := getTestAssignments(t, wc)
+			for name, a := range assignments {
+				if a == "first" && name != tc.expected {
+					t.Errorf("Unexpected node kept in first: %s; expected %s", name, tc.expected)
+				}
+			}
+			if assignments[tc.expected] != "first" {
+				t.Errorf("Expected %s to stay in first, got %v", tc.expected, assignments)
+			}
+		})
+	}
+}
+
+func TestReconcileTopologySpread(t *testing.T) {
+	testCases := []struct {
+		name     string
+		zones    []string
+		current  []string
+		maxSkew  int
+		expected []string
+	}{
+		{
+			name:     "SpreadNewNodes",
+			zones:    []string{"a", "a", "a", "b", "b", "c"},
+			current:  []string{"", "", "", "", "", ""},
+			expected: []string{"first", "", "", "first", "", "first"},
+		},
+		{
+			name:     "RebalanceNewZone",
+			zones:    []string{"a", "a", "a", "b", "b", ""},
+			current:  []string{"first", "first", "first", "", "", ""},
+			expected: []string{"first", "first", "", "first", "", ""},
+		},
+		{
+			name:     "WithinMaxSkew",
+			zones:    []string{"a", "a", "a", "b", "b", "b"},
+			current:  []string{"first", "first", "first", "", "", ""},
+			maxSkew:  3,
+			expected: []string{"first", "first", "first", "", "", ""},
+		},
+		{
+			name:     "ShrinkLargestZone",
+			zones:    []string{"a", "a", "a", "b", "b", "c"},
+			current:  []string{"first", "first", "first", "first", "", "first"},
+			maxSkew:  2,
+			expected: []string{"first", "", "", "first", "", "first"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			nodes := newTestNodes(len(tc.zones))
+			for i, node := range nodes {
+				node := node.(*corev1.Node)
+				if tc.zones[i] != "" {
+					node.Labels["zone"] = tc.zones[i]
+				}
+				if tc.current[i] != "" {
+					node.Labels["nag.assignments.kube-valet.io/testnag"] = tc.current[i]
+				}
+			}
+
+			nag := newTestNag()
+			nag.Spec.Assignments = nag.Spec.Assignments[:1]
+			nag.Spec.Assignments[0].NumDesired = 3
+			nag.Spec.Assignments[0].TopologySpread = &assignmentsv1alpha1.NodeAssignmentTopologySpread{TopologyKey: "zone", MaxSkew: tc.maxSkew}
+			nag.Spec.DefaultAssignment = nil
+
+			wc := newTestWriterContextForNodes(nodes, nil, nag)
+			if err := wc.Reconcile(); err != nil {
+				t.Fatalf("Unexpected reconcile error: %v", err)
+			}
+
+			assignments := getTestAssignments(t, wc)
+			for i, expected := range tc.expected {
+				name := fmt.Sprintf("node%d", i)
+				if assignments[name] != expected {
+					t.Errorf("Unexpected assignment for %s (zone %q): got %q; expected %q", name, tc.zones[i], assignments[name], expected)
+				}
+			}
+		})
+	}
+}
+
+func TestReconcileTopologySpreadAfterEarlierAssignment(t *testing.T) {
+	nodes := newTestNodes(3)
+	for i, zone := range []string{"a", "a", "b"} {
+		nodes[i].(*corev1.Node).Labels["zone"] = zone
+	}
+	nodes[0].(*corev1.Node).Labels["nag.assignments.kube-valet.io/testnag"] = "second"
+	nodes[1].(*corev1.Node).Labels["nag.assignments.kube-valet.io/testnag"] = "second"
+
+	// first is filled before second is balanced, so the only free node in zone b can't be counted on to move a
+	// node of second out of zone a
+	nag := newTestNag()
+	nag.Spec.Assignments[1].TopologySpread = &assignmentsv1alpha1.NodeAssignmentTopologySpread{TopologyKey: "zone", MaxSkew: 1}
+
+	wc := newTestWriterContextForNodes(nodes, nil, nag)
+	if err := wc.Reconcile(); err != nil {
+		t.Fatalf("Unexpected reconcile error: %v", err)
+	}
+
+	expected := map[string]string{"node0": "second", "node1": "second", "node2": "first"}
+	if got := getTestAssignments(t, wc); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected assignments: got %v; expected %v", got, expected)
+	}
+}
+
+func TestReconcileRequirements(t *testing.T) {
+	nodes := newTestNodes(5)
+	for i, memory := range []string{"32Gi", "128Gi", "128Gi", "128Gi", "128Gi"} {
+		node := nodes[i].(*corev1.Node)
+		node.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+		node.Status.Allocatable = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(memory)}
+		node.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}
+	}
+	// node1 has the assignment but is no longer ready
+	node1 := nodes[1].(*corev1.Node)
+	node1.Labels["nag.assignments.kube-valet.io/testnag"] = "bigmem"
+	node1.Status.Conditions[0].Status = corev1.ConditionFalse
+	// node3 was just created
+	nodes[3].(*corev1.Node).CreationTimestamp = metav1.Now()
+
+	nag := newTestNag()
+	nag.Spec.Assignments = []assignmentsv1alpha1.NodeAssignment{{
+		Name:       "bigmem",
+		NumDesired: 3,
+		Requirements: &assignmentsv1alpha1.NodeAssignmentRequirements{
+			MinAllocatable:  corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Gi")},
+			ExcludeNotReady: true,
+			MinAgeSeconds:   600,
+		},
+	}}
+
+	wc := newTestWriterContextForNodes(nodes, nil, nag)
+	if err := wc.Reconcile(); err != nil {
+		t.Fatalf("Unexpected reconcile error: %v", err)
+	}
+
+	expected := map[string]string{"node0": "rest", "node1": "rest", "node2": "bigmem", "node3": "rest", "node4": "bigmem"}
+	if got := getTestAssignments(t, wc); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected assignments: got %v; expected %v", got, expected)
+	}
+
+	// The group must be reconciled again once node3 is old enough
+	if wc.RequeueAfter <= 9*time.Minute || wc.RequeueAfter > 10*time.Minute {
+		t.Errorf("Unexpected RequeueAfter: %s", wc.RequeueAfter)
+	}
+}
+
+func TestReconcileRolloutStrategy(t *testing.T) {
+	nag := newTestNag()
+	nag.Spec.RolloutStrategy = &assignmentsv1alpha1.NodeAssignmentGroupRolloutStrategy{MaxChangesPerReconcile: 2, MinIntervalSeconds: 60}
+
+	reconcile := func(wc *WriterContext, changed int, pending int) {
+		t.Helper()
+		if err := wc.Reconcile(); err != nil {
+			t.Fatalf("Unexpected reconcile error: %v", err)
+		}
+		if wc.NumChanged != changed || wc.NumPending != pending {
+			t.Errorf("Unexpected changes: got %d changed and %d pending; expected %d and %d", wc.NumChanged, wc.NumPending, changed, pending)
+		}
+		nag.Status = wc.Status(nag.Status, nil)
+	}
+
+	// The first wave only changes 2 of the 5 nodes and waits for the interval before the next one
+	wc := newTestWriterContext(5, nag)
+	reconcile(wc, 2, 3)
+	if wc.RequeueAfter != time.Minute {
+		t.Errorf("Unexpected RequeueAfter: %s", wc.RequeueAfter)
+	}
+	rollout := nag.Status.Rollout
+	if rollout == nil || rollout.NumPending != 3 || rollout.NumChanged != 2 || rollout.LastWaveTime == nil {
+		t.Fatalf("Unexpected rollout status: %+v", rollout)
+	}
+
+	// Nothing changes until the interval has passed
+	kubeClient := wc.kubeClient
+	wc = newTestWriterContextFromClient(t, kubeClient, nil, nag)
+	reconcile(wc, 0, 3)
+	if wc.RequeueAfter <= 59*time.Second || wc.RequeueAfter > time.Minute {
+		t.Errorf("Unexpected RequeueAfter: %s", wc.RequeueAfter)
+	}
+
+	// Every wave after the interval makes more changes until the group has converged
+	for _, wave := range []struct{ changed, pending int }{{2, 1}, {1, 0}} {
+		lastWave := metav1.NewTime(nag.Status.Rollout.LastWaveTime.Add(-time.Minute))
+		nag.Status.Rollout.LastWaveTime = &lastWave
+		wc = newTestWriterContextFromClient(t, kubeClient, nil, nag)
+		reconcile(wc, wave.changed, wave.pending)
+	}
+	if wc.RequeueAfter != 0 {
+		t.Errorf("Unexpected RequeueAfter after the rollout finished: %s", wc.RequeueAfter)
+	}
+
+	counts := make(map[string]int)
+	for _, a := range getTestAssignments(t, wc) {
+		counts[a]++
+	}
+	expected := map[string]int{"first": 2, "second": 2, "rest": 1}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("Unexpected assignments: got %v; expected %v", counts, expected)
+	}
+	if nag.Status.State != assignmentsv1alpha1.NodeAssignmentGroupStateSatisfied || nag.Status.Rollout.NumPending != 0 {
+		t.Errorf("Unexpected status after the rollout finished: %+v", nag.Status)
+	}
+}
+
+func TestReconcileDrain(t *testing.T) {
+	nodes := newTestNodes(2)
+	nodes[0].(*corev1.Node).Labels["nag.assignments.kube-valet.io/testnag"] = "first"
+	newPod := func(name string, owner string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "web"},
+			Spec:       corev1.PodSpec{NodeName: "node0"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+		if owner != "" {
+			isController := true
+			pod.OwnerReferences = []metav1.OwnerReference{{Kind: owner, Name: name, Controller: &isController}}
+		}
+		return pod
+	}
+	pods := []runtime.Object{newPod("web", "ReplicaSet"), newPod("protected", ""), newPod("logs", "DaemonSet")}
+
+	// Move node0 from first to rest
+	zero := 0
+	nag := newTestNag()
+	nag.Spec.Assignments = []assignmentsv1alpha1.NodeAssignment{{
+		Name:               "first",
+		MinNodes:           &zero,
+		Mode:               assignmentsv1alpha1.NodeAssignmentModeLabelAndTaint,
+		ReassignmentPolicy: assignmentsv1alpha1.NodeAssignmentReassignmentPolicyDrain,
+	}}
+
+	wc := newTestWriterContextForNodes(nodes, pods, nag)
+	kubeClient := wc.kubeClient.(*fakekube.Clientset)
+	var evicted []string
+	kubeClient.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		name := action.(k8stesting.CreateAction).GetObject().(*policyv1beta1.Eviction).Name
+		if name == "protected" {
+			return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 10)
+		}
+		evicted = append(evicted, name)
+		return true, nil, nil
+	})
+
+	getNode0 := func() *corev1.Node {
+		node, err := kubeClient.CoreV1().Nodes().Get("node0", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return node
+	}
+
+	// The node is cordoned and keeps its assignment while pods are left
+	if err := wc.Reconcile(); err != nil {
+		t.Fatalf("Unexpected reconcile error: %v", err)
+	}
+	node0 := getNode0()
+	if a, _ := wc.Nag.GetAssignment(node0); a != "first" || !node0.Spec.Unschedulable {
+		t.Errorf("Expected node0 to be cordoned and still in first, got %s and unschedulable=%v", a, node0.Spec.Unschedulable)
+	}
+	if node0.Annotations["drain.nag.assignments.kube-valet.io/testnag"] != drainStateDraining || node0.Annotations["drain-pods.nag.assignments.kube-valet.io/testnag"] != "2" {
+		t.Errorf("Unexpected drain annotations: %v", node0.Annotations)
+	}
+	if !reflect.DeepEqual(evicted, []string{"web"}) {
+		t.Errorf("Unexpected evictions: %v", evicted)
+	}
+	if wc.RequeueAfter != drainPollInterval {
+		t.Errorf("Unexpected RequeueAfter: %s", wc.RequeueAfter)
+	}
+
+	// Once the pods are gone the node is moved, uncordoned, and the drain annotations are removed
+	podIndex := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	podIndex.Add(pods[2])
+	wc = newTestWriterContextFromClient(t, kubeClient, podIndex, nag)
+	if err := wc.Reconcile(); err != nil {
+		t.Fatalf("Unexpected reconcile error: %v", err)
+	}
+	node0 = getNode0()
+	if a, _ := wc.Nag.GetAssignment(node0); a != "rest" || node0.Spec.Unschedulable || len(node0.Spec.Taints) != 0 {
+		t.Errorf("Expected node0 to be uncordoned and in rest, got %s and %+v", a, node0.Spec)
+	}
+	if len(testDrainAnnotations(node0)) != 0 {
+		t.Errorf("Drain annotations were not removed: %v", node0.Annotations)
+	}
+}
+
+// testDrainAnnotations returns the drain annotations of the node
+func testDrainAnnotations(node *corev1.Node) map[string]string {
+	rtn := make(map[string]string)
+	for k, v := range node.Annotations {
+		if strings.HasPrefix(k, "drain") {
+			rtn[k] = v
+		}
+	}
+	return rtn
+}
+
+func TestReconcileDrainTimeout(t *testing.T) {
+	nodes := newTestNodes(1)
+	node0 := nodes[0].(*corev1.Node)
+	node0.Labels["nag.assignments.kube-valet.io/testnag"] = "first"
+	node0.Spec.Unschedulable = true
+	// An admin cordoned the node before the drain started an hour ago
+	node0.Annotations = map[string]string{
+		"drain.nag.assignments.kube-valet.io/testnag":         drainStateDraining,
+		"drain-started.nag.assignments.kube-valet.io/testnag": time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+	}
+	pods := []runtime.Object{&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "stuck", Namespace: "web"},
+		Spec:       corev1.PodSpec{NodeName: "node0"},
+	}}
+
+	zero := 0
+	nag := newTestNag()
+	nag.Spec.Assignments = []assignmentsv1alpha1.NodeAssignment{{
+		Name:                "first",
+		MinNodes:            &zero,
+		ReassignmentPolicy:  assignmentsv1alpha1.NodeAssignmentReassignmentPolicyDrain,
+		DrainTimeoutSeconds: 600,
+	}}
+
+	wc := newTestWriterContextForNodes(nodes, pods, nag)
+	if err := wc.Reconcile(); err != nil {
+		t.Fatalf("Unexpected reconcile error: %v", err)
+	}
+	if got := getTestAssignments(t, wc); got["node0"] != "rest" {
+		t.Errorf("Expected node0 to be moved after the timeout, got %v", got)
+	}
+	node, err := wc.kubeClient.CoreV1().Nodes().Get("node0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// kube-valet did not cordon the node so it must not uncordon it
+	if !node.Spec.Unschedulable || len(testDrainAnnotations(node)) != 0 {
+		t.Errorf("Unexpected node after the drain timed out: %+v %v", node.Spec, node.Annotations)
+	}
+}
+
+func BenchmarkReconcile(b *testing.B) {
+	for _, numNodes := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("%dNodes", numNodes), func(b *testing.B) {
+			nodes := newTestNodes(numNodes)
+			nodeIndex := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, node := range nodes {
+				nodeIndex.Add(node)
+			}
+			kubeClient := fakekube.NewSimpleClientset(nodes...)
+			nag := newTestNag()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := NewWriterContext(kubeClient, nodeIndex, nil, &record.FakeRecorder{}, "valet-0", nag).Reconcile(); err != nil {
+					b.Fatalf("Unexpected reconcile error: %v", err)
+				}
+			}
+		})
+	}
+}
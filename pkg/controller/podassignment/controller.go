@@ -25,12 +25,12 @@ type Controller struct {
 }
 
 // NewController creates a new Controller
-func NewController(podIndex cache.Indexer, cparIndex cache.Indexer, parIndex cache.Indexer, kubeClient kubernetes.Interface, valetClient valet.Interface, threadiness int, stopChannel chan struct{}) *Controller {
+func NewController(podIndex cache.Indexer, cparIndex cache.Indexer, parIndex cache.Indexer, nsIndex cache.Indexer, kubeClient kubernetes.Interface, valetClient valet.Interface, threadiness int, stopChannel chan struct{}) *Controller {
 	return &Controller{
 		queue:    queues.NewRetryingWorkQueue("Pod", podIndex, threadiness, stopChannel),
 		log:      logging.MustGetLogger("PodAssignmentController"),
 		podIndex: podIndex,
-		parMan:   NewManager(podIndex, cparIndex, parIndex, kubeClient),
+		parMan:   NewManager(podIndex, cparIndex, parIndex, nsIndex, kubeClient),
 	}
 }
 
@@ -2,6 +2,7 @@ package podassignment
 
 import (
 	// "encoding/json"
+	"sort"
 
 	// "github.com/domoinc/kube-valet/pkg/utils"
 	logging "github.com/op/go-logging"
@@ -22,15 +23,17 @@ type Manager struct {
 	podIndex   cache.Indexer
 	cparIndex  cache.Indexer
 	parIndex   cache.Indexer
+	nsIndex    cache.Indexer
 	kubeClient kubernetes.Interface
 }
 
-func NewManager(podIndex cache.Indexer, cparIndex cache.Indexer, parIndex cache.Indexer, kubeClient kubernetes.Interface) *Manager {
+func NewManager(podIndex cache.Indexer, cparIndex cache.Indexer, parIndex cache.Indexer, nsIndex cache.Indexer, kubeClient kubernetes.Interface) *Manager {
 	return &Manager{
 		log:        logging.MustGetLogger("PodAssignmentManager"),
 		podIndex:   podIndex,
 		cparIndex:  cparIndex,
 		parIndex:   parIndex,
+		nsIndex:    nsIndex,
 		kubeClient: kubeClient,
 	}
 }
@@ -44,16 +47,61 @@ func (m *Manager) PodIsProtected(pod *corev1.Pod) bool {
 	return false
 }
 
-// TODO make this ordered!
+// getNamespace returns the cached namespace or nil if it is not known
+func (m *Manager) getNamespace(name string) *corev1.Namespace {
+	obj, exists, err := m.nsIndex.GetByKey(name)
+	if err != nil {
+		m.log.Errorf("Unable to get namespace %s: %s", name, err)
+		return nil
+	}
+	if !exists {
+		return nil
+	}
+	return obj.(*corev1.Namespace)
+}
+
+// matchedRule is a rule that targets a pod along with the details needed to order it
+type matchedRule struct {
+	cluster bool
+	key     string
+	spec    *assignmentsv1alpha1.PodAssignmentRuleSpec
+}
+
+// orderRules sorts rules in the order they should be applied. Lower priorities first,
+// then ClusterPodAssignmentRules before PodAssignmentRules, then by name. Rules applied
+// before the last rule with stopProcessing are dropped.
+func orderRules(rules []matchedRule) []matchedRule {
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].spec.Priority != rules[j].spec.Priority {
+			return rules[i].spec.Priority < rules[j].spec.Priority
+		}
+		if rules[i].cluster != rules[j].cluster {
+			return rules[i].cluster
+		}
+		return rules[i].key < rules[j].key
+	})
+
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].spec.StopProcessing {
+			return rules[i:]
+		}
+	}
+	return rules
+}
+
+// GetPodAssignmentsScheduling returns the scheduling of every rule that targets the pod in the order it should be applied
 func (m *Manager) GetPodAssignmentsScheduling(pod *corev1.Pod) []*assignmentsv1alpha1.PodAssignmentRuleScheduling {
-	var r []*assignmentsv1alpha1.PodAssignmentRuleScheduling
+	var matched []matchedRule
 
 	// Should probably not copy rules for every pod. But it's more dangerous to point to rules in memory since the underlying objects might change
 
+	ns := m.getNamespace(pod.GetNamespace())
+
 	// Non-Namespaced, get all in store
 	if err := cache.ListAll(m.cparIndex, labels.Everything(), func(obj interface{}) {
-		if obj.(*assignmentsv1alpha1.ClusterPodAssignmentRule).TargetsPod(pod) {
-			r = append(r, obj.(*assignmentsv1alpha1.ClusterPodAssignmentRule).Spec.Scheduling.DeepCopy())
+		cpar := obj.(*assignmentsv1alpha1.ClusterPodAssignmentRule)
+		if cpar.TargetsNamespace(ns) && cpar.TargetsPod(pod) {
+			matched = append(matched, matchedRule{cluster: true, key: cpar.GetName(), spec: cpar.Spec.PodAssignmentRuleSpec.DeepCopy()})
 		}
 	}); err != nil {
 		m.log.Errorf("Unable to get Non-Namespaced pod assignment scheduling %s", err)
@@ -61,32 +109,41 @@ func (m *Manager) GetPodAssignmentsScheduling(pod *corev1.Pod) []*assignmentsv1a
 
 	// Namespaced, get via indexer
 	if err := cache.ListAllByNamespace(m.parIndex, pod.GetNamespace(), labels.Everything(), func(obj interface{}) {
-		if obj.(*assignmentsv1alpha1.PodAssignmentRule).TargetsPod(pod) {
-			r = append(r, obj.(*assignmentsv1alpha1.PodAssignmentRule).Spec.Scheduling.DeepCopy())
+		par := obj.(*assignmentsv1alpha1.PodAssignmentRule)
+		if par.TargetsPod(pod) {
+			matched = append(matched, matchedRule{key: par.GetName(), spec: par.Spec.DeepCopy()})
 		}
 	}); err != nil {
 		m.log.Errorf("Unable to get Namespaced pod assignment scheduling %s", err)
 	}
 
+	var r []*assignmentsv1alpha1.PodAssignmentRuleScheduling
+	for _, rule := range orderRules(matched) {
+		r = append(r, &rule.spec.Scheduling)
+	}
+
 	return r
 }
 
-func (m *Manager) GetPodSchedulingPatches(pod *corev1.Pod) []utils.JsonPatchOperation {
+func (m *Manager) GetPodSchedulingPatches(pod *corev1.Pod) ([]utils.JsonPatchOperation, error) {
 	m.log.Debugf("Generating schedule patches for pod in %s", pod.GetNamespace())
 
-	patchOps := []utils.JsonPatchOperation{}
+	if m.PodIsProtected(pod) {
+		return []utils.JsonPatchOperation{}, nil
+	}
 
-	if !m.PodIsProtected(pod) {
-		// Figure out which assignments this pod matches
-		scheds := m.GetPodAssignmentsScheduling(pod)
+	// Figure out which assignments this pod matches
+	scheds := m.GetPodAssignmentsScheduling(pod)
 
-		m.log.Debugf("Matched %d scheduling rule(s)", len(scheds))
+	m.log.Debugf("Matched %d scheduling rule(s)", len(scheds))
 
-		// Append all patch operations
-		for _, s := range scheds {
-			patchOps = append(patchOps, s.GetPatchOps(pod)...)
+	// Apply every rule in turn so that later rules merge with the results of earlier ones
+	mutated := pod.DeepCopy()
+	for _, s := range scheds {
+		if err := s.ApplyToPod(mutated); err != nil {
+			return nil, err
 		}
 	}
 
-	return patchOps
+	return utils.PodSchedulingPatchOps(pod, mutated), nil
 }
@@ -0,0 +1,129 @@
+package podassignment
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+
+	assignmentsv1alpha1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1alpha1"
+)
+
+func newTestIndexer(objs ...interface{}) cache.Indexer {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, obj := range objs {
+		indexer.Add(obj)
+	}
+	return indexer
+}
+
+func newTestSpec(zone string, priority int32, stop bool) assignmentsv1alpha1.PodAssignmentRuleSpec {
+	return assignmentsv1alpha1.PodAssignmentRuleSpec{
+		Priority:       priority,
+		StopProcessing: stop,
+		Scheduling: assignmentsv1alpha1.PodAssignmentRuleScheduling{
+			NodeSelector: map[string]string{"zone": zone},
+		},
+	}
+}
+
+func newTestCpar(name string, spec assignmentsv1alpha1.PodAssignmentRuleSpec) *assignmentsv1alpha1.ClusterPodAssignmentRule {
+	return &assignmentsv1alpha1.ClusterPodAssignmentRule{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       assignmentsv1alpha1.ClusterPodAssignmentRuleSpec{PodAssignmentRuleSpec: spec},
+	}
+}
+
+func newTestPar(name string, spec assignmentsv1alpha1.PodAssignmentRuleSpec) *assignmentsv1alpha1.PodAssignmentRule {
+	return &assignmentsv1alpha1.PodAssignmentRule{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       spec,
+	}
+}
+
+func TestGetPodAssignmentsSchedulingOrder(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cpars    []interface{}
+		pars     []interface{}
+		expected []string
+	}{
+		{
+			name:     "ByName",
+			cpars:    []interface{}{newTestCpar("b", newTestSpec("b", 0, false)), newTestCpar("a", newTestSpec("a", 0, false))},
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "ClusterBeforeNamespaced",
+			cpars:    []interface{}{newTestCpar("z", newTestSpec("cluster", 0, false))},
+			pars:     []interface{}{newTestPar("a", newTestSpec("namespaced", 0, false))},
+			expected: []string{"cluster", "namespaced"},
+		},
+		{
+			name:     "Priority",
+			cpars:    []interface{}{newTestCpar("a", newTestSpec("cluster", 10, false))},
+			pars:     []interface{}{newTestPar("a", newTestSpec("namespaced", 0, false))},
+			expected: []string{"namespaced", "cluster"},
+		},
+		{
+			name:     "StopProcessing",
+			cpars:    []interface{}{newTestCpar("a", newTestSpec("default", -10, false)), newTestCpar("b", newTestSpec("team", 10, false))},
+			pars:     []interface{}{newTestPar("a", newTestSpec("stop", 0, true))},
+			expected: []string{"stop", "team"},
+		},
+	}
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewManager(newTestIndexer(), newTestIndexer(tc.cpars...), newTestIndexer(tc.pars...), newTestIndexer(), fakekube.NewSimpleClientset())
+
+			scheds := m.GetPodAssignmentsScheduling(pod)
+			if len(scheds) != len(tc.expected) {
+				t.Fatalf("Expected %d rules, got %d", len(tc.expected), len(scheds))
+			}
+			for i, s := range scheds {
+				if s.NodeSelector["zone"] != tc.expected[i] {
+					t.Errorf("Unexpected rule at %d: got %s; expected %s", i, s.NodeSelector["zone"], tc.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGetPodSchedulingPatchesLayering(t *testing.T) {
+	cparSpec := newTestSpec("a", 0, false)
+	cparSpec.Scheduling.Tolerations = []corev1.Toleration{{Key: "shared", Operator: corev1.TolerationOpExists}}
+
+	parSpec := newTestSpec("b", 0, false)
+	parSpec.Scheduling.MergeStrategy = assignmentsv1alpha1.PodAssignmentRuleSchedulingMergeStrategyMergeAppend
+	parSpec.Scheduling.Tolerations = []corev1.Toleration{{Key: "team", Operator: corev1.TolerationOpExists}}
+
+	m := NewManager(newTestIndexer(), newTestIndexer(newTestCpar("default", cparSpec)), newTestIndexer(newTestPar("team", parSpec)), newTestIndexer(), fakekube.NewSimpleClientset())
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"}}
+	ops, err := m.GetPodSchedulingPatches(pod)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(ops) != 2 {
+		t.Fatalf("Expected 2 patch operations, got %+v", ops)
+	}
+	for _, op := range ops {
+		switch op.Path {
+		case "/spec/nodeSelector":
+			if op.Value.(map[string]string)["zone"] != "b" {
+				t.Errorf("Expected the namespaced rule to win, got %v", op.Value)
+			}
+		case "/spec/tolerations":
+			if len(op.Value.([]corev1.Toleration)) != 2 {
+				t.Errorf("Expected the tolerations of both rules, got %v", op.Value)
+			}
+		default:
+			t.Errorf("Unexpected patch operation %+v", op)
+		}
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/domoinc/kube-valet/pkg/controller/nodeassignment"
 	"github.com/domoinc/kube-valet/pkg/controller/podassignment"
 	"github.com/domoinc/kube-valet/pkg/controller/scheduling/packleft"
+	"github.com/domoinc/kube-valet/pkg/utils"
 )
 
 // ResourceWatcher abstracts and shares indexers and informers.
@@ -38,6 +39,9 @@ type ResourceWatcher struct {
 	cparInformer cache.Controller
 	cparIndexer  cache.Indexer
 
+	nsInformer cache.Controller
+	nsIndexer  cache.Indexer
+
 	nagControllers []NagController
 	nagInformer    cache.Controller
 	nagIndexer     cache.Indexer
@@ -49,6 +53,8 @@ type ResourceWatcher struct {
 	podControllers []PodController
 	podInformer    cache.Controller
 	podIndexer     cache.Indexer
+	// nodeUsage is kept up to date by every pod event, elected or not, so it is complete once the pod cache syncs
+	nodeUsage *utils.NodeUsageTracker
 
 	plMan *packleft.Manager
 }
@@ -131,11 +137,14 @@ func (rw *ResourceWatcher) Run(stopChan chan struct{}) {
 	//pod controller
 	podListWatch := cache.NewListWatchFromClient(coreRestClient, "pods", corev1.NamespaceAll, fields.Everything())
 
+	rw.nodeUsage = utils.NewNodeUsageTracker()
+
 	//TODO: make resync configurable?
 	rw.podIndexer, rw.podInformer = cache.NewIndexerInformer(podListWatch, &corev1.Pod{}, 0,
 		cache.ResourceEventHandlerFuncs{
 			AddFunc: func(obj interface{}) {
 				pod := obj.(*corev1.Pod)
+				rw.nodeUsage.SetPod(pod)
 				for _, ctlr := range rw.podControllers {
 					ctlr.OnAddPod(pod)
 				}
@@ -143,17 +152,19 @@ func (rw *ResourceWatcher) Run(stopChan chan struct{}) {
 			UpdateFunc: func(oldObj interface{}, newObj interface{}) {
 				oldPod := oldObj.(*corev1.Pod)
 				newPod := newObj.(*corev1.Pod)
+				rw.nodeUsage.SetPod(newPod)
 				for _, ctlr := range rw.podControllers {
 					ctlr.OnUpdatePod(oldPod, newPod)
 				}
 			},
 			DeleteFunc: func(obj interface{}) {
 				pod := obj.(*corev1.Pod)
+				rw.nodeUsage.DeletePod(pod)
 				for _, ctlr := range rw.podControllers {
 					ctlr.OnDeletePod(pod)
 				}
 			},
-		}, cache.Indexers{})
+		}, cache.Indexers{utils.PodNodeNameIndex: utils.PodNodeNameIndexFunc})
 
 	nodeListWatch := cache.NewListWatchFromClient(coreRestClient, "nodes", corev1.NamespaceAll, fields.Everything())
 
@@ -215,10 +226,14 @@ func (rw *ResourceWatcher) Run(stopChan chan struct{}) {
 	//TODO: make resync configurable?
 	rw.cparIndexer, rw.cparInformer = cache.NewIndexerInformer(cparListWatcher, &assignmentsv1alpha1.ClusterPodAssignmentRule{}, 0, cache.ResourceEventHandlerFuncs{}, cache.Indexers{})
 
+	nsListWatch := cache.NewListWatchFromClient(coreRestClient, "namespaces", corev1.NamespaceAll, fields.Everything())
+	//TODO: make resync configurable?
+	rw.nsIndexer, rw.nsInformer = cache.NewIndexerInformer(nsListWatch, &corev1.Namespace{}, 0, cache.ResourceEventHandlerFuncs{}, cache.Indexers{})
+
 	// Initialize controllers
-	rw.parCtlr = podassignment.NewController(rw.podIndexer, rw.cparIndexer, rw.parIndexer, rw.kubeClient, rw.valetClient, rw.config.ParController.Threads, stopChan)
-	rw.nagCtlr = nodeassignment.NewController(rw.nagIndexer, rw.nodeIndexer, rw.kubeClient, rw.valetClient, rw.config.NagController.Threads, stopChan)
-	rw.plCtlr = packleft.NewController(rw.nagIndexer, rw.nodeIndexer, rw.podIndexer, rw.kubeClient, rw.valetClient, rw.config.PLController.Threads, stopChan)
+	rw.parCtlr = podassignment.NewController(rw.podIndexer, rw.cparIndexer, rw.parIndexer, rw.nsIndexer, rw.kubeClient, rw.valetClient, rw.config.ParController.Threads, stopChan)
+	rw.nagCtlr = nodeassignment.NewController(rw.nagIndexer, rw.nodeIndexer, rw.podIndexer, rw.kubeClient, rw.valetClient, rw.config.EventRecorder, rw.config.Identity, rw.config.DryRun, rw.config.NagController.Threads, stopChan)
+	rw.plCtlr = packleft.NewController(rw.nagIndexer, rw.nodeIndexer, rw.nodeUsage, rw.kubeClient, rw.valetClient, rw.config.EventRecorder, rw.config.Identity, rw.config.DryRun, rw.config.PLController.Threads, rw.config.PackLeftCoalesceWindow, stopChan)
 
 	// start caches
 	go rw.podInformer.Run(stopChan)
@@ -230,12 +245,15 @@ func (rw *ResourceWatcher) Run(stopChan chan struct{}) {
 	go rw.parInformer.Run(stopChan)
 	rw.log.Infof("starting cpar informer")
 	go rw.cparInformer.Run(stopChan)
+	rw.log.Infof("starting namespace informer")
+	go rw.nsInformer.Run(stopChan)
 
 	rw.waitForCacheSync(stopChan, rw.podInformer, "pod")
 	rw.waitForCacheSync(stopChan, rw.nodeInformer, "node")
 	rw.waitForCacheSync(stopChan, rw.nagInformer, "nag")
 	rw.waitForCacheSync(stopChan, rw.parInformer, "par")
 	rw.waitForCacheSync(stopChan, rw.cparInformer, "cpar")
+	rw.waitForCacheSync(stopChan, rw.nsInformer, "namespace")
 
 	// start controller queue processing
 	if rw.config.NagController.ShouldRun {
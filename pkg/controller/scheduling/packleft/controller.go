@@ -1,12 +1,15 @@
 package packleft
 
 import (
+	"time"
+
 	assignmentsv1alpha1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1alpha1"
 	valet "github.com/domoinc/kube-valet/pkg/client/clientset/versioned"
 	"github.com/op/go-logging"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/domoinc/kube-valet/pkg/metrics"
 	"github.com/domoinc/kube-valet/pkg/queues"
@@ -23,17 +26,20 @@ type Controller struct {
 	nodeIndex   cache.Indexer
 	log         *logging.Logger
 	registry    *metrics.Registry
+	// coalesceWindow is how long triggers from pod and node changes are collected before a nag is rebalanced
+	coalesceWindow time.Duration
 }
 
 // NewController creates a new packleft.Controller
-func NewController(nagIndex cache.Indexer, nodeIndex cache.Indexer, podIndex cache.Indexer, kubeClient kubernetes.Interface, valetClient valet.Interface, threadiness int, stopChannel chan struct{}) *Controller {
+func NewController(nagIndex cache.Indexer, nodeIndex cache.Indexer, nodeUsage *utils.NodeUsageTracker, kubeClient kubernetes.Interface, valetClient valet.Interface, recorder record.EventRecorder, identity string, dryRun bool, threadiness int, coalesceWindow time.Duration, stopChannel chan struct{}) *Controller {
 	return &Controller{
-		queue:     queues.NewRetryingWorkQueue("NodeAssignmentGroup", nagIndex, threadiness, stopChannel),
-		plm:       NewManager(nagIndex, nodeIndex, podIndex, kubeClient, valetClient),
-		nagIndex:  nagIndex,
-		nodeIndex: nodeIndex,
-		log:       logging.MustGetLogger("PackLeftSchedulingController"),
-		registry:  metrics.NewRegistry(),
+		queue:          queues.NewRetryingWorkQueue("NodeAssignmentGroup", nagIndex, threadiness, stopChannel),
+		plm:            NewManager(nagIndex, nodeIndex, nodeUsage, kubeClient, valetClient, recorder, identity, dryRun),
+		nagIndex:       nagIndex,
+		nodeIndex:      nodeIndex,
+		log:            logging.MustGetLogger("PackLeftSchedulingController"),
+		registry:       metrics.NewRegistry(),
+		coalesceWindow: coalesceWindow,
 	}
 }
 
@@ -53,7 +59,10 @@ func (plc *Controller) Run() {
 				return err
 			}
 		} else {
-			plc.plm.RebalanceNag(nag, metric)
+			if requeueAfter := plc.plm.RebalanceNag(nag, metric); requeueAfter > 0 {
+				plc.log.Debugf("requeueing nag %s in %s", nag.Name, requeueAfter)
+				plc.queue.AddItemAfter(nag, requeueAfter)
+			}
 			//clean up nodes that are no longer part of the nag but have labels
 			plc.plm.CleanUnassignedNodes(nag)
 		}
@@ -80,7 +89,7 @@ func (plc *Controller) ProcessPackLeftNagsForNode(node *corev1.Node) {
 	// get the nags that apply to this node
 	nags := plc.getNodeAssignmentGroupsWithPackLeft(node)
 	for _, nag := range nags {
-		plc.OnAddNag(nag)
+		plc.queueNag(nag)
 	}
 }
 
@@ -123,10 +132,11 @@ func (plc *Controller) OnAddPod(pod *corev1.Pod) {
 }
 
 // OnUpdatePod processes pod updates for PackLeft. The rebalance is only triggered if the NodeName changes
-// this typically happens when a pod is first scheduled onto a node
+// this typically happens when a pod is first scheduled onto a node. Pods that finish free their resources
+// so they trigger a rebalance too
 func (plc *Controller) OnUpdatePod(oldPod *corev1.Pod, newPod *corev1.Pod) {
 	// pods don't move nodes, but they do go from no node to a node
-	if oldPod.Spec.NodeName != newPod.Spec.NodeName {
+	if oldPod.Spec.NodeName != newPod.Spec.NodeName || utils.PodHoldsResources(oldPod) != utils.PodHoldsResources(newPod) {
 		node := plc.getNodeHostingPod(newPod)
 		if node != nil {
 			plc.OnAddNode(node)
@@ -165,6 +175,18 @@ func (plc *Controller) getNodeAssignmentGroupsWithPackLeft(node *corev1.Node) []
 func (plc *Controller) queueAllNags() {
 	for _, obj := range plc.nagIndex.List() {
 		nag := obj.(*assignmentsv1alpha1.NodeAssignmentGroup)
+		plc.queueNag(nag)
+	}
+}
+
+// queueNag queues a rebalance of the nag for a pod or node change. Changes within the coalesce window share a
+// single rebalance.
+func (plc *Controller) queueNag(nag *assignmentsv1alpha1.NodeAssignmentGroup) {
+	if plc.coalesceWindow <= 0 {
 		plc.queue.AddItem(nag)
+		return
+	}
+	if plc.queue.AddItemCoalesced(nag, plc.coalesceWindow) {
+		plc.registry.GetPackLeftCoalescedTriggers(nag.Name).Inc()
 	}
 }
@@ -1,9 +1,10 @@
 package packleft
 
 import (
-	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
+	"time"
 
 	assignmentsv1alpha1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1alpha1"
 	valet "github.com/domoinc/kube-valet/pkg/client/clientset/versioned"
@@ -12,10 +13,9 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 )
 
@@ -27,29 +27,43 @@ const (
 type Manager struct {
 	nagIndex    cache.Indexer
 	nodeIndex   cache.Indexer
-	podIndex    cache.Indexer
+	nodeUsage   *utils.NodeUsageTracker
 	valetClient valet.Interface
 	kubeClient  kubernetes.Interface
+	recorder    record.EventRecorder
+	identity    string
+	dryRun      bool
+	now         func() time.Time
 	log         *logging.Logger
 }
 
-// NewManager creates a new manager
-func NewManager(nagIndex cache.Indexer, nodeIndex cache.Indexer, podIndex cache.Indexer, kubeClient kubernetes.Interface, valetClient valet.Interface) *Manager {
+// NewManager creates a new manager. nodeUsage has to be fed the events of all pods
+func NewManager(nagIndex cache.Indexer, nodeIndex cache.Indexer, nodeUsage *utils.NodeUsageTracker, kubeClient kubernetes.Interface, valetClient valet.Interface, recorder record.EventRecorder, identity string, dryRun bool) *Manager {
 	return &Manager{
 		nagIndex:    nagIndex,
 		nodeIndex:   nodeIndex,
-		podIndex:    podIndex,
+		nodeUsage:   nodeUsage,
 		kubeClient:  kubeClient,
 		valetClient: valetClient,
+		recorder:    recorder,
+		identity:    identity,
+		dryRun:      dryRun,
+		now:         time.Now,
 		log:         logging.MustGetLogger("PackLeftSchedulingManager"),
 	}
 }
 
-// RebalanceNag rebalance nodes that are assigned to pack left assignments in a given nag
-func (m *Manager) RebalanceNag(nag *assignmentsv1alpha1.NodeAssignmentGroup, metric *prometheus.GaugeVec) {
+// RebalanceNag rebalance nodes that are assigned to pack left assignments in a given nag. The time after which the nag
+// has to be rebalanced again for state changes held back by MinDwellSeconds is returned. Zero when there are none.
+func (m *Manager) RebalanceNag(nag *assignmentsv1alpha1.NodeAssignmentGroup, metric *prometheus.GaugeVec) time.Duration {
 	// Ensure that the finalizer is set on the nag
 	m.ensureFinalizer(nag)
 
+	// Balance using a defaulted copy so every PackLeft assignment has its full and avoid settings
+	nag = nag.DeepCopy()
+	assignmentsv1alpha1.SetObjectDefaults_NodeAssignmentGroup(nag)
+
+	var requeueAfter time.Duration
 	packLeftNodeGroups := m.getPackLeftNodeGroups(nag)
 	m.log.Debugf("found %d node groups for nag %s with", len(packLeftNodeGroups), nag.Name)
 	for assignmentName, nodes := range packLeftNodeGroups {
@@ -57,7 +71,9 @@ func (m *Manager) RebalanceNag(nag *assignmentsv1alpha1.NodeAssignmentGroup, met
 			m.log.Infof("rebalancing  %d nodes in assignment %s.%s", len(nodes), nag.Name, assignmentName)
 			labelKey := getLabelKey(nag.Name)
 			if assignment, ok := m.getAssignmentByName(assignmentName, nag); ok {
-				m.balanceNodes(nodes, labelKey, nag, assignment, metric)
+				if wait := m.balanceNodes(nodes, labelKey, nag, assignment, metric); wait > 0 && (requeueAfter == 0 || wait < requeueAfter) {
+					requeueAfter = wait
+				}
 			} else {
 				m.log.Warningf("Assignment %s doesn't exist in the NodeAssignmentGroup", assignmentName)
 			}
@@ -65,6 +81,7 @@ func (m *Manager) RebalanceNag(nag *assignmentsv1alpha1.NodeAssignmentGroup, met
 			m.log.Warningf("No nodes found for assignment %s on nag %s", assignmentName, nag.Name)
 		}
 	}
+	return requeueAfter
 }
 
 // CleanAllNodes clears all attributes for a pack left nag from all nodes
@@ -74,7 +91,7 @@ func (m *Manager) CleanAllNodes(nag *assignmentsv1alpha1.NodeAssignmentGroup) er
 		node := obj.(*corev1.Node)
 		if (!m.NodeHasPackLeftAssignment(node, nag) && m.NodeHasPackLeftAttributes(node, nag)) || !NodeCanBeBalanced(node) {
 			newNode := m.unassignNode(node, labelKey)
-			if err := m.patchNodeState(node, newNode); err != nil {
+			if err := m.patchNodeState(nag, node, newNode, labelKey); err != nil {
 				return err
 			}
 		}
@@ -91,7 +108,7 @@ func (m *Manager) CleanUnassignedNodes(nag *assignmentsv1alpha1.NodeAssignmentGr
 		if !m.NodeHasPackLeftAssignment(node, nag) && m.NodeHasPackLeftAttributes(node, nag) {
 			m.log.Debugf("Node '%s' has packleft attributes for nag '%s' but is not assigned to it anymore. Clearing attributes", node.Name, nag.Name)
 			newNode := m.unassignNode(node, labelKey)
-			m.patchNodeState(node, newNode)
+			m.patchNodeState(nag, node, newNode, labelKey)
 		}
 	}
 }
@@ -143,27 +160,20 @@ func newAssignmentContext(percent float64, node *corev1.Node, assignment *assign
 	}
 }
 
-func (m *Manager) balanceNodes(nodes []*corev1.Node, labelKey string, nag *assignmentsv1alpha1.NodeAssignmentGroup, assignment *assignmentsv1alpha1.NodeAssignment, metric *prometheus.GaugeVec) {
+// balanceNodes sets the pack left state of the nodes of an assignment. The time until the first state change that is
+// held back by MinDwellSeconds may be made is returned. Zero when none are.
+func (m *Manager) balanceNodes(nodes []*corev1.Node, labelKey string, nag *assignmentsv1alpha1.NodeAssignmentGroup, assignment *assignmentsv1alpha1.NodeAssignment, metric *prometheus.GaugeVec) time.Duration {
 	var nodesWithPercent []*assignmentContext
-	//create this first so it doesn't get created twice for every node
-	podsOnNodes := m.getPodsOnNodes()
 	for _, node := range nodes {
 		if !NodeCanBeBalanced(node) {
 			continue //filter out unschedulable nodes
 		}
-		var percentFull float64
-		memFull := m.getNodePercentFullMemory(node, podsOnNodes)
-		cpuFull := m.getNodePercentFullCPU(node, podsOnNodes)
-		if memFull > cpuFull {
-			percentFull = memFull
-		} else {
-			percentFull = cpuFull
-		}
+		percentFull := getNodePercentFull(node, m.nodeUsage.Get(node.Name), assignment.PackLeft)
 		nodesWithPercent = append(nodesWithPercent, newAssignmentContext(percentFull, node, assignment))
 	}
 	if len(nodesWithPercent) < 1 {
 		m.log.Warningf("No schedulable nodes found. Unable to balance nodes")
-		return
+		return 0
 	}
 
 	// sort the nodes by fullest first
@@ -175,16 +185,13 @@ func (m *Manager) balanceNodes(nodes []*corev1.Node, labelKey string, nag *assig
 		return nodesWithPercent[i].percentFull > nodesWithPercent[j].percentFull
 	})
 
-	// Determine the avoidBufferSize
-	avoidBufferSize := 0
-	if assignment.PackLeft != nil {
-		if assignment.PackLeft.PercentAvoid != nil {
-			// get the avoidBufferSize based on PercentAvoid. Rounding down
-			avoidBufferSize = int(float32(len(nodes)) * float32(*assignment.PackLeft.PercentAvoid) / 100.0)
-		}
-		// if NumAvoid is larger, use it instead
-		if assignment.PackLeft.NumAvoid > avoidBufferSize {
-			avoidBufferSize = assignment.PackLeft.NumAvoid
+	// Determine the avoidBufferSize. The assignment has been defaulted so PackLeft is always set
+	avoidBufferSize := assignment.PackLeft.NumAvoid
+	if assignment.PackLeft.PercentAvoid != nil {
+		// get the avoidBufferSize based on PercentAvoid. Rounding down
+		// if it is larger than NumAvoid, use it instead
+		if percentAvoid := int(float32(len(nodes)) * float32(*assignment.PackLeft.PercentAvoid) / 100.0); percentAvoid > avoidBufferSize {
+			avoidBufferSize = percentAvoid
 		}
 	}
 	// avoidBufferSize cannot be < 1
@@ -194,42 +201,84 @@ func (m *Manager) balanceNodes(nodes []*corev1.Node, labelKey string, nag *assig
 	}
 	m.log.Debugf("attempting to leave %d nodes as 'Avoid' nodes", avoidBufferSize)
 
-	// Determine fullPercent
-	fullPercent := .8 // Default is 80%
-	if assignment.PackLeft != nil && assignment.PackLeft.FullPercent != nil {
-		fullPercent = float64(*assignment.PackLeft.FullPercent) / float64(100)
+	// Determine fullPercent and the lower fullExitPercent that Use nodes have to drop to before they are no longer full
+	fullPercent := float64(*assignment.PackLeft.FullPercent) / float64(100)
+	fullExitPercent := fullPercent
+	if assignment.PackLeft.FullExitPercent != nil {
+		fullExitPercent = float64(*assignment.PackLeft.FullExitPercent) / float64(100)
 	}
-	m.log.Debugf("nodes will be considered full at %%%v", fullPercent*100)
+	m.log.Debugf("nodes will be considered full at %%%v and stop being full at %%%v", fullPercent*100, fullExitPercent*100)
+
+	now := m.now()
+	minDwell := time.Duration(assignment.PackLeft.MinDwellSeconds) * time.Second
+	var requeueAfter time.Duration
 
 	denyCount := 0
 	avoidCount := 0
 
 	firstCtx := nodesWithPercent[0]
 	m.log.Debugf("assigning node %s to be first full node", firstCtx.node.Name)
-	firstNode := m.assignNode(firstCtx, nodeUse, labelKey, metric)
-	m.patchNodeState(firstCtx.node, firstNode)
+	firstNode := m.assignNode(nag, firstCtx, nodeUse, labelKey, metric)
+	m.patchNodeState(nag, firstCtx.node, firstNode, labelKey)
 
 	for _, ctx := range nodesWithPercent[1:] {
-		var newNode *corev1.Node
-		// these calls actually save the data to kubernetes
-		if ctx.percentFull > fullPercent {
-			m.log.Debugf("assigned node %s to be Use", ctx.node.Name)
-			newNode = m.assignNode(ctx, nodeUse, labelKey, metric)
+		current := nodePackLeftState(ctx.node.Labels[labelKey])
+		var state nodePackLeftState
+		if ctx.percentFull > fullPercent || (current == nodeUse && ctx.percentFull > fullExitPercent) {
+			state = nodeUse
 		} else if avoidCount < avoidBufferSize {
-			m.log.Debugf("assigned node %s to be Avoid", ctx.node.Name)
-			newNode = m.assignNode(ctx, nodeAvoid, labelKey, metric)
-			avoidCount++
+			state = nodeAvoid
 		} else {
-			m.log.Debugf("assigned node %s to be Deny", ctx.node.Name)
-			newNode = m.assignNode(ctx, nodeDeny, labelKey, metric)
+			state = nodeDeny
+		}
+
+		if wait := dwellRemaining(ctx.node, labelKey, state, minDwell, now); wait > 0 {
+			m.log.Debugf("keeping node %s %s for %s before it can be %s", ctx.node.Name, current, wait, state)
+			state = current
+			if requeueAfter == 0 || wait < requeueAfter {
+				requeueAfter = wait
+			}
+		}
+
+		// Count the state the node ends up in so that nodes kept in their state by the dwell time leave their place in
+		// the avoid buffer to the next nodes
+		switch state {
+		case nodeAvoid:
+			avoidCount++
+		case nodeDeny:
 			denyCount++
 		}
-		m.patchNodeState(ctx.node, newNode)
+
+		// these calls actually save the data to kubernetes
+		m.log.Debugf("assigned node %s to be %s", ctx.node.Name, state)
+		newNode := m.assignNode(nag, ctx, state, labelKey, metric)
+		m.patchNodeState(nag, ctx.node, newNode, labelKey)
 	}
 
 	if avoidBufferSize != avoidCount {
 		m.log.Warningf("avoid buffer size on %s.%s is lower than specified", nag.GetName(), assignment.Name)
 	}
+	return requeueAfter
+}
+
+// dwellRemaining returns how much longer the node has to keep its current state before it can be moved to the given
+// more restrictive state. The last change of the state is taken from the provenance of the node. Zero is returned when
+// the move is allowed.
+func dwellRemaining(node *corev1.Node, labelKey string, state nodePackLeftState, minDwell time.Duration, now time.Time) time.Duration {
+	current := nodePackLeftState(node.Labels[labelKey])
+	currentRank, ok := stateRestrictiveness[current]
+	if !ok || minDwell == 0 || stateRestrictiveness[state] <= currentRank {
+		return 0
+	}
+
+	changed, err := time.Parse(time.RFC3339, assignmentsv1alpha1.GetProvenance(node, labelKey)[assignmentsv1alpha1.ProvenanceAssignedAt])
+	if err != nil {
+		return 0
+	}
+	if wait := changed.Add(minDwell).Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
 }
 
 type nodePackLeftState string
@@ -241,38 +290,68 @@ const (
 	nodeLabelKey                   = "nag.packleft.scheduling.kube-valet.io/%s"
 )
 
+// stateRestrictiveness orders the states by how much they keep new pods off a node
+var stateRestrictiveness = map[nodePackLeftState]int{
+	nodeUse:   0,
+	nodeAvoid: 1,
+	nodeDeny:  2,
+}
+
 func getLabelKey(nag string) string {
 	return fmt.Sprintf(nodeLabelKey, nag)
 }
 
-func (m *Manager) patchNodeState(oldNode *corev1.Node, newNode *corev1.Node) error {
-
-	oldData, err := json.Marshal(oldNode)
-	if err != nil {
-		return err
+// patchNodeState writes the pack left label, taint and provenance of newNode to the node. Only these are copied so
+// that changes made to the node by other controllers are kept if the patch has to be retried. An event is recorded on
+// the node when its state changes or the patch fails. In dry run mode the state change is only logged and recorded
+// as an event on the nag.
+func (m *Manager) patchNodeState(nag *assignmentsv1alpha1.NodeAssignmentGroup, oldNode *corev1.Node, newNode *corev1.Node, labelKey string) error {
+	if m.dryRun {
+		if oldState, newState := oldNode.Labels[labelKey], newNode.Labels[labelKey]; oldState != newState {
+			m.log.Noticef("Dry run of NAG %s would change the pack left state of %s from %s to %s", nag.Name, oldNode.Name, stateOrNone(oldState), stateOrNone(newState))
+			m.recorder.Eventf(nag, corev1.EventTypeNormal, utils.EventReasonDryRun, "Would change the pack left state of %s from %s to %s", oldNode.Name, stateOrNone(oldState), stateOrNone(newState))
+		}
+		return nil
 	}
 
-	newData, err := json.Marshal(newNode)
-	if err != nil {
-		return err
-	}
+	_, err := utils.PatchNode(m.kubeClient, oldNode, func(node *corev1.Node) {
+		if state, ok := newNode.Labels[labelKey]; ok {
+			if node.Labels == nil {
+				node.Labels = make(map[string]string)
+			}
+			node.Labels[labelKey] = state
+		} else {
+			delete(node.Labels, labelKey)
+		}
+
+		m.removeTaint(node, labelKey)
+		for _, taint := range newNode.Spec.Taints {
+			if taint.Key == labelKey {
+				node.Spec.Taints = append(node.Spec.Taints, taint)
+			}
+		}
 
-	patchBytes, err := strategicpatch.CreateTwoWayMergePatch(oldData, newData, corev1.Node{})
+		assignmentsv1alpha1.CopyProvenance(node, newNode, labelKey)
+	})
 	if err != nil {
+		m.recorder.Eventf(oldNode, corev1.EventTypeWarning, utils.EventReasonPatchFailed, "Failed to patch the pack left state for NodeAssignmentGroup %s: %v", nag.Name, err)
 		return err
 	}
 
-	// an empty patch of "{}" has a len of 2. No need to send a zero patch
-	if !utils.IsEmptyPatch(patchBytes) {
-		_, err = m.kubeClient.CoreV1().Nodes().Patch(oldNode.Name, types.StrategicMergePatchType, patchBytes)
-		if err != nil {
-			return err
-		}
+	if oldState, newState := oldNode.Labels[labelKey], newNode.Labels[labelKey]; oldState != newState {
+		m.recorder.Eventf(oldNode, corev1.EventTypeNormal, utils.EventReasonPackLeftStateChanged, "Pack left state for NodeAssignmentGroup %s changed from %s to %s", nag.Name, stateOrNone(oldState), stateOrNone(newState))
 	}
-
 	return nil
 }
 
+// stateOrNone returns "None" for nodes without a pack left state
+func stateOrNone(state string) string {
+	if state == "" {
+		return "None"
+	}
+	return state
+}
+
 func (m *Manager) unassignNode(node *corev1.Node, labelKey string) *corev1.Node {
 	newNode := node.DeepCopy()
 
@@ -282,18 +361,26 @@ func (m *Manager) unassignNode(node *corev1.Node, labelKey string) *corev1.Node
 	//remove the taint
 	m.removeTaint(newNode, labelKey)
 
+	assignmentsv1alpha1.RemoveProvenance(newNode, labelKey)
+
 	return newNode
 }
 
 // Full nodes have no taint
 // Filling nodes have a PreferNoSchedule taint
 // Emptying nodes have a NoScheduleTaint
-func (m *Manager) assignNode(ctx *assignmentContext, state nodePackLeftState, labelKey string, metric *prometheus.GaugeVec) *corev1.Node {
+// Provenance is only recorded when the state changes so that rebalancing doesn't patch nodes that stay the same
+func (m *Manager) assignNode(nag *assignmentsv1alpha1.NodeAssignmentGroup, ctx *assignmentContext, state nodePackLeftState, labelKey string, metric *prometheus.GaugeVec) *corev1.Node {
 
 	metric.With(prometheus.Labels{"node_assignment": ctx.assignment.Name, "node_name": ctx.node.Name, "pack_left_state": string(state)}).Set(ctx.percentFull)
 
 	newNode := ctx.node.DeepCopy()
 
+	if previous := newNode.Labels[labelKey]; previous != string(state) {
+		nag.SetProvenance(newNode, labelKey, m.identity, m.now(), previous)
+		newNode.Annotations[assignmentsv1alpha1.ProvenanceKey(assignmentsv1alpha1.ProvenancePercentFull, labelKey)] = strconv.FormatFloat(ctx.percentFull*100, 'f', 1, 64)
+	}
+
 	//replace label
 	newNode.ObjectMeta.Labels[labelKey] = string(state)
 
@@ -372,43 +459,50 @@ func (m *Manager) getTargetedNodes(assignmentGroup *assignmentsv1alpha1.NodeAssi
 	return rtn
 }
 
-func (m *Manager) getNodePercentFullMemory(node *corev1.Node, podsOnNodes map[string][]*corev1.Pod) float64 {
-	var bytesRequested int64
-	for _, pod := range podsOnNodes[node.Name] {
-		// Completed pods don't count against schedulable capacity
-		if pod.Status.Phase != corev1.PodSucceeded {
-			for _, container := range pod.Spec.Containers {
-				bytesRequested += container.Resources.Requests.Memory().Value()
-			}
+// getNodePercentFull returns how full the node is for the pack left configuration of an assignment. The percent full
+// of each resource is combined by the aggregation of the configuration. Resources the node has none of are left out.
+func getNodePercentFull(node *corev1.Node, usage utils.NodeUsage, pl *assignmentsv1alpha1.PackLeftScheduling) float64 {
+	var max, weighted float64
+	var totalWeight int
+	for _, r := range pl.Resources {
+		full, ok := getResourcePercentFull(node, usage, r.Name)
+		if !ok {
+			continue
+		}
+		if full > max {
+			max = full
 		}
+		weight := assignmentsv1alpha1.PackLeftResourceWeightDefault
+		if r.Weight != nil {
+			weight = *r.Weight
+		}
+		weighted += full * float64(weight)
+		totalWeight += weight
 	}
 
-	return float64(bytesRequested) / float64(node.Status.Allocatable.Memory().Value())
-}
-
-func (m *Manager) getNodePercentFullCPU(node *corev1.Node, podsOnNodes map[string][]*corev1.Pod) float64 {
-	var minutesRequested int64
-	for _, pod := range podsOnNodes[node.Name] {
-		// Completed pods don't count against schedulable capacity
-		if pod.Status.Phase != corev1.PodSucceeded {
-			for _, container := range pod.Spec.Containers {
-				minutesRequested += container.Resources.Requests.Cpu().ScaledValue(-3)
-			}
+	if pl.Aggregation == assignmentsv1alpha1.PackLeftAggregationWeightedAverage {
+		if totalWeight == 0 {
+			return 0
 		}
+		return weighted / float64(totalWeight)
 	}
-
-	return float64(minutesRequested) / float64(node.Status.Allocatable.Cpu().ScaledValue(-3))
+	return max
 }
 
-// no need to optimize by only looking for one node because all pods have to be looked at anyway
-// might want to memozie/cache this somehow
-func (m *Manager) getPodsOnNodes() map[string][]*corev1.Pod {
-	rtn := make(map[string][]*corev1.Pod)
-	for _, obj := range m.podIndex.List() {
-		pod := obj.(*corev1.Pod)
-		rtn[pod.Spec.NodeName] = append(rtn[pod.Spec.NodeName], pod)
+// getResourcePercentFull returns how much of the allocatable amount of a resource is taken by the requests of the pods
+// on the node. The pods resource is the number of pods. False is returned when the node has none of the resource.
+func getResourcePercentFull(node *corev1.Node, usage utils.NodeUsage, name corev1.ResourceName) (float64, bool) {
+	allocatable, ok := node.Status.Allocatable[name]
+	if !ok || allocatable.IsZero() {
+		return 0, false
 	}
-	return rtn
+
+	if name == corev1.ResourcePods {
+		return float64(usage.Pods) / float64(allocatable.Value()), true
+	}
+
+	quantity := usage.Requests[name]
+	return float64(quantity.MilliValue()) / float64(allocatable.MilliValue()), true
 }
 
 func (m *Manager) getPackLeftNodeAssignment(nag *assignmentsv1alpha1.NodeAssignmentGroup) []*assignmentsv1alpha1.NodeAssignment {
@@ -432,6 +526,10 @@ func getAllAssignments(nag *assignmentsv1alpha1.NodeAssignmentGroup) []assignmen
 }
 
 func (m *Manager) ensureFinalizer(nag *assignmentsv1alpha1.NodeAssignmentGroup) error {
+	if m.dryRun {
+		return nil
+	}
+
 	// Only add finalizer if it's not already present
 	for _, f := range nag.GetFinalizers() {
 		if f == PackLeftFinalizer {
@@ -464,7 +562,12 @@ func (m *Manager) ensureFinalizer(nag *assignmentsv1alpha1.NodeAssignmentGroup)
 	return nil
 }
 
+// RemoveFinalizer removes the pack left finalizer from the nag. Nothing is written in dry run mode
 func (m *Manager) RemoveFinalizer(nag *assignmentsv1alpha1.NodeAssignmentGroup) error {
+	if m.dryRun {
+		return nil
+	}
+
 	retryErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		// Retrieve the latest version before attempting update
 		// RetryOnConflict uses exponential backoff to avoid exhausting the apiserver
@@ -1,15 +1,23 @@
 package packleft
 
 import (
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	fakekube "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 
+	assignmentsv1alpha1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1alpha1"
 	fakevalet "github.com/domoinc/kube-valet/pkg/client/clientset/versioned/fake"
+	"github.com/domoinc/kube-valet/pkg/utils"
 )
 
 func fakeKeyFunc(obj interface{}) (string, error) {
@@ -17,15 +25,6 @@ func fakeKeyFunc(obj interface{}) (string, error) {
 }
 
 func TestGetNodePercentFullMemory(t *testing.T) {
-	fakeIndexer := cache.NewIndexer(fakeKeyFunc, cache.Indexers{})
-	m := NewManager(
-		fakeIndexer,
-		fakeIndexer,
-		fakeIndexer,
-		fakekube.NewSimpleClientset(),
-		fakevalet.NewSimpleClientset(),
-	)
-
 	testNode := &corev1.Node{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "testnode",
@@ -68,7 +67,7 @@ func TestGetNodePercentFullMemory(t *testing.T) {
 			})
 		}
 
-		pFull := m.getNodePercentFullMemory(testNode, map[string][]*corev1.Pod{"testnode": testPods})
+		pFull, _ := getResourcePercentFull(testNode, utils.NodeUsageOf(testPods), corev1.ResourceMemory)
 
 		if pFull != tc.expected {
 			t.Errorf("Unexpected result: got %f; expected %f", pFull, tc.expected)
@@ -77,16 +76,6 @@ func TestGetNodePercentFullMemory(t *testing.T) {
 }
 
 func TestGetNodePercentFullCPU(t *testing.T) {
-	// No need for real indexers for this test
-	fakeIndexer := cache.NewIndexer(fakeKeyFunc, cache.Indexers{})
-	m := NewManager(
-		fakeIndexer,
-		fakeIndexer,
-		fakeIndexer,
-		fakekube.NewSimpleClientset(),
-		fakevalet.NewSimpleClientset(),
-	)
-
 	testNode := &corev1.Node{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "testnode",
@@ -129,10 +118,244 @@ func TestGetNodePercentFullCPU(t *testing.T) {
 			})
 		}
 
-		pFull := m.getNodePercentFullCPU(testNode, map[string][]*corev1.Pod{"testnode": testPods})
+		pFull, _ := getResourcePercentFull(testNode, utils.NodeUsageOf(testPods), corev1.ResourceCPU)
 
 		if pFull != tc.expected {
 			t.Errorf("Unexpected result: got %f; expected %f", pFull, tc.expected)
 		}
 	}
 }
+
+func TestGetNodePercentFull(t *testing.T) {
+	license := corev1.ResourceName("example.com/license")
+	testNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "testnode"},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10"),
+				corev1.ResourceMemory: resource.MustParse("20Gi"),
+				corev1.ResourcePods:   resource.MustParse("8"),
+				license:               resource.MustParse("2"),
+			},
+		},
+	}
+
+	// 5 running pods use half the cpu, a quarter of the memory, 5 of 8 pods and all licenses
+	var testPods []*corev1.Pod
+	for i := 0; i < 6; i++ {
+		requests := corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1"),
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		}
+		if i < 2 {
+			requests[license] = resource.MustParse("1")
+		}
+		pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{Resources: corev1.ResourceRequirements{Requests: requests}}}}}
+		if i == 5 {
+			pod.Status.Phase = corev1.PodSucceeded
+		}
+		testPods = append(testPods, pod)
+	}
+
+	zero, one, three := 0, 1, 3
+	testCases := []struct {
+		name        string
+		resources   []assignmentsv1alpha1.PackLeftResource
+		aggregation assignmentsv1alpha1.PackLeftAggregation
+		expected    float64
+	}{
+		{"Default", []assignmentsv1alpha1.PackLeftResource{{Name: corev1.ResourceCPU, Weight: &one}, {Name: corev1.ResourceMemory, Weight: &one}}, assignmentsv1alpha1.PackLeftAggregationMax, 0.5},
+		{"Pods", []assignmentsv1alpha1.PackLeftResource{{Name: corev1.ResourcePods, Weight: &one}}, assignmentsv1alpha1.PackLeftAggregationMax, 0.625},
+		{"Extended", []assignmentsv1alpha1.PackLeftResource{{Name: corev1.ResourcePods, Weight: &one}, {Name: license, Weight: &one}}, assignmentsv1alpha1.PackLeftAggregationMax, 1.0},
+		{"WeightedAverage", []assignmentsv1alpha1.PackLeftResource{{Name: corev1.ResourceCPU, Weight: &one}, {Name: corev1.ResourcePods, Weight: &three}}, assignmentsv1alpha1.PackLeftAggregationWeightedAverage, 0.59375},
+		{"ZeroWeight", []assignmentsv1alpha1.PackLeftResource{{Name: corev1.ResourceCPU, Weight: &zero}, {Name: corev1.ResourcePods, Weight: &three}}, assignmentsv1alpha1.PackLeftAggregationWeightedAverage, 0.625},
+		{"UnsetWeight", []assignmentsv1alpha1.PackLeftResource{{Name: corev1.ResourceCPU}, {Name: corev1.ResourcePods, Weight: &three}}, assignmentsv1alpha1.PackLeftAggregationWeightedAverage, 0.59375},
+		// Resources the node does not have are left out
+		{"Missing", []assignmentsv1alpha1.PackLeftResource{{Name: "example.com/gpu", Weight: &one}, {Name: corev1.ResourceMemory, Weight: &one}}, assignmentsv1alpha1.PackLeftAggregationWeightedAverage, 0.25},
+		{"OnlyMissing", []assignmentsv1alpha1.PackLeftResource{{Name: "example.com/gpu", Weight: &one}}, assignmentsv1alpha1.PackLeftAggregationWeightedAverage, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pl := &assignmentsv1alpha1.PackLeftScheduling{Resources: tc.resources, Aggregation: tc.aggregation}
+			if pFull := getNodePercentFull(testNode, utils.NodeUsageOf(testPods), pl); pFull != tc.expected {
+				t.Errorf("Unexpected result: got %f; expected %f", pFull, tc.expected)
+			}
+		})
+	}
+}
+
+func TestBalanceNodesHysteresis(t *testing.T) {
+	nag := &assignmentsv1alpha1.NodeAssignmentGroup{ObjectMeta: metav1.ObjectMeta{Name: "testnag"}}
+	labelKey := getLabelKey(nag.Name)
+	now := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	assignedAtKey := assignmentsv1alpha1.ProvenanceKey(assignmentsv1alpha1.ProvenanceAssignedAt, labelKey)
+	recently := map[string]string{assignedAtKey: now.Add(-time.Minute).Format(time.RFC3339)}
+
+	nodeUsage := utils.NewNodeUsageTracker()
+	var nodes []*corev1.Node
+	var objs []runtime.Object
+	for _, n := range []struct {
+		name, state string
+		cpu         string
+		annotations map[string]string
+	}{
+		{"node-a", "Use", "9", nil},
+		{"node-b", "Use", "7500m", nil},   // Below fullPercent but above fullExitPercent
+		{"node-c", "Deny", "5", recently}, // Less restrictive states are never held back
+		{"node-d", "Use", "6", recently},  // Has to stay Use until minDwellSeconds have passed, leaving Avoid to node-c
+		{"node-e", "Deny", "0", nil},
+	} {
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: n.name, Labels: map[string]string{labelKey: n.state}, Annotations: n.annotations},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10"), corev1.ResourceMemory: resource.MustParse("10Gi")},
+				Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		}
+		nodes = append(nodes, node)
+		objs = append(objs, node)
+		nodeUsage.SetPod(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: n.name, Namespace: "default"},
+			Spec: corev1.PodSpec{NodeName: n.name, Containers: []corev1.Container{{Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(n.cpu)},
+			}}}},
+		})
+	}
+
+	fakeIndexer := cache.NewIndexer(fakeKeyFunc, cache.Indexers{})
+	m := NewManager(fakeIndexer, fakeIndexer, nodeUsage, fakekube.NewSimpleClientset(objs...), fakevalet.NewSimpleClientset(), &record.FakeRecorder{}, "valet-0", false)
+	m.now = func() time.Time { return now }
+
+	fullPercent, fullExitPercent := 80, 70
+	assignment := &assignmentsv1alpha1.NodeAssignment{
+		Name:           "packed",
+		SchedulingMode: assignmentsv1alpha1.NodeAssignmentSchedulingModePackLeft,
+		PackLeft:       &assignmentsv1alpha1.PackLeftScheduling{FullPercent: &fullPercent, FullExitPercent: &fullExitPercent, MinDwellSeconds: 300},
+	}
+	assignmentsv1alpha1.SetObjectDefaults_NodeAssignmentGroup(&assignmentsv1alpha1.NodeAssignmentGroup{Spec: assignmentsv1alpha1.NodeAssignmentGroupSpec{DefaultAssignment: assignment}})
+	metric := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test"}, []string{"node_assignment", "node_name", "pack_left_state"})
+
+	getStates := func() map[string]string {
+		states := make(map[string]string)
+		for _, node := range nodes {
+			patched, err := m.kubeClient.CoreV1().Nodes().Get(node.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			states[node.Name] = patched.Labels[labelKey]
+		}
+		return states
+	}
+
+	if wait := m.balanceNodes(nodes, labelKey, nag, assignment, metric); wait != 4*time.Minute {
+		t.Errorf("Expected a requeue in 4m, got %s", wait)
+	}
+	expected := map[string]string{"node-a": "Use", "node-b": "Use", "node-c": "Avoid", "node-d": "Use", "node-e": "Deny"}
+	if states := getStates(); !reflect.DeepEqual(states, expected) {
+		t.Errorf("Unexpected states: %v", states)
+	}
+
+	// Once the dwell time has passed the node can be avoided. The nodes are read back so that the second pass sees
+	// the states and provenance written by the first.
+	for i, node := range nodes {
+		patched, err := m.kubeClient.CoreV1().Nodes().Get(node.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		nodes[i] = patched
+	}
+	m.now = func() time.Time { return now.Add(5 * time.Minute) }
+	if wait := m.balanceNodes(nodes, labelKey, nag, assignment, metric); wait != 0 {
+		t.Errorf("Expected no requeue, got %s", wait)
+	}
+	expected["node-c"] = "Deny"
+	expected["node-d"] = "Avoid"
+	if states := getStates(); !reflect.DeepEqual(states, expected) {
+		t.Errorf("Unexpected states: %v", states)
+	}
+}
+
+func TestPatchNodeStateEvents(t *testing.T) {
+	fakeIndexer := cache.NewIndexer(fakeKeyFunc, cache.Indexers{})
+	nag := &assignmentsv1alpha1.NodeAssignmentGroup{ObjectMeta: metav1.ObjectMeta{Name: "testnag"}}
+	labelKey := getLabelKey(nag.Name)
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "testnode", Labels: map[string]string{labelKey: string(nodeAvoid)}}}
+	recorder := record.NewFakeRecorder(10)
+	m := NewManager(fakeIndexer, fakeIndexer, utils.NewNodeUsageTracker(), fakekube.NewSimpleClientset(node), fakevalet.NewSimpleClientset(), recorder, "valet-0", false)
+
+	ctx := newAssignmentContext(0.5, node, &assignmentsv1alpha1.NodeAssignment{Name: "packed"})
+	metric := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test"}, []string{"node_assignment", "node_name", "pack_left_state"})
+
+	// Only changes of the state are recorded
+	if err := m.patchNodeState(nag, node, m.assignNode(nag, ctx, nodeAvoid, labelKey, metric), labelKey); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := m.patchNodeState(nag, node, m.assignNode(nag, ctx, nodeDeny, labelKey, metric), labelKey); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(recorder.Events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(recorder.Events))
+	}
+	if e := <-recorder.Events; e != "Normal PackLeftStateChanged Pack left state for NodeAssignmentGroup testnag changed from Avoid to Deny" {
+		t.Errorf("Unexpected event: %s", e)
+	}
+
+	// The change is recorded on the node
+	patched, err := m.kubeClient.CoreV1().Nodes().Get("testnode", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	p := assignmentsv1alpha1.GetProvenance(patched, labelKey)
+	if p[assignmentsv1alpha1.ProvenancePrevious] != "Avoid" || p[assignmentsv1alpha1.ProvenancePercentFull] != "50.0" || p[assignmentsv1alpha1.ProvenanceAssignedBy] != "valet-0" {
+		t.Errorf("Unexpected provenance: %v", p)
+	}
+
+	// Nodes that can't be patched get a warning
+	missing := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "missing", Labels: map[string]string{}}}
+	if err := m.patchNodeState(nag, missing, m.unassignNode(missing, labelKey), labelKey); err != nil {
+		t.Fatalf("Unexpected error for an empty patch: %v", err)
+	}
+	missing.Labels[labelKey] = string(nodeUse)
+	if err := m.patchNodeState(nag, missing, m.unassignNode(missing, labelKey), labelKey); err == nil {
+		t.Fatalf("Expected an error patching a node that does not exist")
+	}
+	if e := <-recorder.Events; !strings.HasPrefix(e, "Warning PatchFailed Failed to patch the pack left state for NodeAssignmentGroup testnag") {
+		t.Errorf("Unexpected event: %s", e)
+	}
+}
+
+func TestPatchNodeStateDryRun(t *testing.T) {
+	fakeIndexer := cache.NewIndexer(fakeKeyFunc, cache.Indexers{})
+	nag := &assignmentsv1alpha1.NodeAssignmentGroup{ObjectMeta: metav1.ObjectMeta{Name: "testnag"}}
+	labelKey := getLabelKey(nag.Name)
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "testnode", Labels: map[string]string{labelKey: string(nodeAvoid)}}}
+	kubeClient := fakekube.NewSimpleClientset(node)
+	valetClient := fakevalet.NewSimpleClientset(nag)
+	recorder := record.NewFakeRecorder(10)
+	m := NewManager(fakeIndexer, fakeIndexer, utils.NewNodeUsageTracker(), kubeClient, valetClient, recorder, "valet-0", true)
+	kubeClient.ClearActions()
+	valetClient.ClearActions()
+
+	ctx := newAssignmentContext(0.5, node, &assignmentsv1alpha1.NodeAssignment{Name: "packed"})
+	metric := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test"}, []string{"node_assignment", "node_name", "pack_left_state"})
+	if err := m.patchNodeState(nag, node, m.assignNode(nag, ctx, nodeDeny, labelKey, metric), labelKey); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := m.ensureFinalizer(nag); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := m.RemoveFinalizer(nag); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(kubeClient.Actions()) != 0 || len(valetClient.Actions()) != 0 {
+		t.Errorf("Dry run used the api: %v %v", kubeClient.Actions(), valetClient.Actions())
+	}
+	if len(recorder.Events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(recorder.Events))
+	}
+	if e := <-recorder.Events; e != "Normal DryRun Would change the pack left state of testnode from Avoid to Deny" {
+		t.Errorf("Unexpected event: %s", e)
+	}
+}
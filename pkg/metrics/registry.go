@@ -1,20 +1,27 @@
 package metrics
 
 import (
+	"sync"
+
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 type Registry struct {
-	packLeftPercentFullByNag map[string]*prometheus.GaugeVec
+	packLeftPercentFullByNag       map[string]*prometheus.GaugeVec
+	packLeftCoalescedTriggersByNag map[string]prometheus.Counter
+	lock                           sync.Mutex
 }
 
 func NewRegistry() *Registry {
 	return &Registry{
-		packLeftPercentFullByNag: make(map[string]*prometheus.GaugeVec),
+		packLeftPercentFullByNag:       make(map[string]*prometheus.GaugeVec),
+		packLeftCoalescedTriggersByNag: make(map[string]prometheus.Counter),
 	}
 }
 
 func (r *Registry) GetPackLeftPercentFull(name string) *prometheus.GaugeVec {
+	r.lock.Lock()
+	defer r.lock.Unlock()
 	if _, ok := r.packLeftPercentFullByNag[name]; !ok {
 		r.packLeftPercentFullByNag[name] = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name:        "kubevalet_packleft_full_percent",
@@ -28,3 +35,17 @@ func (r *Registry) GetPackLeftPercentFull(name string) *prometheus.GaugeVec {
 	}
 	return r.packLeftPercentFullByNag[name]
 }
+
+// GetPackLeftCoalescedTriggers counts the pack left rebalances of a nag that were folded into one already pending
+func (r *Registry) GetPackLeftCoalescedTriggers(name string) prometheus.Counter {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.packLeftCoalescedTriggersByNag[name]; !ok {
+		r.packLeftCoalescedTriggersByNag[name] = prometheus.NewCounter(prometheus.CounterOpts{
+			Name:        "kubevalet_packleft_coalesced_triggers_total",
+			ConstLabels: prometheus.Labels{"node_assignment_group": name},
+		})
+		prometheus.MustRegister(r.packLeftCoalescedTriggersByNag[name])
+	}
+	return r.packLeftCoalescedTriggersByNag[name]
+}
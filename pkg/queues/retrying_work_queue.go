@@ -2,6 +2,7 @@ package queues
 
 import (
 	"errors"
+	"sync"
 	"time"
 
 	"github.com/op/go-logging"
@@ -19,6 +20,11 @@ type RetryingWorkQueue struct {
 	businessLogicFunc ItemProcessFunc
 	threadiness       int
 	stopChan          chan struct{}
+
+	// pending holds the keys added by AddItemCoalesced that have not been picked up by a worker yet, with the timer
+	// that adds them to the queue once their window has passed
+	pending     map[string]*time.Timer
+	pendingLock sync.Mutex
 }
 
 type ItemProcessFunc func(obj interface{}) error
@@ -31,6 +37,7 @@ func NewRetryingWorkQueue(queueType string, indexer cache.Indexer, threadiness i
 		indexer:     indexer,
 		threadiness: threadiness,
 		stopChan:    stopCh,
+		pending:     make(map[string]*time.Timer),
 	}
 }
 
@@ -43,6 +50,45 @@ func (rwq *RetryingWorkQueue) AddItem(obj interface{}) {
 	}
 }
 
+// AddItemAfter adds the object to the queue once the duration has passed
+func (rwq *RetryingWorkQueue) AddItemAfter(obj interface{}, after time.Duration) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err == nil {
+		rwq.queue.AddAfter(key, after)
+	} else {
+		rwq.log.Errorf("error adding add %s to queue %v", rwq.queueType, err)
+	}
+}
+
+// AddItemCoalesced adds the object to the queue once the window has passed. Adding the object again before a worker
+// picks it up does nothing, so the object is processed once for all the adds within the window. When a worker picks
+// the object up sooner, because of AddItem or AddItemAfter, the delayed add is dropped since that run covers it.
+// Returns true if the add was coalesced into one that is already pending.
+func (rwq *RetryingWorkQueue) AddItemCoalesced(obj interface{}, window time.Duration) bool {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		rwq.log.Errorf("error adding add %s to queue %v", rwq.queueType, err)
+		return false
+	}
+
+	rwq.pendingLock.Lock()
+	defer rwq.pendingLock.Unlock()
+	if _, ok := rwq.pending[key]; ok {
+		return true
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(window, func() {
+		rwq.pendingLock.Lock()
+		defer rwq.pendingLock.Unlock()
+		// The key is no longer pending, or pending for a later add, when a worker picked it up in the meantime
+		if rwq.pending[key] == timer {
+			rwq.queue.Add(key)
+		}
+	})
+	rwq.pending[key] = timer
+	return false
+}
+
 func (rwq *RetryingWorkQueue) Run(businessLogicFunc ItemProcessFunc) {
 	defer runtime.HandleCrash()
 
@@ -77,6 +123,15 @@ func (rwq *RetryingWorkQueue) processNextItem() bool {
 	// parallel.
 	defer rwq.queue.Done(key)
 
+	// Clear the pending key before processing so that adds made during this run queue another one. This run covers
+	// the pending add, so its timer is stopped if it has not fired yet.
+	rwq.pendingLock.Lock()
+	if timer, ok := rwq.pending[key.(string)]; ok {
+		timer.Stop()
+		delete(rwq.pending, key.(string))
+	}
+	rwq.pendingLock.Unlock()
+
 	// if the indexer has not been set lets retry it
 	if rwq.indexer == nil {
 		rwq.handleErr(errors.New("indexer has not been set yet deferring to retry logic"), key)
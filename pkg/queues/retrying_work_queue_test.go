@@ -0,0 +1,111 @@
+package queues
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestAddItemCoalesced(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}}
+	indexer.Add(node)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	rwq := NewRetryingWorkQueue("Node", indexer, 1, stopCh)
+
+	var lock sync.Mutex
+	processed := 0
+	go rwq.Run(func(obj interface{}) error {
+		lock.Lock()
+		defer lock.Unlock()
+		processed++
+		return nil
+	})
+	getProcessed := func() int {
+		lock.Lock()
+		defer lock.Unlock()
+		return processed
+	}
+
+	window := 50 * time.Millisecond
+	if rwq.AddItemCoalesced(node, window) {
+		t.Errorf("First add should not be coalesced")
+	}
+	for i := 0; i < 5; i++ {
+		if !rwq.AddItemCoalesced(node, window) {
+			t.Errorf("Add %d within the window should be coalesced", i)
+		}
+	}
+
+	if err := waitFor(func() bool { return getProcessed() == 1 }); err != nil {
+		t.Fatalf("Expected the node to be processed once, got %d", getProcessed())
+	}
+
+	// After a worker has picked the item up the next add has to be queued again
+	if rwq.AddItemCoalesced(node, window) {
+		t.Errorf("Add after processing should not be coalesced")
+	}
+	if err := waitFor(func() bool { return getProcessed() == 2 }); err != nil {
+		t.Fatalf("Expected the node to be processed twice, got %d", getProcessed())
+	}
+}
+
+func TestAddItemCoalescedWithAddItem(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}}
+	indexer.Add(node)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	rwq := NewRetryingWorkQueue("Node", indexer, 1, stopCh)
+
+	var lock sync.Mutex
+	processed := 0
+	go rwq.Run(func(obj interface{}) error {
+		lock.Lock()
+		defer lock.Unlock()
+		processed++
+		return nil
+	})
+	getProcessed := func() int {
+		lock.Lock()
+		defer lock.Unlock()
+		return processed
+	}
+
+	window := 200 * time.Millisecond
+	if rwq.AddItemCoalesced(node, window) {
+		t.Errorf("First add should not be coalesced")
+	}
+	// A plain add is processed right away and covers the coalesced one
+	rwq.AddItem(node)
+	if err := waitFor(func() bool { return getProcessed() == 1 }); err != nil {
+		t.Fatalf("Expected the node to be processed once, got %d", getProcessed())
+	}
+
+	// The delayed add was dropped so the node is not processed again once the window has passed
+	time.Sleep(2 * window)
+	if getProcessed() != 1 {
+		t.Errorf("Expected the node to be processed once in the window, got %d", getProcessed())
+	}
+
+	if rwq.AddItemCoalesced(node, window) {
+		t.Errorf("Add after processing should not be coalesced")
+	}
+	if err := waitFor(func() bool { return getProcessed() == 2 }); err != nil {
+		t.Fatalf("Expected the node to be processed twice, got %d", getProcessed())
+	}
+}
+
+func waitFor(condition func() bool) error {
+	return wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return condition(), nil
+	})
+}
@@ -0,0 +1,11 @@
+package utils
+
+// Reasons of the events recorded on NodeAssignmentGroups and Nodes
+const (
+	EventReasonAssigned             = "Assigned"
+	EventReasonUnassigned           = "Unassigned"
+	EventReasonPackLeftStateChanged = "PackLeftStateChanged"
+	EventReasonNotSatisfied         = "NotSatisfied"
+	EventReasonPatchFailed          = "PatchFailed"
+	EventReasonDryRun               = "DryRun"
+)
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// PatchNode applies mutate to a copy of node and sends the difference to the api as a strategic merge patch.
+// The patch is only accepted if the node has not changed since the passed resourceVersion. On a conflict the
+// latest version of the node is fetched and mutate is applied to it again. This keeps concurrent writers from
+// overwriting each other's labels and taints, as the taint list is always replaced as a whole.
+// The patched node, or node itself when nothing had to change, is returned.
+func PatchNode(kubeClient kubernetes.Interface, node *corev1.Node, mutate func(*corev1.Node)) (*corev1.Node, error) {
+	current := node
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		patchBytes, err := createNodePatch(current, mutate)
+		if err != nil {
+			return err
+		}
+
+		// No need to send an empty patch
+		if IsEmptyPatch(patchBytes) {
+			return nil
+		}
+
+		patched, err := kubeClient.CoreV1().Nodes().Patch(current.Name, types.StrategicMergePatchType, patchBytes)
+		if errors.IsConflict(err) {
+			// Fetch the latest version so the next attempt is based on it
+			latest, getErr := kubeClient.CoreV1().Nodes().Get(current.Name, metav1.GetOptions{})
+			if getErr != nil {
+				return getErr
+			}
+			current = latest
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		current = patched
+		return nil
+	})
+	return current, err
+}
+
+// createNodePatch creates a patch from node to the mutated copy of it with a resourceVersion precondition
+func createNodePatch(node *corev1.Node, mutate func(*corev1.Node)) ([]byte, error) {
+	mutated := node.DeepCopy()
+	mutate(mutated)
+
+	oldData, err := json.Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+
+	newData, err := json.Marshal(mutated)
+	if err != nil {
+		return nil, err
+	}
+
+	patchBytes, err := strategicpatch.CreateTwoWayMergePatch(oldData, newData, corev1.Node{})
+	if err != nil || IsEmptyPatch(patchBytes) || node.ResourceVersion == "" {
+		return patchBytes, err
+	}
+
+	// The apiserver rejects the patch with a conflict if the resourceVersion is no longer current
+	patch := map[string]interface{}{}
+	if err := json.Unmarshal(patchBytes, &patch); err != nil {
+		return nil, err
+	}
+	metadata, ok := patch["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = map[string]interface{}{}
+		patch["metadata"] = metadata
+	}
+	metadata["resourceVersion"] = node.ResourceVersion
+
+	return json.Marshal(patch)
+}
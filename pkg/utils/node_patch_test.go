@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestPatchNodeRetriesOnConflict(t *testing.T) {
+	stale := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node0", ResourceVersion: "1", Labels: map[string]string{}}}
+	latest := stale.DeepCopy()
+	latest.ResourceVersion = "2"
+	latest.Labels["other"] = "writer"
+	latest.Spec.Taints = []corev1.Taint{{Key: "other", Effect: corev1.TaintEffectNoSchedule}}
+
+	kubeClient := fakekube.NewSimpleClientset(latest)
+	var preconditions []string
+	kubeClient.PrependReactor("patch", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := struct {
+			Metadata metav1.ObjectMeta `json:"metadata"`
+		}{}
+		if err := json.Unmarshal(action.(k8stesting.PatchAction).GetPatch(), &patch); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		preconditions = append(preconditions, patch.Metadata.ResourceVersion)
+		// Mimic the apiserver rejecting a patch for an old version
+		if patch.Metadata.ResourceVersion != latest.ResourceVersion {
+			return true, nil, errors.NewConflict(schema.GroupResource{Resource: "nodes"}, "node0", nil)
+		}
+		return false, nil, nil
+	})
+
+	patched, err := PatchNode(kubeClient, stale, func(node *corev1.Node) {
+		node.Labels["mine"] = "value"
+		node.Spec.Taints = append(node.Spec.Taints, corev1.Taint{Key: "mine", Effect: corev1.TaintEffectNoSchedule})
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(preconditions) != 2 || preconditions[0] != "1" || preconditions[1] != "2" {
+		t.Errorf("Unexpected resourceVersion preconditions: %v", preconditions)
+	}
+	if patched.Labels["other"] != "writer" || patched.Labels["mine"] != "value" {
+		t.Errorf("Concurrent label change was lost: %v", patched.Labels)
+	}
+	if len(patched.Spec.Taints) != 2 {
+		t.Errorf("Concurrent taint change was lost: %v", patched.Spec.Taints)
+	}
+}
+
+func TestPatchNodeSkipsEmptyPatch(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node0", ResourceVersion: "1"}}
+	kubeClient := fakekube.NewSimpleClientset(node)
+
+	patched, err := PatchNode(kubeClient, node, func(*corev1.Node) {})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if patched != node {
+		t.Errorf("Expected the passed node to be returned")
+	}
+	for _, action := range kubeClient.Actions() {
+		if action.GetVerb() == "patch" {
+			t.Errorf("Unexpected patch sent for an unchanged node")
+		}
+	}
+}
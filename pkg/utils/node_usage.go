@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// PodNodeNameIndex is the name of the pod index by spec.nodeName
+const PodNodeNameIndex = "spec.nodeName"
+
+// PodNodeNameIndexFunc indexes pods by the node they are bound to. Pods that are not bound yet are left out.
+func PodNodeNameIndexFunc(obj interface{}) ([]string, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || pod.Spec.NodeName == "" {
+		return []string{}, nil
+	}
+	return []string{pod.Spec.NodeName}, nil
+}
+
+// PodsOnNode returns the pods bound to the node. The PodNodeNameIndex is used when the indexer has it, otherwise all
+// pods are looked at.
+func PodsOnNode(podIndex cache.Indexer, nodeName string) []*corev1.Pod {
+	var pods []*corev1.Pod
+	if nodeName == "" {
+		return pods
+	}
+	if _, ok := podIndex.GetIndexers()[PodNodeNameIndex]; ok {
+		objs, err := podIndex.ByIndex(PodNodeNameIndex, nodeName)
+		if err == nil {
+			for _, obj := range objs {
+				pods = append(pods, obj.(*corev1.Pod))
+			}
+			return pods
+		}
+	}
+	for _, obj := range podIndex.List() {
+		if pod := obj.(*corev1.Pod); pod.Spec.NodeName == nodeName {
+			pods = append(pods, pod)
+		}
+	}
+	return pods
+}
+
+// NodeUsage is what the pods bound to a node take up
+type NodeUsage struct {
+	// Requests is the sum of the requests of the pods that hold resources. See NodeRequests
+	Requests corev1.ResourceList
+	// Pods is the number of pods that hold resources
+	Pods int64
+}
+
+// NodeUsageOf returns the usage of a node with the given pods bound to it
+func NodeUsageOf(pods []*corev1.Pod) NodeUsage {
+	usage := NodeUsage{Requests: NodeRequests(pods)}
+	for _, pod := range pods {
+		if PodHoldsResources(pod) {
+			usage.Pods++
+		}
+	}
+	return usage
+}
+
+// NodeUsageTracker keeps a running NodeUsage for every node. It is fed pod events so that the usage of a node can be
+// read without looking at every pod in the cluster.
+type NodeUsageTracker struct {
+	lock sync.RWMutex
+	// nodes is the usage by node name
+	nodes map[string]*NodeUsage
+	// pods is what each counted pod added to the usage of its node, so that it can be taken away again
+	pods map[string]podUsage
+}
+
+type podUsage struct {
+	nodeName string
+	requests corev1.ResourceList
+}
+
+// NewNodeUsageTracker creates an empty NodeUsageTracker
+func NewNodeUsageTracker() *NodeUsageTracker {
+	return &NodeUsageTracker{
+		nodes: make(map[string]*NodeUsage),
+		pods:  make(map[string]podUsage),
+	}
+}
+
+// SetPod counts a pod that was added or updated. Only pods that are bound to a node and hold resources count.
+func (t *NodeUsageTracker) SetPod(pod *corev1.Pod) {
+	key := podKey(pod)
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.removePod(key)
+	if pod.Spec.NodeName == "" || !PodHoldsResources(pod) {
+		return
+	}
+
+	requests := PodRequests(pod)
+	usage, ok := t.nodes[pod.Spec.NodeName]
+	if !ok {
+		usage = &NodeUsage{Requests: corev1.ResourceList{}}
+		t.nodes[pod.Spec.NodeName] = usage
+	}
+	addResourceList(usage.Requests, requests)
+	usage.Pods++
+	t.pods[key] = podUsage{nodeName: pod.Spec.NodeName, requests: requests}
+}
+
+// DeletePod stops counting a pod that was deleted
+func (t *NodeUsageTracker) DeletePod(pod *corev1.Pod) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.removePod(podKey(pod))
+}
+
+// Get returns a copy of the usage of the node
+func (t *NodeUsageTracker) Get(nodeName string) NodeUsage {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	usage, ok := t.nodes[nodeName]
+	if !ok {
+		return NodeUsage{Requests: corev1.ResourceList{}}
+	}
+	return NodeUsage{Requests: usage.Requests.DeepCopy(), Pods: usage.Pods}
+}
+
+// removePod takes what the pod added away from its node. The lock must be held.
+func (t *NodeUsageTracker) removePod(key string) {
+	counted, ok := t.pods[key]
+	if !ok {
+		return
+	}
+	delete(t.pods, key)
+
+	usage := t.nodes[counted.nodeName]
+	usage.Pods--
+	if usage.Pods == 0 {
+		delete(t.nodes, counted.nodeName)
+		return
+	}
+	for name, quantity := range counted.requests {
+		value := usage.Requests[name]
+		value.Sub(quantity)
+		usage.Requests[name] = value
+	}
+}
+
+func podKey(pod *corev1.Pod) string {
+	return pod.Namespace + "/" + pod.Name
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newTestPod(name, nodeName string, phase corev1.PodPhase, requests ...string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       corev1.PodSpec{NodeName: nodeName, Containers: newTestContainers(requests...)},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+}
+
+func TestPodsOnNode(t *testing.T) {
+	pods := []*corev1.Pod{
+		newTestPod("a", "node0", corev1.PodRunning),
+		newTestPod("b", "node1", corev1.PodRunning),
+		newTestPod("c", "node0", corev1.PodSucceeded),
+		newTestPod("d", "", corev1.PodPending),
+	}
+
+	for name, indexers := range map[string]cache.Indexers{
+		"Indexed":   {PodNodeNameIndex: PodNodeNameIndexFunc},
+		"Unindexed": {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			podIndex := cache.NewIndexer(cache.MetaNamespaceKeyFunc, indexers)
+			for _, pod := range pods {
+				podIndex.Add(pod)
+			}
+			names := make(map[string]bool)
+			for _, pod := range PodsOnNode(podIndex, "node0") {
+				names[pod.Name] = true
+			}
+			if len(names) != 2 || !names["a"] || !names["c"] {
+				t.Errorf("Unexpected pods on node0: %v", names)
+			}
+			if found := PodsOnNode(podIndex, ""); len(found) != 0 {
+				t.Errorf("Pods that are not bound should not be on a node, got %d", len(found))
+			}
+		})
+	}
+}
+
+func TestNodeUsageTracker(t *testing.T) {
+	tracker := NewNodeUsageTracker()
+
+	tracker.SetPod(newTestPod("a", "node0", corev1.PodRunning, "1", "1Gi"))
+	tracker.SetPod(newTestPod("b", "node0", corev1.PodRunning, "500m", "512Mi"))
+	tracker.SetPod(newTestPod("c", "", corev1.PodPending, "2", "2Gi"))
+	tracker.SetPod(newTestPod("d", "node1", corev1.PodRunning, "1", "1Gi"))
+
+	expectUsage := func(nodeName string, pods int64, cpu, memory string) {
+		t.Helper()
+		usage := tracker.Get(nodeName)
+		if usage.Pods != pods {
+			t.Errorf("Expected %d pods on %s, got %d", pods, nodeName, usage.Pods)
+		}
+		if quantity := usage.Requests[corev1.ResourceCPU]; quantity.Cmp(resource.MustParse(cpu)) != 0 {
+			t.Errorf("Unexpected cpu request on %s: %s", nodeName, quantity.String())
+		}
+		if quantity := usage.Requests[corev1.ResourceMemory]; quantity.Cmp(resource.MustParse(memory)) != 0 {
+			t.Errorf("Unexpected memory request on %s: %s", nodeName, quantity.String())
+		}
+	}
+	expectUsage("node0", 2, "1500m", "1536Mi")
+	expectUsage("node1", 1, "1", "1Gi")
+
+	// Binding a pending pod counts it on its node
+	tracker.SetPod(newTestPod("c", "node0", corev1.PodRunning, "2", "2Gi"))
+	expectUsage("node0", 3, "3500m", "3584Mi")
+
+	// Finished pods stop counting
+	tracker.SetPod(newTestPod("a", "node0", corev1.PodSucceeded, "1", "1Gi"))
+	expectUsage("node0", 2, "2500m", "2560Mi")
+
+	tracker.DeletePod(newTestPod("b", "node0", corev1.PodRunning, "500m", "512Mi"))
+	tracker.DeletePod(newTestPod("d", "node1", corev1.PodRunning, "1", "1Gi"))
+	expectUsage("node0", 1, "2", "2Gi")
+	expectUsage("node1", 0, "0", "0")
+
+	// The running usage matches the one computed from the pods left
+	usage := NodeUsageOf([]*corev1.Pod{
+		newTestPod("a", "node0", corev1.PodSucceeded, "1", "1Gi"),
+		newTestPod("c", "node0", corev1.PodRunning, "2", "2Gi"),
+	})
+	expectUsage("node0", usage.Pods, "2", "2Gi")
+}
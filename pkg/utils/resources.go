@@ -0,0 +1,61 @@
+package utils
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodRequests returns the resources the scheduler reserves for the pod, the same way the NodeResourcesFit filter of
+// kube-scheduler does. Containers run together so their requests are added up. Init containers run one at a time
+// before them, so for every resource the largest init container request is used when it is more than that sum.
+//
+// The scheduler also adds the pod overhead of the RuntimeClass. The Overhead field is not part of the pod API this
+// project is built against, so it is not counted here.
+func PodRequests(pod *corev1.Pod) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, c := range pod.Spec.Containers {
+		addResourceList(requests, c.Resources.Requests)
+	}
+	for _, c := range pod.Spec.InitContainers {
+		maxResourceList(requests, c.Resources.Requests)
+	}
+	return requests
+}
+
+// PodHoldsResources returns false for pods that finished. Their resources are free for other pods again, so the
+// scheduler does not count them against the node.
+func PodHoldsResources(pod *corev1.Pod) bool {
+	return pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed
+}
+
+// NodeRequests returns the total requests of the pods that hold resources. pods are expected to be the pods bound
+// to a single node.
+func NodeRequests(pods []*corev1.Pod) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, pod := range pods {
+		if PodHoldsResources(pod) {
+			addResourceList(requests, PodRequests(pod))
+		}
+	}
+	return requests
+}
+
+// addResourceList adds the quantities of new to list
+func addResourceList(list corev1.ResourceList, new corev1.ResourceList) {
+	for name, quantity := range new {
+		if value, ok := list[name]; ok {
+			value.Add(quantity)
+			list[name] = value
+		} else {
+			list[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// maxResourceList sets every quantity of list to the larger of it and the one in new
+func maxResourceList(list corev1.ResourceList, new corev1.ResourceList) {
+	for name, quantity := range new {
+		if value, ok := list[name]; !ok || quantity.Cmp(value) > 0 {
+			list[name] = quantity.DeepCopy()
+		}
+	}
+}
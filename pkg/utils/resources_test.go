@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func newTestContainers(requests ...string) []corev1.Container {
+	var containers []corev1.Container
+	for i := 0; i+1 < len(requests); i += 2 {
+		containers = append(containers, corev1.Container{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(requests[i]),
+					corev1.ResourceMemory: resource.MustParse(requests[i+1]),
+				},
+			},
+		})
+	}
+	return containers
+}
+
+func TestPodRequests(t *testing.T) {
+	testCases := []struct {
+		name           string
+		containers     []corev1.Container
+		initContainers []corev1.Container
+		cpu, memory    string
+	}{
+		{name: "NoRequests", containers: []corev1.Container{{}}, cpu: "0", memory: "0"},
+		{name: "Single", containers: newTestContainers("500m", "1Gi"), cpu: "500m", memory: "1Gi"},
+		{name: "Sum", containers: newTestContainers("500m", "1Gi", "1500m", "512Mi"), cpu: "2", memory: "1536Mi"},
+		{
+			name:           "SmallerInitContainers",
+			containers:     newTestContainers("1", "1Gi", "1", "1Gi"),
+			initContainers: newTestContainers("1", "1Gi", "500m", "2Gi"),
+			cpu:            "2", memory: "2Gi",
+		},
+		{
+			// Init containers don't run at the same time, so only the largest one of each resource counts
+			name:           "LargerInitContainers",
+			containers:     newTestContainers("1", "1Gi"),
+			initContainers: newTestContainers("3", "512Mi", "2", "4Gi"),
+			cpu:            "3", memory: "4Gi",
+		},
+		{name: "OnlyInitContainers", initContainers: newTestContainers("250m", "128Mi"), cpu: "250m", memory: "128Mi"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: tc.containers, InitContainers: tc.initContainers}}
+			requests := PodRequests(pod)
+			if cpu := requests[corev1.ResourceCPU]; cpu.Cmp(resource.MustParse(tc.cpu)) != 0 {
+				t.Errorf("Unexpected cpu request: got %s; expected %s", cpu.String(), tc.cpu)
+			}
+			if memory := requests[corev1.ResourceMemory]; memory.Cmp(resource.MustParse(tc.memory)) != 0 {
+				t.Errorf("Unexpected memory request: got %s; expected %s", memory.String(), tc.memory)
+			}
+		})
+	}
+
+	// The requests of the pod must not be modified by the calculation
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: newTestContainers("1", "1Gi", "1", "1Gi")}}
+	PodRequests(pod)
+	if cpu := pod.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU]; cpu.String() != "1" {
+		t.Errorf("Container request was modified: %s", cpu.String())
+	}
+}
+
+func TestNodeRequests(t *testing.T) {
+	var pods []*corev1.Pod
+	for _, phase := range []corev1.PodPhase{"", corev1.PodPending, corev1.PodRunning, corev1.PodUnknown, corev1.PodSucceeded, corev1.PodFailed} {
+		pods = append(pods, &corev1.Pod{
+			Spec:   corev1.PodSpec{NodeName: "node0", Containers: newTestContainers("1", "1Gi")},
+			Status: corev1.PodStatus{Phase: phase},
+		})
+	}
+
+	// Finished pods don't count
+	requests := NodeRequests(pods)
+	if cpu := requests[corev1.ResourceCPU]; cpu.Cmp(resource.MustParse("4")) != 0 {
+		t.Errorf("Unexpected cpu request: %s", cpu.String())
+	}
+	if memory := requests[corev1.ResourceMemory]; memory.Cmp(resource.MustParse("4Gi")) != 0 {
+		t.Errorf("Unexpected memory request: %s", memory.String())
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 )
 
 type JsonPatchOperation struct {
@@ -50,14 +51,66 @@ func TargetableLabelsAreDifferent(m1 map[string]string, m2 map[string]string) bo
 	return false
 }
 
+// TargetableTaintsAreDifferent compares two taint lists and looks for changes, But ignores any taints that are
+// applied by kube-valet to avoid loops.
+func TargetableTaintsAreDifferent(t1 []corev1.Taint, t2 []corev1.Taint) bool {
+	filter := func(taints []corev1.Taint) map[corev1.Taint]struct{} {
+		m := make(map[corev1.Taint]struct{})
+		for _, t := range taints {
+			// Ignore if key contains the kube-valet domain
+			if strings.Contains(t.Key, "kube-valet.io") {
+				continue
+			}
+			// TimeAdded is not targetable
+			t.TimeAdded = nil
+			m[t] = struct{}{}
+		}
+		return m
+	}
+
+	m1, m2 := filter(t1), filter(t2)
+	if len(m1) != len(m2) {
+		return true
+	}
+	for t := range m1 {
+		if _, ok := m2[t]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
 // NodeTargetingHasChanged checks for changes in targetable aspects between two nodes
 func NodeTargetingHasChanged(oldNode *corev1.Node, newNode *corev1.Node) bool {
 	if TargetableLabelsAreDifferent(oldNode.GetLabels(), newNode.GetLabels()) {
 		return true
 	}
+	if oldNode.Spec.Unschedulable != newNode.Spec.Unschedulable {
+		return true
+	}
+	if TargetableTaintsAreDifferent(oldNode.Spec.Taints, newNode.Spec.Taints) {
+		return true
+	}
 	return false
 }
 
+// NodeEligibilityHasChanged checks for changes in the node status that decide whether a node can host an assignment
+func NodeEligibilityHasChanged(oldNode *corev1.Node, newNode *corev1.Node) bool {
+	if nodeReadyStatus(oldNode) != nodeReadyStatus(newNode) {
+		return true
+	}
+	return !apiequality.Semantic.DeepEqual(oldNode.Status.Allocatable, newNode.Status.Allocatable)
+}
+
+func nodeReadyStatus(node *corev1.Node) corev1.ConditionStatus {
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status
+		}
+	}
+	return corev1.ConditionUnknown
+}
+
 // FilterValues Takes a slice of strings and returns a new slice that is filtered by the passed function
 func FilterValues(vs []string, f func(string) bool) []string {
 	var vsf []string
@@ -68,3 +121,32 @@ func FilterValues(vs []string, f func(string) bool) []string {
 	}
 	return vsf
 }
+
+// PodSchedulingPatchOps returns the JSON patch operations that change the scheduling fields of orig into
+// those of mutated. Fields that are absent in orig are added instead of replaced.
+func PodSchedulingPatchOps(orig *corev1.Pod, mutated *corev1.Pod) []JsonPatchOperation {
+	ops := []JsonPatchOperation{}
+
+	if !apiequality.Semantic.DeepEqual(orig.Spec.NodeSelector, mutated.Spec.NodeSelector) {
+		ops = append(ops, fieldPatchOp("/spec/nodeSelector", len(orig.Spec.NodeSelector) != 0, len(mutated.Spec.NodeSelector) != 0, mutated.Spec.NodeSelector))
+	}
+	if !apiequality.Semantic.DeepEqual(orig.Spec.Affinity, mutated.Spec.Affinity) {
+		ops = append(ops, fieldPatchOp("/spec/affinity", orig.Spec.Affinity != nil, mutated.Spec.Affinity != nil, mutated.Spec.Affinity))
+	}
+	if !apiequality.Semantic.DeepEqual(orig.Spec.Tolerations, mutated.Spec.Tolerations) {
+		ops = append(ops, fieldPatchOp("/spec/tolerations", len(orig.Spec.Tolerations) != 0, len(mutated.Spec.Tolerations) != 0, mutated.Spec.Tolerations))
+	}
+
+	return ops
+}
+
+func fieldPatchOp(path string, existed bool, exists bool, value interface{}) JsonPatchOperation {
+	switch {
+	case !exists:
+		return JsonPatchOperation{Op: "remove", Path: path}
+	case !existed:
+		return JsonPatchOperation{Op: "add", Path: path, Value: value}
+	default:
+		return JsonPatchOperation{Op: "replace", Path: path, Value: value}
+	}
+}
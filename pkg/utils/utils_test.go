@@ -3,8 +3,10 @@ package utils
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -126,6 +128,93 @@ func TestNodeTargetingHasChanged(t *testing.T) {
 	}
 }
 
+var tainttests = []struct {
+	name string
+	t1   []corev1.Taint
+	t2   []corev1.Taint
+	r    bool
+}{
+	{
+		"Unchanged",
+		[]corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}},
+		[]corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}},
+		false,
+	},
+	{
+		"UnchangedIgnoreValetTaints",
+		[]corev1.Taint{{Key: "dedicated", Value: "gpu"}, {Key: "nag.assignments.kube-valet.io/nag1", Value: "assign1"}},
+		[]corev1.Taint{{Key: "dedicated", Value: "gpu"}},
+		false,
+	},
+	{
+		"UnchangedReordered",
+		[]corev1.Taint{{Key: "a"}, {Key: "b"}},
+		[]corev1.Taint{{Key: "b"}, {Key: "a"}},
+		false,
+	},
+	{
+		"EffectChange",
+		[]corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}},
+		[]corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoExecute}},
+		true,
+	},
+	{
+		"TaintAdd",
+		nil,
+		[]corev1.Taint{{Key: "dedicated", Value: "gpu"}},
+		true,
+	},
+	{
+		"TaintRemove",
+		[]corev1.Taint{{Key: "dedicated", Value: "gpu"}},
+		nil,
+		true,
+	},
+}
+
+func TestTargetableTaintChanges(t *testing.T) {
+	for _, tt := range tainttests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := TargetableTaintsAreDifferent(tt.t1, tt.t2)
+			if r != tt.r {
+				t.Errorf("got %v, want %v", r, tt.r)
+			}
+		})
+	}
+}
+
+func TestNodeTargetingHasChangedUnschedulable(t *testing.T) {
+	oldNode := &corev1.Node{}
+	newNode := &corev1.Node{Spec: corev1.NodeSpec{Unschedulable: true}}
+	if !NodeTargetingHasChanged(oldNode, newNode) {
+		t.Errorf("got false, want true")
+	}
+}
+
+func TestNodeEligibilityHasChanged(t *testing.T) {
+	oldNode := &corev1.Node{Status: corev1.NodeStatus{
+		Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue, LastHeartbeatTime: metav1.Now()}},
+	}}
+
+	heartbeat := oldNode.DeepCopy()
+	heartbeat.Status.Conditions[0].LastHeartbeatTime = metav1.NewTime(time.Now().Add(time.Minute))
+	if NodeEligibilityHasChanged(oldNode, heartbeat) {
+		t.Errorf("got true for a heartbeat, want false")
+	}
+
+	notReady := oldNode.DeepCopy()
+	notReady.Status.Conditions[0].Status = corev1.ConditionFalse
+	if !NodeEligibilityHasChanged(oldNode, notReady) {
+		t.Errorf("got false for a readiness change, want true")
+	}
+
+	resized := oldNode.DeepCopy()
+	resized.Status.Allocatable = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")}
+	if !NodeEligibilityHasChanged(oldNode, resized) {
+		t.Errorf("got false for an allocatable change, want true")
+	}
+}
+
 func TestFilter(t *testing.T) {
 	o := []string{"keep", "filterout", "keep"}
 	n := []string{"keep", "keep"}
@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ConversionReview matches the apiextensions.k8s.io/v1beta1 type sent to CRD conversion webhooks.
+// It is declared here to avoid depending on all of k8s.io/apiextensions-apiserver.
+type ConversionReview struct {
+	metav1.TypeMeta `json:",inline"`
+	Request         *ConversionRequest  `json:"request,omitempty"`
+	Response        *ConversionResponse `json:"response,omitempty"`
+}
+
+// ConversionRequest describes the objects to convert and the version to convert them to
+type ConversionRequest struct {
+	UID               types.UID              `json:"uid"`
+	DesiredAPIVersion string                 `json:"desiredAPIVersion"`
+	Objects           []runtime.RawExtension `json:"objects"`
+}
+
+// ConversionResponse contains the converted objects in the same order as the request
+type ConversionResponse struct {
+	UID              types.UID              `json:"uid"`
+	ConvertedObjects []runtime.RawExtension `json:"convertedObjects"`
+	Result           metav1.Status          `json:"result"`
+}
+
+func (s *Server) convertHandler(w http.ResponseWriter, r *http.Request) {
+	s.log.Debug("Processing conversion request")
+
+	var body []byte
+	if r.Body != nil {
+		if data, err := ioutil.ReadAll(r.Body); err == nil {
+			body = data
+		}
+	}
+	if len(body) == 0 {
+		s.log.Error("Empty body in conversion request")
+		http.Error(w, "empty body", http.StatusBadRequest)
+		return
+	}
+
+	review := &ConversionReview{}
+	if err := json.Unmarshal(body, review); err != nil || review.Request == nil {
+		s.log.Errorf("Can't decode conversion review: %v", err)
+		http.Error(w, "could not decode conversion review", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = convertObjects(review.Request)
+	if review.Response.Result.Status != metav1.StatusSuccess {
+		s.log.Errorf("Failed to convert objects to %s: %s", review.Request.DesiredAPIVersion, review.Response.Result.Message)
+	}
+	review.Request = nil
+
+	resp, err := json.Marshal(review)
+	if err != nil {
+		s.log.Errorf("Can't encode response: %v", err)
+		http.Error(w, fmt.Sprintf("could not encode response: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(resp); err != nil {
+		s.log.Errorf("Can't write response: %v", err)
+	}
+}
+
+// convertObjects converts every object in the request. The whole request fails if any object can't be converted.
+func convertObjects(req *ConversionRequest) *ConversionResponse {
+	resp := &ConversionResponse{UID: req.UID}
+
+	gv, err := schema.ParseGroupVersion(req.DesiredAPIVersion)
+	if err != nil {
+		resp.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+		return resp
+	}
+
+	for _, raw := range req.Objects {
+		converted, err := convertObject(raw.Raw, gv)
+		if err != nil {
+			resp.ConvertedObjects = nil
+			resp.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+			return resp
+		}
+		resp.ConvertedObjects = append(resp.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+
+	resp.Result = metav1.Status{Status: metav1.StatusSuccess}
+	return resp
+}
+
+func convertObject(raw []byte, gv schema.GroupVersion) ([]byte, error) {
+	obj, gvk, err := deserializer.Decode(raw, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Objects already in the desired version are returned untouched
+	if gvk.GroupVersion() == gv {
+		return raw, nil
+	}
+
+	converted, err := runtimeScheme.ConvertToVersion(obj, gv)
+	if err != nil {
+		return nil, fmt.Errorf("could not convert %s to %s: %v", gvk.Kind, gv, err)
+	}
+	return json.Marshal(converted)
+}
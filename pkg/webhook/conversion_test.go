@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	assignmentsv1beta1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1beta1"
+)
+
+func TestConvertObjects(t *testing.T) {
+	req := &ConversionRequest{
+		UID:               "1234",
+		DesiredAPIVersion: "assignments.kube-valet.io/v1beta1",
+		Objects: []runtime.RawExtension{
+			{Raw: []byte(`{"apiVersion":"assignments.kube-valet.io/v1alpha1","kind":"PodAssignmentRule","metadata":{"name":"par","namespace":"web","uid":"abcd"},"spec":{"targetLabels":{"app":"web"},"scheduling":{"mergeStrategy":"Reject"}}}`)},
+			{Raw: []byte(`{"apiVersion":"assignments.kube-valet.io/v1beta1","kind":"NodeAssignmentGroup","metadata":{"name":"nag"},"spec":{}}`)},
+		},
+	}
+
+	resp := convertObjects(req)
+	if resp.UID != req.UID || resp.Result.Status != metav1.StatusSuccess {
+		t.Fatalf("Unexpected response: %+v", resp)
+	}
+	if len(resp.ConvertedObjects) != 2 {
+		t.Fatalf("Expected 2 converted objects, got %d", len(resp.ConvertedObjects))
+	}
+
+	par := &assignmentsv1beta1.PodAssignmentRule{}
+	if err := json.Unmarshal(resp.ConvertedObjects[0].Raw, par); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if par.APIVersion != req.DesiredAPIVersion || par.Kind != "PodAssignmentRule" || par.Namespace != "web" || par.UID != "abcd" {
+		t.Errorf("Unexpected converted rule: %+v %+v", par.TypeMeta, par.ObjectMeta)
+	}
+	if par.Spec.PodSelector == nil || par.Spec.PodSelector.MatchLabels["app"] != "web" {
+		t.Errorf("Target labels were not converted: %+v", par.Spec.PodSelector)
+	}
+
+	// Objects already in the desired version are passed through
+	if string(resp.ConvertedObjects[1].Raw) != string(req.Objects[1].Raw) {
+		t.Errorf("Object in the desired version was changed: %s", resp.ConvertedObjects[1].Raw)
+	}
+
+	req.Objects = append(req.Objects, runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"Pod"}`)})
+	if resp := convertObjects(req); resp.Result.Status != metav1.StatusFailure || resp.ConvertedObjects != nil {
+		t.Errorf("Expected a failure for an unknown kind, got %+v", resp)
+	}
+}
@@ -12,11 +12,16 @@ import (
 
 	"k8s.io/api/admission/v1beta1"
 
+	assignmentsv1alpha1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1alpha1"
+	"github.com/domoinc/kube-valet/pkg/apis/assignments/v1alpha1/validation"
+	assignmentsv1beta1 "github.com/domoinc/kube-valet/pkg/apis/assignments/v1beta1"
 	"github.com/domoinc/kube-valet/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
 var (
@@ -25,8 +30,14 @@ var (
 	deserializer  = codecs.UniversalDeserializer()
 )
 
+func init() {
+	// Registers the kube-valet types along with their defaulting and conversion functions
+	utilruntime.Must(assignmentsv1alpha1.AddToScheme(runtimeScheme))
+	utilruntime.Must(assignmentsv1beta1.AddToScheme(runtimeScheme))
+}
+
 type PodAssigner interface {
-	GetPodSchedulingPatches(*corev1.Pod) []utils.JsonPatchOperation
+	GetPodSchedulingPatches(*corev1.Pod) ([]utils.JsonPatchOperation, error)
 }
 
 type Config struct {
@@ -90,6 +101,8 @@ func (s *Server) Run() {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", s.healthzHandler)
 	mux.HandleFunc("/mutate", s.mutateHandler)
+	mux.HandleFunc("/validate", s.validateHandler)
+	mux.HandleFunc("/convert", s.convertHandler)
 	s.server.Handler = mux
 
 	// Start the server, restart on errors
@@ -108,8 +121,34 @@ func (s *Server) healthzHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("Healthy"))
 }
 
+// admitFunc handles a decoded admission review and returns the response to send
+type admitFunc func(*v1beta1.AdmissionReview) *v1beta1.AdmissionResponse
+
 func (s *Server) mutateHandler(w http.ResponseWriter, r *http.Request) {
 	s.log.Debug("Processing mutation request")
+	s.serveAdmission(w, r, func(ar *v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
+		// Handle mutations for different resources
+		switch ar.Request.Kind.Kind {
+		case "Pod":
+			s.log.Debug("Processing pod mutation")
+			return s.mutatePod(ar, s.podAssigner)
+		case assignmentsv1alpha1.NodeAssignmentGroupResourceKind,
+			assignmentsv1alpha1.PodAssignmentRuleResourceKind,
+			assignmentsv1alpha1.ClusterPodAssignmentRuleResourceKind:
+			s.log.Debugf("Processing %s defaulting", ar.Request.Kind.Kind)
+			return s.defaultResource(ar)
+		}
+		return nil
+	})
+}
+
+func (s *Server) validateHandler(w http.ResponseWriter, r *http.Request) {
+	s.log.Debug("Processing validation request")
+	s.serveAdmission(w, r, s.validate)
+}
+
+// serveAdmission decodes the admission review in the request, passes it to admit and writes the response
+func (s *Server) serveAdmission(w http.ResponseWriter, r *http.Request, admit admitFunc) {
 	var body []byte
 	if r.Body != nil {
 		if data, err := ioutil.ReadAll(r.Body); err == nil {
@@ -117,7 +156,7 @@ func (s *Server) mutateHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	if len(body) == 0 {
-		s.log.Error("Empty body in mutation request")
+		s.log.Error("Empty body in admission request")
 		http.Error(w, "empty body", http.StatusBadRequest)
 		return
 	}
@@ -139,12 +178,8 @@ func (s *Server) mutateHandler(w http.ResponseWriter, r *http.Request) {
 				Message: err.Error(),
 			},
 		}
-	}
-
-	// Handle mutations for different resources
-	if admissionReview.Request.Kind.Kind == "Pod" {
-		s.log.Debug("Processing pod mutation")
-		admissionResponse = s.mutatePod(admissionReview, s.podAssigner)
+	} else if admissionReview.Request != nil {
+		admissionResponse = admit(admissionReview)
 	}
 
 	// Populate admissionReview from admissionResponse
@@ -167,6 +202,139 @@ func (s *Server) mutateHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// validate checks kube-valet resources with the shared validation package
+func (s *Server) validate(ar *v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
+	req := ar.Request
+
+	// There is nothing to validate on delete
+	if req.Operation == v1beta1.Delete {
+		return &v1beta1.AdmissionResponse{Allowed: true}
+	}
+
+	var errs field.ErrorList
+	var err error
+	switch req.Kind.Kind {
+	case assignmentsv1alpha1.NodeAssignmentGroupResourceKind:
+		nag := &assignmentsv1alpha1.NodeAssignmentGroup{}
+		if err = json.Unmarshal(req.Object.Raw, nag); err == nil {
+			errs = validation.ValidateNodeAssignmentGroup(nag)
+		}
+	case assignmentsv1alpha1.PodAssignmentRuleResourceKind:
+		par := &assignmentsv1alpha1.PodAssignmentRule{}
+		if err = json.Unmarshal(req.Object.Raw, par); err == nil {
+			errs = validation.ValidatePodAssignmentRule(par)
+		}
+	case assignmentsv1alpha1.ClusterPodAssignmentRuleResourceKind:
+		cpar := &assignmentsv1alpha1.ClusterPodAssignmentRule{}
+		if err = json.Unmarshal(req.Object.Raw, cpar); err == nil {
+			errs = validation.ValidateClusterPodAssignmentRule(cpar)
+		}
+	default:
+		s.log.Warningf("Skipping validation of unknown kind %s", req.Kind.Kind)
+	}
+
+	if err != nil {
+		s.log.Errorf("Could not unmarshal raw object: %v", err)
+		return &v1beta1.AdmissionResponse{
+			Result: &metav1.Status{
+				Message: err.Error(),
+			},
+		}
+	}
+
+	if len(errs) != 0 {
+		s.log.Infof("Rejecting invalid %s %s: %v", req.Kind.Kind, req.Name, errs.ToAggregate())
+		return &v1beta1.AdmissionResponse{
+			Result: &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Code:    http.StatusUnprocessableEntity,
+				Reason:  metav1.StatusReasonInvalid,
+				Message: errs.ToAggregate().Error(),
+			},
+		}
+	}
+
+	return &v1beta1.AdmissionResponse{Allowed: true}
+}
+
+// defaultResource patches the spec of a kube-valet resource so that it is stored with all defaults applied
+func (s *Server) defaultResource(ar *v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
+	obj, _, err := deserializer.Decode(ar.Request.Object.Raw, nil, nil)
+	if err != nil {
+		s.log.Errorf("Could not decode raw object: %v", err)
+		return &v1beta1.AdmissionResponse{
+			Result: &metav1.Status{
+				Message: err.Error(),
+			},
+		}
+	}
+
+	defaulted := obj.DeepCopyObject()
+	runtimeScheme.Default(defaulted)
+
+	patchOps, err := specPatchOps(obj, defaulted)
+	if err != nil {
+		return &v1beta1.AdmissionResponse{
+			Result: &metav1.Status{
+				Message: err.Error(),
+			},
+		}
+	}
+	if len(patchOps) == 0 {
+		return &v1beta1.AdmissionResponse{Allowed: true}
+	}
+
+	patchBytes, err := json.Marshal(patchOps)
+	if err != nil {
+		return &v1beta1.AdmissionResponse{
+			Result: &metav1.Status{
+				Message: err.Error(),
+			},
+		}
+	}
+
+	s.log.Debugf("Generated patch: %s\n", patchBytes)
+	return &v1beta1.AdmissionResponse{
+		Allowed: true,
+		Patch:   patchBytes,
+		PatchType: func() *v1beta1.PatchType {
+			pt := v1beta1.PatchTypeJSONPatch
+			return &pt
+		}(),
+	}
+}
+
+// specPatchOps returns an operation that sets the spec of orig to the spec of mutated when they differ
+func specPatchOps(orig runtime.Object, mutated runtime.Object) ([]utils.JsonPatchOperation, error) {
+	origSpec, err := specOf(orig)
+	if err != nil {
+		return nil, err
+	}
+	mutatedSpec, err := specOf(mutated)
+	if err != nil {
+		return nil, err
+	}
+
+	if string(origSpec) == string(mutatedSpec) {
+		return nil, nil
+	}
+	return []utils.JsonPatchOperation{{Op: "add", Path: "/spec", Value: mutatedSpec}}, nil
+}
+
+func specOf(obj runtime.Object) (json.RawMessage, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var fields struct {
+		Spec json.RawMessage `json:"spec"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields.Spec, nil
+}
+
 func (s *Server) mutatePod(ar *v1beta1.AdmissionReview, pa PodAssigner) *v1beta1.AdmissionResponse {
 	req := ar.Request
 	var pod corev1.Pod
@@ -182,7 +350,17 @@ func (s *Server) mutatePod(ar *v1beta1.AdmissionReview, pa PodAssigner) *v1beta1
 	// Inject object metadata from request
 	pod.Namespace = req.Namespace
 
-	patchBytes, err := json.Marshal(pa.GetPodSchedulingPatches(&pod))
+	patchOps, err := pa.GetPodSchedulingPatches(&pod)
+	if err != nil {
+		s.log.Infof("Denying pod in %s: %v", req.Namespace, err)
+		return &v1beta1.AdmissionResponse{
+			Result: &metav1.Status{
+				Message: err.Error(),
+			},
+		}
+	}
+
+	patchBytes, err := json.Marshal(patchOps)
 	if err != nil {
 		return &v1beta1.AdmissionResponse{
 			Result: &metav1.Status{